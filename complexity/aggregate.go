@@ -0,0 +1,32 @@
+package complexity
+
+// FileTotalOperationName is the operation name used for results aggregated
+// per file.
+const FileTotalOperationName = "<file total>"
+
+// AggregateByFile folds the results of each file into a single result holding
+// the summed complexities of its operations. Files keep the order in which
+// they first appear in results.
+func AggregateByFile(results []ComplexityAnalysis) []ComplexityAnalysis {
+	var (
+		totals  []ComplexityAnalysis
+		indexes = make(map[string]int)
+	)
+
+	for _, r := range results {
+		i, ok := indexes[r.Path]
+		if !ok {
+			i = len(totals)
+			indexes[r.Path] = i
+			totals = append(totals, ComplexityAnalysis{
+				Path:          r.Path,
+				OperationName: FileTotalOperationName,
+			})
+		}
+
+		totals[i].Complexity = safeAdd(totals[i].Complexity, r.Complexity)
+		totals[i].FlattenedComplexity = safeAdd(totals[i].FlattenedComplexity, r.FlattenedComplexity)
+	}
+
+	return totals
+}
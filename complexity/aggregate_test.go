@@ -0,0 +1,110 @@
+package complexity_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAggregateByFile(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", Complexity: 5, FlattenedComplexity: 3},
+		{Path: "b.graphql", OperationName: "GetOrder", Complexity: 7, FlattenedComplexity: 7},
+		{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10, FlattenedComplexity: 8},
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: complexity.FileTotalOperationName, Complexity: 15, FlattenedComplexity: 11},
+		{Path: "b.graphql", OperationName: complexity.FileTotalOperationName, Complexity: 7, FlattenedComplexity: 7},
+	}
+
+	if diff := cmp.Diff(expected, complexity.AggregateByFile(results)); diff != "" {
+		t.Errorf("AggregateByFile() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckFileComplexity(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "b.graphql", OperationName: "GetOrder", Complexity: 7},
+		{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10},
+	}
+
+	expected := []complexity.Violation{
+		{
+			Path:          "a.graphql",
+			OperationName: complexity.FileTotalOperationName,
+			Rule:          complexity.RuleMaxFileComplexity,
+			Value:         15,
+			Limit:         10,
+		},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckFileComplexity(results, 10)); diff != "" {
+		t.Errorf("CheckFileComplexity() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckOperationsPerDocument(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"many.graphql": {Data: []byte(`query GetUser { user(id: 1) { id } }
+			query GetName { user(id: 1) { name } }
+			query GetBoth { user(id: 1) { id name } }`)},
+		"one.graphql": {Data: []byte(`query GetOther { user(id: 2) { id } }`)},
+	}
+
+	results, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql")
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.Violation{
+		{
+			Path:          "many.graphql",
+			OperationName: complexity.FileTotalOperationName,
+			Rule:          complexity.RuleMaxOperations,
+			Value:         3,
+			Limit:         2,
+		},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckOperationsPerDocument(results, 2)); diff != "" {
+		t.Errorf("CheckOperationsPerDocument() mismatch (-want +got):\n%s", diff)
+	}
+	if violations := complexity.CheckOperationsPerDocument(results, 3); len(violations) != 0 {
+		t.Errorf("CheckOperationsPerDocument() = %v, want no violations at the limit", violations)
+	}
+}
+
+func TestGroupByFile(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "b.graphql", OperationName: "GetOrder", Complexity: 7},
+		{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10},
+	}
+
+	expected := []complexity.FileGroup{
+		{
+			Path: "a.graphql",
+			Operations: []complexity.ComplexityAnalysis{
+				{Path: "a.graphql", OperationName: "GetUser", Complexity: 5},
+				{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10},
+			},
+			Total: 15,
+		},
+		{
+			Path: "b.graphql",
+			Operations: []complexity.ComplexityAnalysis{
+				{Path: "b.graphql", OperationName: "GetOrder", Complexity: 7},
+			},
+			Total: 7,
+		},
+	}
+
+	if diff := cmp.Diff(expected, complexity.GroupByFile(results)); diff != "" {
+		t.Errorf("GroupByFile() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,82 @@
+package complexity
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// AliasCount holds the number of distinct aliases used for a single field
+// within one selection set.
+type AliasCount struct {
+	Field string `json:"field" yaml:"field" toml:"field"`
+	Count int    `json:"count" yaml:"count" toml:"count"`
+}
+
+// CountAliases counts how many distinct aliases target the same field within
+// each selection set of the operation. Fragments are expanded in place so that
+// aliases spread into a selection set count towards it. For each field the
+// highest count found in any selection set is returned, sorted by field name.
+func CountAliases(doc *ast.QueryDocument, op *ast.OperationDefinition) []AliasCount {
+	counts := make(map[string]int)
+	countAliases(op.SelectionSet, doc, counts)
+
+	var result []AliasCount
+	for field, count := range counts {
+		result = append(result, AliasCount{Field: field, Count: count})
+	}
+
+	slices.SortFunc(result, func(a, b AliasCount) int {
+		return cmp.Compare(a.Field, b.Field)
+	})
+
+	return result
+}
+
+// countAliases records the alias counts of a selection set in counts and
+// recurses into the selected fields.
+func countAliases(selectionSet ast.SelectionSet, doc *ast.QueryDocument, counts map[string]int) {
+	aliases := make(map[string]map[string]struct{})
+	collectAliases(selectionSet, doc, aliases, counts)
+
+	for field, set := range aliases {
+		counts[field] = max(counts[field], len(set))
+	}
+}
+
+// collectAliases gathers the aliases used per field at the level of the given
+// selection set, including those contributed by fragments.
+func collectAliases(selectionSet ast.SelectionSet, doc *ast.QueryDocument, aliases map[string]map[string]struct{}, counts map[string]int) {
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if sel.Alias != "" && sel.Alias != sel.Name {
+				field := fieldName(sel)
+				if aliases[field] == nil {
+					aliases[field] = make(map[string]struct{})
+				}
+				aliases[field][sel.Alias] = struct{}{}
+			}
+
+			countAliases(sel.SelectionSet, doc, counts)
+
+		case *ast.InlineFragment:
+			collectAliases(sel.SelectionSet, doc, aliases, counts)
+
+		case *ast.FragmentSpread:
+			if fragDef := findFragmentDefinition(doc, sel.Name); fragDef != nil {
+				collectAliases(fragDef.SelectionSet, doc, aliases, counts)
+			}
+		}
+	}
+}
+
+// fieldName returns the name of the field qualified by its parent type when
+// the document has been validated against a schema.
+func fieldName(field *ast.Field) string {
+	if field.ObjectDefinition == nil {
+		return field.Name
+	}
+	return field.ObjectDefinition.Name + "." + field.Name
+}
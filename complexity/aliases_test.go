@@ -0,0 +1,77 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+)
+
+const aliasedQuery = `query GetUsers {
+	a: user(id: 1) { id }
+	b: user(id: 2) { id }
+	...MoreUsers
+	user(id: 4) {
+		first: name
+		second: name
+	}
+}
+
+fragment MoreUsers on Query {
+	c: user(id: 3) { id }
+	a: user(id: 1) { name }
+}`
+
+func TestCountAliases(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, aliasedQuery)
+	if gqlErr != nil {
+		t.Fatalf("failed to load query: %v", gqlErr)
+	}
+
+	got := complexity.CountAliases(queryDoc, queryDoc.Operations[0])
+
+	expected := []complexity.AliasCount{
+		{Field: "Query.user", Count: 3},
+		{Field: "User.name", Count: 2},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("CountAliases() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckAliases(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{
+			Path:          "users.graphql",
+			OperationName: "GetUsers",
+			Aliases: []complexity.AliasCount{
+				{Field: "Query.user", Count: 3},
+				{Field: "User.name", Count: 2},
+			},
+		},
+	}
+
+	got := complexity.CheckAliases(results, 2)
+
+	expected := []complexity.Violation{
+		{
+			Path:          "users.graphql",
+			OperationName: "GetUsers",
+			Rule:          complexity.RuleMaxAliasesPerField,
+			Subject:       "Query.user",
+			Value:         3,
+			Limit:         2,
+		},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("CheckAliases() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,44 @@
+package complexity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// MaxComplexityHint is the comment annotating an operation with its own
+// complexity limit, such as "# gql:max-complexity 200".
+const MaxComplexityHint = "gql:max-complexity"
+
+// maxComplexityHint returns the limit given by the MaxComplexityHint in the
+// comment above the operation, or 0 when it has none.
+func maxComplexityHint(op *ast.OperationDefinition) (int, error) {
+	if op.Comment == nil {
+		return 0, nil
+	}
+
+	for _, c := range op.Comment.List {
+		value, ok := strings.CutPrefix(strings.TrimSpace(c.Text()), MaxComplexityHint)
+		if !ok {
+			continue
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit <= 0 {
+			return 0, fmt.Errorf("%w: %s of %s must be a positive integer, got %q", ErrInvalidInput, MaxComplexityHint, operationLabel(op), strings.TrimSpace(value))
+		}
+		return limit, nil
+	}
+
+	return 0, nil
+}
+
+// operationLabel names the operation in messages.
+func operationLabel(op *ast.OperationDefinition) string {
+	if op.Name == "" {
+		return "anonymous operation"
+	}
+	return "operation " + op.Name
+}
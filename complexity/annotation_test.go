@@ -0,0 +1,112 @@
+package complexity_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestMaxComplexityHint(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+		wantErr  bool
+	}{
+		{
+			name:  "absent",
+			query: `query GetUser { user(id: 1) { id } }`,
+		},
+		{
+			name: "present",
+			query: `# Loads the user page.
+			# gql:max-complexity 200
+			query GetUser { user(id: 1) { id } }`,
+			expected: 200,
+		},
+		{
+			name: "malformed",
+			query: `# gql:max-complexity lots
+			query GetUser { user(id: 1) { id } }`,
+			wantErr: true,
+		},
+		{
+			name: "not positive",
+			query: `# gql:max-complexity 0
+			query GetUser { user(id: 1) { id } }`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, err := parser.ParseQuery(&ast.Source{Name: "query.graphql", Input: tt.query})
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc)
+			if tt.wantErr {
+				if !errors.Is(err, complexity.ErrInvalidInput) {
+					t.Fatalf("AnalyseDocument() error = %v, want %v", err, complexity.ErrInvalidInput)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+
+			if result[0].MaxComplexity != tt.expected {
+				t.Errorf("AnalyseDocument() MaxComplexity = %d, want %d", result[0].MaxComplexity, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckComplexity(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "Cheap", Complexity: 5},
+		{Path: "a.graphql", OperationName: "Expensive", Complexity: 150},
+		{Path: "b.graphql", OperationName: "Allowed", Complexity: 150, MaxComplexity: 200},
+		{Path: "b.graphql", OperationName: "Stricter", Complexity: 50, MaxComplexity: 20},
+	}
+
+	expected := []complexity.Violation{
+		{Path: "a.graphql", OperationName: "Expensive", Rule: complexity.RuleMaxComplexity, Value: 150, Limit: 100},
+		{Path: "b.graphql", OperationName: "Stricter", Rule: complexity.RuleMaxComplexity, Value: 50, Limit: 20},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckComplexity(results, 100)); diff != "" {
+		t.Errorf("CheckComplexity() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckComplexityLimits(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", OperationType: ast.Query, Complexity: 60},
+		{Path: "a.graphql", OperationName: "UpdateUser", OperationType: ast.Mutation, Complexity: 60},
+		{Path: "a.graphql", OperationName: "OnUser", OperationType: ast.Subscription, Complexity: 60},
+		{Path: "b.graphql", OperationName: "Dashboard", OperationType: ast.Query, Complexity: 60, MaxComplexity: 100},
+	}
+
+	limits := complexity.ComplexityLimits{Default: 40, Query: 50, Mutation: 80}
+
+	expected := []complexity.Violation{
+		{Path: "a.graphql", OperationName: "GetUser", Rule: complexity.RuleMaxQueryComplexity, Value: 60, Limit: 50},
+		{Path: "a.graphql", OperationName: "OnUser", Rule: complexity.RuleMaxComplexity, Value: 60, Limit: 40},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckComplexityLimits(results, limits)); diff != "" {
+		t.Errorf("CheckComplexityLimits() mismatch (-want +got):\n%s", diff)
+	}
+}
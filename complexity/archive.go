@@ -0,0 +1,244 @@
+package complexity
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// archiveExtensions are the file extensions of the archives documents can be
+// read from.
+var archiveExtensions = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+// maxArchiveFileSize is the size in bytes of the largest file read from an
+// archive. Archives are read into memory, and a small compressed archive can
+// hold files far larger than any document.
+const maxArchiveFileSize = 16 << 20
+
+// isArchive reports whether the named file is an archive by its extension.
+func isArchive(name string) bool {
+	return slices.ContainsFunc(archiveExtensions, func(ext string) bool {
+		return strings.HasSuffix(name, ext)
+	})
+}
+
+// OpenArchive reads the zip or, optionally gzip compressed, tar archive name
+// from fsys and returns its content as a file system. Entry names are the
+// paths of the files within it.
+func OpenArchive(fsys fs.FS, name string) (fs.FS, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading archive %s: %w: %w", name, ErrInvalidInput, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading archive %s: %w", name, err)
+	}
+
+	var archive fs.FS
+	if strings.HasSuffix(name, ".zip") {
+		archive, err = readZip(data)
+	} else {
+		archive, err = readTar(bytes.NewReader(data), !strings.HasSuffix(name, ".tar"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading archive %s: %w: %w", name, ErrInvalidInput, err)
+	}
+	return archive, nil
+}
+
+// readZip opens a zip archive held in memory. Files larger than
+// maxArchiveFileSize are an error, by the size their headers declare, which
+// zip.Reader holds them to when they are read.
+func readZip(data []byte) (fs.FS, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range zr.File {
+		if f.UncompressedSize64 > maxArchiveFileSize {
+			return nil, fmt.Errorf("%s is larger than %d MiB", f.Name, maxArchiveFileSize>>20)
+		}
+	}
+	return zr, nil
+}
+
+// readTar reads the regular files of a tar archive into memory. Files larger
+// than maxArchiveFileSize are an error.
+func readTar(r io.Reader, gzipped bool) (fs.FS, error) {
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	files := make(memFS)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if hdr.Typeflag != tar.TypeReg || !fs.ValidPath(name) {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxArchiveFileSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxArchiveFileSize {
+			return nil, fmt.Errorf("%s is larger than %d MiB", name, maxArchiveFileSize>>20)
+		}
+		files[name] = data
+	}
+}
+
+// archiveDocuments returns the paths of the documents within the archive, the
+// files ending in .graphql or .gql.
+func archiveDocuments(archive fs.FS) ([]string, error) {
+	var docs []string
+	err := fs.WalkDir(archive, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (path.Ext(name) == ".graphql" || path.Ext(name) == ".gql") {
+			docs = append(docs, name)
+		}
+		return nil
+	})
+	return docs, err
+}
+
+// archivesFS serves the files of archives below the archive's name, such as
+// "queries.zip/user.graphql", and all other files from the underlying FS.
+type archivesFS struct {
+	fs.FS
+	archives map[string]fs.FS
+}
+
+func (a archivesFS) Open(name string) (fs.File, error) {
+	for archiveName, archive := range a.archives {
+		if rest, ok := strings.CutPrefix(name, archiveName+"/"); ok {
+			return archive.Open(rest)
+		}
+	}
+	return a.FS.Open(name)
+}
+
+// memFS is a read-only file system of files held in memory, keyed by their
+// path. Directories are implied by the paths of the files.
+type memFS map[string][]byte
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if data, ok := m[name]; ok {
+		return &memFile{info: memInfo{name: path.Base(name), size: int64(len(data))}, r: bytes.NewReader(data)}, nil
+	}
+
+	// Directories list the files and directories directly below them.
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+
+	seen := make(map[string]bool)
+	var entries []fs.DirEntry
+	for _, file := range slices.Sorted(maps.Keys(m)) {
+		rest, ok := strings.CutPrefix(file, prefix)
+		if !ok {
+			continue
+		}
+
+		child, _, isDir := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+
+		info := memInfo{name: child, dir: isDir}
+		if !isDir {
+			info.size = int64(len(m[file]))
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+
+	if len(entries) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{info: memInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// memFile is an open file or directory of a memFS.
+type memFile struct {
+	info    memInfo
+	r       *bytes.Reader
+	entries []fs.DirEntry
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+func (f *memFile) Read(b []byte) (int, error) {
+	if f.info.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+	return f.r.Read(b)
+}
+
+func (f *memFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !f.info.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+
+	entries := f.entries
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	f.entries = f.entries[len(entries):]
+	if n > 0 && len(entries) == 0 {
+		return nil, io.EOF
+	}
+	return entries, nil
+}
+
+// memInfo describes a file or directory of a memFS.
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
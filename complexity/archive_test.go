@@ -0,0 +1,154 @@
+package complexity_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+// archiveQueries are the files of the test archives.
+var archiveQueries = []struct {
+	name, query string
+}{
+	{"user.graphql", `query GetUser { user(id: 1) { id } }`},
+	{"nested/name.gql", `query GetName { user(id: 1) { id name } }`},
+	{"README.md", `# Queries`},
+}
+
+func zipArchive(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range archiveQueries {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", f.name, err)
+		}
+		if _, err := w.Write([]byte(f.query)); err != nil {
+			t.Fatalf("failed to write %s: %v", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, f := range archiveQueries {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.query)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("failed to write header of %s: %v", f.name, err)
+		}
+		if _, err := tw.Write([]byte(f.query)); err != nil {
+			t.Fatalf("failed to write %s: %v", f.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to close gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRunAnalysisFSArchive(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"queries.zip":     {Data: zipArchive(t)},
+		"queries.tar.gz":  {Data: tarGzArchive(t)},
+	}
+
+	for _, archive := range []string{"queries.zip", "queries.tar.gz"} {
+		t.Run(archive, func(t *testing.T) {
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", archive)
+			if err != nil {
+				t.Fatalf("RunAnalysisFS() error = %v", err)
+			}
+
+			expected := []string{
+				archive + "/nested/name.gql: GetName",
+				archive + "/user.graphql: GetUser",
+			}
+
+			var got []string
+			for _, r := range result {
+				got = append(got, r.Path+": "+r.OperationName)
+			}
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("RunAnalysisFS() operations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	fsys := fstest.MapFS{
+		"queries.tar.gz": {Data: tarGzArchive(t)},
+	}
+
+	archive, err := complexity.OpenArchive(fsys, "queries.tar.gz")
+	if err != nil {
+		t.Fatalf("OpenArchive() error = %v", err)
+	}
+
+	if err := fstest.TestFS(archive, "user.graphql", "nested/name.gql", "README.md"); err != nil {
+		t.Errorf("OpenArchive() file system: %v", err)
+	}
+}
+
+func TestOpenArchiveFileTooLarge(t *testing.T) {
+	size := complexity.MaxArchiveFileSize + 1
+
+	var tarBuf bytes.Buffer
+	gw := gzip.NewWriter(&tarBuf)
+	tw := tar.NewWriter(gw)
+	if err := tw.WriteHeader(&tar.Header{Name: "large.graphql", Mode: 0o644, Size: int64(size), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("failed to write header: %v", err)
+	}
+	if _, err := tw.Write(make([]byte, size)); err != nil {
+		t.Fatalf("failed to write large.graphql: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to close gzip: %v", err)
+	}
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	f, err := zw.Create("large.graphql")
+	if err != nil {
+		t.Fatalf("failed to create large.graphql: %v", err)
+	}
+	if _, err := f.Write(make([]byte, size)); err != nil {
+		t.Fatalf("failed to write large.graphql: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+
+	fsys := fstest.MapFS{
+		"queries.tar.gz": {Data: tarBuf.Bytes()},
+		"queries.zip":    {Data: zipBuf.Bytes()},
+	}
+	for _, name := range []string{"queries.tar.gz", "queries.zip"} {
+		if _, err := complexity.OpenArchive(fsys, name); !errors.Is(err, complexity.ErrInvalidInput) {
+			t.Errorf("OpenArchive(%q) error = %v, want %v", name, err, complexity.ErrInvalidInput)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package complexity
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ArgumentCost adds to the weight of a field when one of its arguments has a
+// given value, such as an export in a format that is costlier to produce.
+type ArgumentCost struct {
+	// Field is the field the cost applies to, as "Type.field".
+	Field string
+	// Argument is the name of the argument, and Value the value it must have,
+	// as written in a document without the quotes of strings. Variables are
+	// resolved and arguments left out take the default of the schema.
+	Argument string
+	Value    string
+	// Cost is added to the weight of the field.
+	Cost int
+}
+
+// argumentCostRule matches the rules read by ParseArgumentCost.
+var argumentCostRule = regexp.MustCompile(`^\s*([^\s:]+)\s*:\s*when\s+(\w+)\s*=\s*(.+?)\s+add\s+(\d+)\s*$`)
+
+// ParseArgumentCost parses an argument cost written as
+// "Type.field: when argument=value add N", such as
+// "Query.export: when format=CSV add 50". Values containing spaces are
+// quoted, as in `Query.search: when locale="en US" add 5`.
+func ParseArgumentCost(rule string) (ArgumentCost, error) {
+	m := argumentCostRule.FindStringSubmatch(rule)
+	if m == nil || !strings.Contains(m[1], ".") {
+		return ArgumentCost{}, fmt.Errorf("%w: argument cost must be Type.field: when argument=value add N, got %q", ErrInvalidInput, rule)
+	}
+
+	value := m[3]
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return ArgumentCost{}, fmt.Errorf("%w: argument cost value of %q: %w", ErrInvalidInput, rule, err)
+		}
+		value = unquoted
+	}
+
+	cost, err := strconv.Atoi(m[4])
+	if err != nil {
+		return ArgumentCost{}, fmt.Errorf("%w: argument cost of %q: %w", ErrInvalidInput, rule, err)
+	}
+
+	return ArgumentCost{Field: m[1], Argument: m[2], Value: value, Cost: cost}, nil
+}
+
+// String formats the argument cost as read by ParseArgumentCost.
+func (c ArgumentCost) String() string {
+	value := c.Value
+	if strings.ContainsAny(value, " \t\"") {
+		value = strconv.Quote(value)
+	}
+	return fmt.Sprintf("%s: when %s=%s add %d", c.Field, c.Argument, value, c.Cost)
+}
+
+// argumentCost returns the sum of the costs applying to the field, keyed by
+// "Type.field", given its arguments.
+func argumentCost(costs []ArgumentCost, field string, args map[string]any) int {
+	var total int
+	for _, c := range costs {
+		if c.Field != field {
+			continue
+		}
+		if value, ok := args[c.Argument]; ok && argumentString(value) == c.Value {
+			total = safeAdd(total, c.Cost)
+		}
+	}
+	return total
+}
+
+// argumentString formats an argument value as it is written in a document,
+// without the quotes of strings.
+func argumentString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
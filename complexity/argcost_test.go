@@ -0,0 +1,142 @@
+package complexity_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseArgumentCost(t *testing.T) {
+	tests := []struct {
+		rule     string
+		expected complexity.ArgumentCost
+	}{
+		{
+			rule:     "Query.export: when format=CSV add 50",
+			expected: complexity.ArgumentCost{Field: "Query.export", Argument: "format", Value: "CSV", Cost: 50},
+		},
+		{
+			rule:     "  User.posts:when first = 100 add 3 ",
+			expected: complexity.ArgumentCost{Field: "User.posts", Argument: "first", Value: "100", Cost: 3},
+		},
+		{
+			rule:     `Query.search: when locale="en US" add 5`,
+			expected: complexity.ArgumentCost{Field: "Query.search", Argument: "locale", Value: "en US", Cost: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := complexity.ParseArgumentCost(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseArgumentCost() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("ParseArgumentCost() mismatch (-want +got):\n%s", diff)
+			}
+
+			// Formatted costs parse back to the same cost.
+			again, err := complexity.ParseArgumentCost(got.String())
+			if err != nil {
+				t.Fatalf("ParseArgumentCost(%q) error = %v", got.String(), err)
+			}
+			if diff := cmp.Diff(got, again); diff != "" {
+				t.Errorf("ParseArgumentCost(%q) mismatch (-want +got):\n%s", got.String(), diff)
+			}
+		})
+	}
+}
+
+func TestParseArgumentCostInvalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"export: when format=CSV add 50",
+		"Query.export when format=CSV add 50",
+		"Query.export: format=CSV add 50",
+		"Query.export: when format add 50",
+		"Query.export: when format=CSV add -1",
+		"Query.export: when format=CSV add many",
+		`Query.export: when format="CSV add 50`,
+	} {
+		if _, err := complexity.ParseArgumentCost(rule); !errors.Is(err, complexity.ErrInvalidInput) {
+			t.Errorf("ParseArgumentCost(%q) error = %v, want %v", rule, err, complexity.ErrInvalidInput)
+		}
+	}
+}
+
+func TestRunAnalysisArgumentCosts(t *testing.T) {
+	const argumentSchema = `type Query {
+		export(format: Format = JSON, limit: Int): Export
+		search(query: String!): [Result!]!
+	}
+	enum Format { JSON CSV }
+	type Export { url: String! }
+	type Result { id: ID! }`
+
+	costs := complexity.WithArgumentCosts(
+		complexity.ArgumentCost{Field: "Query.export", Argument: "format", Value: "CSV", Cost: 50},
+		complexity.ArgumentCost{Field: "Query.export", Argument: "limit", Value: "1000", Cost: 20},
+	)
+
+	tests := []struct {
+		name      string
+		query     string
+		opts      []complexity.Option
+		variables map[string]any
+		expected  int
+	}{
+		{name: "without costs", query: `query { export(format: CSV) { url } }`, expected: 2},
+		{name: "matching literal", query: `query { export(format: CSV) { url } }`, opts: []complexity.Option{costs}, expected: 52},
+		{name: "other value", query: `query { export(format: JSON) { url } }`, opts: []complexity.Option{costs}, expected: 2},
+		{name: "schema default", query: `query { export { url } }`, opts: []complexity.Option{costs}, expected: 2},
+		{name: "several matching", query: `query { export(limit: 1000, format: CSV) { url } }`, opts: []complexity.Option{costs}, expected: 72},
+		{
+			name:      "variable",
+			query:     `query ($format: Format!) { export(format: $format) { url } }`,
+			opts:      []complexity.Option{costs},
+			variables: map[string]any{"format": "CSV"},
+			expected:  52,
+		},
+		{name: "variable default", query: `query ($limit: Int = 1000) { export(limit: $limit) { url } }`, opts: []complexity.Option{costs}, expected: 22},
+		{name: "depth decay", query: `query { export(format: CSV) { url } }`, opts: []complexity.Option{costs, complexity.WithDepthDecay(2)}, expected: 52},
+		{name: "ignored field", query: `query { export(format: CSV) { url } }`, opts: []complexity.Option{costs, complexity.WithIgnoreFields("Query.export")}, expected: 1},
+		{
+			// Costs depending on more than a value, such as the length of a
+			// search query, are computed by a ComplexityFunc.
+			name:  "complexity func",
+			query: `query { search(query: "a long and expensive search") { id } }`,
+			opts: []complexity.Option{complexity.WithComplexityFunc(func(ctx context.Context, typeName, fieldName string, childComplexity int, args map[string]any) (int, bool) {
+				if query, ok := args["query"].(string); ok && typeName == "Query" && fieldName == "search" {
+					return len(query)/10 + childComplexity, true
+				}
+				return 0, false
+			})},
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"schema.graphqls": {Data: []byte(argumentSchema)},
+				"query.graphql":   {Data: []byte(tt.query)},
+			}
+
+			opts := append(tt.opts, complexity.WithVariables(tt.variables))
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", opts...)
+			if err != nil {
+				t.Fatalf("failed to run analysis: %v", err)
+			}
+			if len(result) != 1 {
+				t.Fatalf("RunAnalysisFS() returned %d results, want 1", len(result))
+			}
+			if result[0].Complexity != tt.expected {
+				t.Errorf("RunAnalysisFS() Complexity = %d, want %d", result[0].Complexity, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package complexity
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+// CountArguments counts the arguments given to the fields and directives of
+// the operation and of the fragments it uses, each fragment once. Every entry
+// of an object or list literal counts as an argument too, so that large input
+// literals count towards the total however deeply they are nested.
+func CountArguments(doc *ast.QueryDocument, op *ast.OperationDefinition) int {
+	count := directiveArguments(op.Directives) + selectionSetArguments(op.SelectionSet)
+	for _, frag := range usedFragments(doc, op.SelectionSet) {
+		count += directiveArguments(frag.Directives) + selectionSetArguments(frag.SelectionSet)
+	}
+	return count
+}
+
+// selectionSetArguments counts the arguments within a selection set, leaving
+// out the fragments it spreads.
+func selectionSetArguments(selectionSet ast.SelectionSet) int {
+	var count int
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			count += directiveArguments(sel.Directives) + selectionSetArguments(sel.SelectionSet)
+			for _, arg := range sel.Arguments {
+				count += 1 + valueEntries(arg.Value)
+			}
+		case *ast.InlineFragment:
+			count += directiveArguments(sel.Directives) + selectionSetArguments(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			count += directiveArguments(sel.Directives)
+		}
+	}
+	return count
+}
+
+// directiveArguments counts the arguments of the directives.
+func directiveArguments(directives ast.DirectiveList) int {
+	var count int
+	for _, d := range directives {
+		for _, arg := range d.Arguments {
+			count += 1 + valueEntries(arg.Value)
+		}
+	}
+	return count
+}
+
+// valueEntries counts the entries of an object or list literal, including the
+// entries of the literals nested in it.
+func valueEntries(value *ast.Value) int {
+	if value == nil || value.Kind != ast.ObjectValue && value.Kind != ast.ListValue {
+		return 0
+	}
+
+	var count int
+	for _, child := range value.Children {
+		count += 1 + valueEntries(child.Value)
+	}
+	return count
+}
@@ -0,0 +1,120 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const argumentsSchema = `type Query {
+	user(id: ID!): User
+}
+
+type Mutation {
+	createUsers(input: [UserInput!]!, dryRun: Boolean): [User!]!
+}
+
+type User {
+	id: ID!
+	name(upper: Boolean): String!
+}
+
+input UserInput {
+	name: String!
+	tags: [String!]
+	address: AddressInput
+}
+
+input AddressInput {
+	street: String!
+	geo: GeoInput
+}
+
+input GeoInput {
+	lat: Float!
+	lng: Float!
+}
+`
+
+func TestCountArguments(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: argumentsSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{
+			name:     "no arguments",
+			query:    `query { __typename }`,
+			expected: 0,
+		},
+		{
+			name:     "scalar arguments",
+			query:    `query GetUser($upper: Boolean) { user(id: 1) { id name(upper: $upper) } }`,
+			expected: 2,
+		},
+		{
+			name: "nested input literals",
+			query: `mutation CreateUsers {
+				createUsers(dryRun: true, input: [
+					{ name: "a", tags: ["x", "y"], address: { street: "s", geo: { lat: 1, lng: 2 } } },
+					{ name: "b" }
+				]) { id }
+			}`,
+			// 2 arguments, 2 list items, 3 + 1 object fields, 2 tags, 2 address
+			// fields and 2 geo fields.
+			expected: 14,
+		},
+		{
+			name:     "directive arguments",
+			query:    `query GetUser($skip: Boolean!) { user(id: 1) @skip(if: $skip) { id } }`,
+			expected: 2,
+		},
+		{
+			name: "fragments once each",
+			query: `query GetUser {
+				user(id: 1) { ...UserFields ...UserFields }
+			}
+
+			fragment UserFields on User {
+				name(upper: true)
+			}`,
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, tt.query)
+			if gqlErr != nil {
+				t.Fatalf("failed to load query: %v", gqlErr)
+			}
+
+			if got := complexity.CountArguments(queryDoc, queryDoc.Operations[0]); got != tt.expected {
+				t.Errorf("CountArguments() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckArguments(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "users.graphql", OperationName: "CreateUsers", Arguments: 14},
+		{Path: "users.graphql", OperationName: "GetUser", Arguments: 1},
+	}
+
+	expected := []complexity.Violation{
+		{Path: "users.graphql", OperationName: "CreateUsers", Rule: complexity.RuleMaxArguments, Value: 14, Limit: 10},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckArguments(results, 10)); diff != "" {
+		t.Errorf("CheckArguments() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,63 @@
+package complexity
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// BatchTotalOperationName is the operation name of the result holding the
+// total complexity of a batch, see Config.Batch.
+const BatchTotalOperationName = "<batch total>"
+
+// batchRequest is a single request of a batched GraphQL request.
+type batchRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// document is a source to analyse along with the request it was sent in, if
+// any.
+type document struct {
+	*ast.Source
+
+	// batch is the path of the batch the document was read from.
+	batch string
+	// operationName selects the operation the request executes.
+	operationName string
+	// variables holds the values sent with the request.
+	variables map[string]any
+}
+
+// batchDocuments reads the requests of a batched request, a JSON array of
+// objects with a query and optionally an operationName and variables. Each
+// document is named by the batch path and the index of its request.
+func batchDocuments(name string, data []byte) ([]document, error) {
+	var requests []batchRequest
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("decoding batched request: %w", err)
+	}
+
+	docs := make([]document, 0, len(requests))
+	for i, req := range requests {
+		docs = append(docs, document{
+			Source:        &ast.Source{Name: name + ManifestSeparator + strconv.Itoa(i), Input: req.Query},
+			batch:         name,
+			operationName: req.OperationName,
+			variables:     req.Variables,
+		})
+	}
+	return docs, nil
+}
+
+// addToBatch adds the result of a request in a batch to the batch's total.
+func addToBatch(total *ComplexityAnalysis, r ComplexityAnalysis) {
+	total.Complexity += r.Complexity
+	total.FlattenedComplexity += r.FlattenedComplexity
+	total.RootFields += r.RootFields
+	total.ResponseNodes += r.ResponseNodes
+	total.Depth = max(total.Depth, r.Depth)
+}
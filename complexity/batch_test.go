@@ -0,0 +1,38 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestRunAnalysisBatch(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"schema.graphqls": listSchema,
+		"requests.json": `[
+			{"query": "query Users($count: Int) { users(first: $count) { id name } }", "variables": {"count": 10}},
+			{
+				"query": "query GetUser($skip: Boolean!) { users(first: 1) { id name @skip(if: $skip) } } query Other { users { id } }",
+				"operationName": "GetUser",
+				"variables": {"skip": true}
+			}
+		]`,
+	})
+
+	result, err := complexity.RunAnalysis(t.Context(), "*.graphqls", "requests.json", complexity.WithBatch(), complexity.WithListMultiplierArgs("first"))
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "requests.json#0", OperationName: "Users", OperationType: ast.Query, Complexity: 21, FlattenedComplexity: 21, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 3, ResponseNodes: 21},
+		{Path: "requests.json#1", OperationName: "GetUser", OperationType: ast.Query, Complexity: 2, FlattenedComplexity: 2, RootFields: 1, Depth: 2, Arguments: 2, UniqueFields: 3, ResponseNodes: 2, Directives: []complexity.DirectiveCount{{Field: "User.name", Count: 1}}},
+		{Path: "requests.json", OperationName: complexity.BatchTotalOperationName, Complexity: 23, FlattenedComplexity: 23, RootFields: 2, Depth: 2, ResponseNodes: 23},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysis() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,189 @@
+package complexity
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// TypeComplexity is the part of an operation's complexity spent on the fields
+// of a type.
+type TypeComplexity struct {
+	TypeName   string `json:"type" yaml:"type" toml:"type"`
+	Complexity int    `json:"complexity" yaml:"complexity" toml:"complexity"`
+}
+
+// TypeBreakdown attributes the complexity of the operation to the types
+// declaring the fields it selects, the object or interface type each field is
+// selected on. The weight of a field is attributed to its type, and the
+// complexity of its selection set, multiplied by its list size, to the types
+// of the fields in it. Abstract selections attribute the type conditions they
+// are charged for. The parts are sorted by descending complexity and add up to
+// the complexity of the operation, except with Config.DepthDecay, which the
+// breakdown does not apply.
+func TypeBreakdown(schema *ast.Schema, op *ast.OperationDefinition, opts ...Option) []TypeComplexity {
+	cfg := newConfig(opts)
+	w := breakdownWalker{
+		schema: schema,
+		vars:   NewVariables(op, cfg.Variables),
+		cfg:    cfg,
+	}
+
+	costs := w.selectionSetCosts(schema.Types[rootTypeName(schema, op)], op.SelectionSet)
+
+	var breakdown []TypeComplexity
+	for _, name := range slices.Sorted(maps.Keys(costs)) {
+		if costs[name] > 0 {
+			breakdown = append(breakdown, TypeComplexity{TypeName: name, Complexity: costs[name]})
+		}
+	}
+	slices.SortStableFunc(breakdown, func(a, b TypeComplexity) int {
+		return cmp.Compare(b.Complexity, a.Complexity)
+	})
+	return breakdown
+}
+
+// typeCosts holds complexity keyed by the type it is attributed to.
+type typeCosts map[string]int
+
+func (c typeCosts) add(other typeCosts) {
+	for name, cost := range other {
+		c[name] = safeAdd(c[name], cost)
+	}
+}
+
+func (c typeCosts) total() int {
+	var total int
+	for _, cost := range c {
+		total = safeAdd(total, cost)
+	}
+	return total
+}
+
+// breakdownWalker computes the complexity of selections as calculate does,
+// keeping track of the types it is spent on.
+type breakdownWalker struct {
+	schema *ast.Schema
+	vars   Variables
+	cfg    Config
+}
+
+func (w breakdownWalker) selectionSetCosts(parent *ast.Definition, selectionSet ast.SelectionSet) typeCosts {
+	var (
+		costs      = make(typeCosts)
+		conditions = make(map[string]typeCosts)
+	)
+
+	for _, selection := range selectionSet {
+		if !included(selectionDirectives(selection), w.vars, w.cfg.logger()) {
+			continue
+		}
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			costs.add(w.fieldCosts(s))
+
+		case *ast.FragmentSpread:
+			if s.Definition == nil {
+				continue
+			}
+			w.fragmentCosts(parent, s.Definition.TypeCondition, s.Definition.SelectionSet, costs, conditions)
+
+		case *ast.InlineFragment:
+			w.fragmentCosts(parent, s.TypeCondition, s.SelectionSet, costs, conditions)
+		}
+	}
+
+	totals := make(map[string]int, len(conditions))
+	for typeCondition, c := range conditions {
+		totals[typeCondition] = c.total()
+	}
+	_, chosen := mostExpensiveCondition(w.schema, parent, totals, w.cfg.WorstCase, safeAdd)
+	for _, typeCondition := range chosen {
+		costs.add(conditions[typeCondition])
+	}
+
+	return costs
+}
+
+// fragmentCosts adds the costs of a fragment to costs, or to the costs of its
+// type condition when it narrows an abstract parent to another type.
+func (w breakdownWalker) fragmentCosts(parent *ast.Definition, typeCondition string, selectionSet ast.SelectionSet, costs typeCosts, conditions map[string]typeCosts) {
+	if typeCondition == "" || parent == nil || typeCondition == parent.Name {
+		costs.add(w.selectionSetCosts(parent, selectionSet))
+		return
+	}
+
+	fragmentCosts := w.selectionSetCosts(w.schema.Types[typeCondition], selectionSet)
+	if !isAbstract(parent) {
+		costs.add(fragmentCosts)
+		return
+	}
+
+	if conditions[typeCondition] == nil {
+		conditions[typeCondition] = make(typeCosts)
+	}
+	conditions[typeCondition].add(fragmentCosts)
+}
+
+// fieldCosts computes the costs of a single field including its selection
+// set. Fields of interfaces cost as much as their most expensive
+// implementation and are attributed to the interface.
+func (w breakdownWalker) fieldCosts(field *ast.Field) typeCosts {
+	if field.Definition == nil || field.ObjectDefinition == nil {
+		// The document has not been validated against the schema.
+		return nil
+	}
+
+	fieldType := w.schema.Types[field.Definition.Type.Name()]
+	if fieldType == nil || fieldType.Name == "__Schema" {
+		return nil
+	}
+
+	child := make(typeCosts)
+	switch fieldType.Kind {
+	case ast.Object, ast.Interface, ast.Union:
+		child = w.selectionSetCosts(fieldType, field.SelectionSet)
+	}
+
+	args := w.vars.Arguments(field)
+	object := field.ObjectDefinition
+	if object.Kind != ast.Interface {
+		return w.objectFieldCosts(object.Name, object.Name, field.Name, child, args)
+	}
+
+	var (
+		mostExpensive typeCosts
+		maxComplexity int
+	)
+	for _, t := range w.schema.GetPossibleTypes(object) {
+		costs := w.objectFieldCosts(object.Name, t.Name, field.Name, child, args)
+		if c := costs.total(); mostExpensive == nil || c > maxComplexity {
+			mostExpensive, maxComplexity = costs, c
+		}
+	}
+	return mostExpensive
+}
+
+// objectFieldCosts computes the costs of a field of the object type given the
+// costs of its selection set, attributing its weight to owner.
+func (w breakdownWalker) objectFieldCosts(owner, object, field string, child typeCosts, args map[string]any) typeCosts {
+	def := fieldDefinition(w.schema, object, field)
+	weight := fieldWeight(w.schema, def, object, args, w.cfg)
+	multiplier := fieldMultiplier(def, object, args, w.cfg)
+
+	// As in customComplexity, fields costing less than their selection set
+	// cost one more than it.
+	childTotal := child.total()
+	if safeAdd(weight, safeMul(childTotal, multiplier)) < childTotal {
+		weight, multiplier = 1, 1
+	}
+
+	costs := typeCosts{owner: weight}
+	for name, cost := range child {
+		costs[name] = safeAdd(costs[name], safeMul(cost, multiplier))
+	}
+	return costs
+}
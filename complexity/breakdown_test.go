@@ -0,0 +1,83 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestTypeBreakdown(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: `
+		type Query {
+			node(id: ID!): Node
+			user(id: ID!): User
+		}
+
+		interface Node {
+			id: ID!
+		}
+
+		type User implements Node {
+			id: ID!
+			name: String!
+			orders(first: Int): [Order!]!
+		}
+
+		type Order implements Node {
+			id: ID!
+			total: Int!
+		}
+	`})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, errs := gqlparser.LoadQuery(schemaDoc, `query GetUser {
+		user(id: 1) {
+			name
+			orders(first: 3) {
+				id
+				total
+			}
+		}
+		node(id: 1) {
+			id
+			... on Order {
+				total
+			}
+		}
+	}`)
+	if errs != nil {
+		t.Fatalf("failed to load query: %v", errs)
+	}
+
+	opts := []complexity.Option{complexity.WithListMultiplierArgs("first")}
+	got := complexity.TypeBreakdown(schemaDoc, queryDoc.Operations[0], opts...)
+
+	expected := []complexity.TypeComplexity{
+		{TypeName: "Order", Complexity: 7},
+		{TypeName: "Query", Complexity: 2},
+		{TypeName: "User", Complexity: 2},
+		{TypeName: "Node", Complexity: 1},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("TypeBreakdown() mismatch (-want +got):\n%s", diff)
+	}
+
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, append(opts, complexity.WithTypeBreakdown())...)
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	var total int
+	for _, tc := range result[0].Types {
+		total += tc.Complexity
+	}
+	if total != result[0].Complexity {
+		t.Errorf("TypeBreakdown() adds up to %d, want the complexity %d", total, result[0].Complexity)
+	}
+}
@@ -0,0 +1,21 @@
+package complexity
+
+import "math/bits"
+
+// BudgetPercent returns the complexity as a percentage of the budget, rounded
+// down and capped at the largest int. A budget or complexity of zero or less
+// yields zero.
+func BudgetPercent(complexity, budget int) int {
+	if budget <= 0 || complexity <= 0 {
+		return 0
+	}
+
+	// Saturated complexities overflow when multiplied by 100, so the product
+	// is computed in 128 bits.
+	hi, lo := bits.Mul64(uint64(complexity), 100)
+	if hi >= uint64(budget) {
+		return maxInt
+	}
+	percent, _ := bits.Div64(hi, lo, uint64(budget))
+	return int(min(percent, uint64(maxInt)))
+}
@@ -0,0 +1,28 @@
+package complexity_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+)
+
+func TestBudgetPercent(t *testing.T) {
+	tests := []struct {
+		complexity, budget, expected int
+	}{
+		{complexity: 50, budget: 200, expected: 25},
+		{complexity: 1, budget: 3, expected: 33},
+		{complexity: 300, budget: 200, expected: 150},
+		{complexity: 10, budget: 0, expected: 0},
+		{complexity: math.MaxInt, budget: 1, expected: math.MaxInt},
+		{complexity: math.MaxInt, budget: math.MaxInt, expected: 100},
+		{complexity: math.MaxInt / 2, budget: math.MaxInt, expected: 49},
+	}
+
+	for _, tt := range tests {
+		if got := complexity.BudgetPercent(tt.complexity, tt.budget); got != tt.expected {
+			t.Errorf("BudgetPercent(%d, %d) = %d, want %d", tt.complexity, tt.budget, got, tt.expected)
+		}
+	}
+}
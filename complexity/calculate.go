@@ -0,0 +1,190 @@
+package complexity
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// calculate computes the complexity of a selection set on the parent type,
+// such as the root type of an operation, following the rules of gqlgen's
+// complexity package, except that a selection set on an interface or union
+// costs the most expensive of its type conditions rather than their sum, as
+// only one concrete type is ever resolved per object. With worstCase set
+// fragments on abstract types are added to the concrete types they include,
+// see mostExpensiveCondition. With initial set only the initial payload of an
+// operation using incremental delivery is counted: deferred fragments are left
+// out and streamed list fields count their initial items. Selections left out
+// by @skip or @include are not counted, see included.
+func calculate(ctx context.Context, es graphql.ExecutableSchema, parent *ast.Definition, selectionSet ast.SelectionSet, vars Variables, log *slog.Logger, worstCase, initial bool) int {
+	w := walker{
+		es:        es,
+		schema:    es.Schema(),
+		vars:      vars,
+		log:       log,
+		worstCase: worstCase,
+		initial:   initial,
+	}
+
+	return w.selectionSetComplexity(ctx, parent, selectionSet)
+}
+
+type walker struct {
+	es        graphql.ExecutableSchema
+	schema    *ast.Schema
+	vars      Variables
+	log       *slog.Logger
+	worstCase bool
+	initial   bool
+}
+
+// selectionSetComplexity computes the complexity of a selection set on the
+// given parent type.
+func (w walker) selectionSetComplexity(ctx context.Context, parent *ast.Definition, selectionSet ast.SelectionSet) int {
+	var (
+		complexity int
+		conditions = make(map[string]int)
+	)
+
+	for _, selection := range selectionSet {
+		if !included(selectionDirectives(selection), w.vars, w.log) {
+			continue
+		}
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			complexity = safeAdd(complexity, w.fieldComplexity(ctx, s))
+
+		case *ast.FragmentSpread:
+			if s.Definition == nil || w.initial && isDeferred(s.Directives, w.vars) {
+				continue
+			}
+			complexity = w.fragmentComplexity(ctx, parent, s.Definition.TypeCondition, s.Definition.SelectionSet, complexity, conditions)
+
+		case *ast.InlineFragment:
+			if w.initial && isDeferred(s.Directives, w.vars) {
+				continue
+			}
+			complexity = w.fragmentComplexity(ctx, parent, s.TypeCondition, s.SelectionSet, complexity, conditions)
+		}
+	}
+
+	conditionComplexity, _ := mostExpensiveCondition(w.schema, parent, conditions, w.worstCase, safeAdd)
+	return safeAdd(complexity, conditionComplexity)
+}
+
+// fragmentComplexity adds the complexity of a fragment to the running total.
+// Fragments narrowing an abstract parent to another type are instead summed
+// per type condition, so that only the most expensive one is counted.
+func (w walker) fragmentComplexity(ctx context.Context, parent *ast.Definition, typeCondition string, selectionSet ast.SelectionSet, complexity int, conditions map[string]int) int {
+	if typeCondition == "" || parent == nil || typeCondition == parent.Name {
+		return safeAdd(complexity, w.selectionSetComplexity(ctx, parent, selectionSet))
+	}
+
+	fragmentComplexity := w.selectionSetComplexity(ctx, w.schema.Types[typeCondition], selectionSet)
+	if !isAbstract(parent) {
+		return safeAdd(complexity, fragmentComplexity)
+	}
+
+	conditions[typeCondition] = safeAdd(conditions[typeCondition], fragmentComplexity)
+	return complexity
+}
+
+// fieldComplexity computes the complexity of a single field including its
+// selection set.
+func (w walker) fieldComplexity(ctx context.Context, field *ast.Field) int {
+	if field.Definition == nil || field.ObjectDefinition == nil {
+		// The document has not been validated against the schema.
+		return 0
+	}
+
+	fieldType := w.schema.Types[field.Definition.Type.Name()]
+	if fieldType == nil || fieldType.Name == "__Schema" {
+		return 0
+	}
+
+	var childComplexity int
+	switch fieldType.Kind {
+	case ast.Object, ast.Interface, ast.Union:
+		childComplexity = w.selectionSetComplexity(ctx, fieldType, field.SelectionSet)
+	}
+
+	if n, ok := streamedItems(field, w.vars); ok && w.initial {
+		ctx = withStreamedItems(ctx, n)
+	}
+
+	args := w.vars.Arguments(field)
+	if field.ObjectDefinition.Kind == ast.Interface {
+		return w.interfaceFieldComplexity(ctx, field.ObjectDefinition, field.Name, childComplexity, args)
+	}
+
+	return w.customComplexity(ctx, field.ObjectDefinition.Name, field.Name, childComplexity, args)
+}
+
+// interfaceFieldComplexity computes the complexity of a field declared on an
+// interface as the complexity of its most expensive implementation.
+func (w walker) interfaceFieldComplexity(ctx context.Context, def *ast.Definition, field string, childComplexity int, args map[string]any) int {
+	var maxComplexity int
+	for _, t := range w.schema.GetPossibleTypes(def) {
+		maxComplexity = max(maxComplexity, w.customComplexity(ctx, t.Name, field, childComplexity, args))
+	}
+	return maxComplexity
+}
+
+// customComplexity asks the executable schema for the complexity of a field,
+// falling back to one plus its child complexity. A field streamed in the
+// initial payload may cost less than its children, as none of its items may
+// be sent with it.
+func (w walker) customComplexity(ctx context.Context, object, field string, childComplexity int, args map[string]any) int {
+	if c, ok := w.es.Complexity(ctx, object, field, childComplexity, args); ok && (c >= childComplexity || isStreamed(ctx)) {
+		return c
+	}
+	return safeAdd(1, childComplexity)
+}
+
+// rootTypeName returns the name of the root type of the operation.
+func rootTypeName(schema *ast.Schema, op *ast.OperationDefinition) string {
+	switch op.Operation {
+	case ast.Mutation:
+		if schema.Mutation != nil {
+			return schema.Mutation.Name
+		}
+	case ast.Subscription:
+		if schema.Subscription != nil {
+			return schema.Subscription.Name
+		}
+	default:
+		if schema.Query != nil {
+			return schema.Query.Name
+		}
+	}
+	return ""
+}
+
+// isAbstract reports whether the definition is an interface or a union.
+func isAbstract(def *ast.Definition) bool {
+	return def.Kind == ast.Interface || def.Kind == ast.Union
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// safeAdd is a saturating add of a and b that ignores negative operands, as
+// in gqlgen, so that complexities cannot be overflowed intentionally.
+func safeAdd(a, b int) int {
+	if a < 0 {
+		if b < 0 {
+			return 1
+		}
+		return b
+	} else if b < 0 {
+		return a
+	}
+
+	c := a + b
+	if c < a {
+		c = maxInt
+	}
+	return c
+}
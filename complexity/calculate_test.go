@@ -0,0 +1,201 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const abstractSchema = `type Query {
+	node(id: ID!): Node
+	search(term: String!): [SearchResult!]!
+}
+
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	name: String!
+	friends: [User!]!
+}
+
+type Bot implements Node {
+	id: ID!
+	name: String!
+}
+
+union SearchResult = User | Bot
+`
+
+func TestAnalyseDocumentAbstractTypes(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "abstract.graphqls", Input: abstractSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{
+			name: "interface",
+			query: `query GetNode {
+				node(id: 1) {
+					id
+					... on User { name friends { id } }
+					... on Bot { name }
+				}
+			}`,
+			// node + id + the User branch (name + friends + id)
+			expected: 5,
+		},
+		{
+			name: "union",
+			query: `query Search {
+				search(term: "gql") {
+					... on User { id name }
+					...BotFields
+				}
+			}
+
+			fragment BotFields on Bot { id name }`,
+			// search + the most expensive branch of two fields
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, err := parser.ParseQuery(&ast.Source{Name: tt.name + ".graphql", Input: tt.query})
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc)
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, result[0].Complexity); diff != "" {
+				t.Errorf("Complexity mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+const worstCaseSchema = `type Query {
+	node(id: ID!): Node
+}
+
+interface Node {
+	id: ID!
+}
+
+interface Named {
+	name: String!
+}
+
+type User implements Node & Named {
+	id: ID!
+	name: String!
+	email: String!
+}
+
+type Team implements Node & Named {
+	id: ID!
+	name: String!
+	members: [User!]!
+}
+
+type Bot implements Node {
+	id: ID!
+	model: String!
+}
+`
+
+func TestAnalyseDocumentWorstCase(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "worstcase.graphqls", Input: worstCaseSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		worstCase bool
+		expected  int
+	}{
+		{
+			name: "most expensive type condition",
+			query: `query GetNode {
+				node(id: 1) {
+					id
+					... on Named { name }
+					... on User { email }
+					... on Team { members { id } }
+					... on Bot { model }
+				}
+			}`,
+			// node + id + the Team branch (members + id)
+			expected: 4,
+		},
+		{
+			name: "worst case",
+			query: `query GetNode {
+				node(id: 1) {
+					id
+					... on Named { name }
+					... on User { email }
+					... on Team { members { id } }
+					... on Bot { model }
+				}
+			}`,
+			worstCase: true,
+			// node + id + a Team, paying for Named (name) and Team (members + id)
+			expected: 5,
+		},
+		{
+			name: "worst case without shared fragments",
+			query: `query GetNode {
+				node(id: 1) {
+					id
+					... on User { email }
+					... on Team { members { id } }
+					... on Bot { model }
+				}
+			}`,
+			worstCase: true,
+			// node + id + the Team branch (members + id)
+			expected: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, err := parser.ParseQuery(&ast.Source{Name: "node.graphql", Input: tt.query})
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			var opts []complexity.Option
+			if tt.worstCase {
+				opts = append(opts, complexity.WithWorstCase())
+			}
+
+			result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, opts...)
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+			if result[0].Complexity != tt.expected {
+				t.Errorf("AnalyseDocument() Complexity = %d, want %d", result[0].Complexity, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package complexity
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// changedDocuments returns the changed files whose documents are analysed.
+// A changed schema file can change the complexity of any document, so nil is
+// returned to analyse every document when one of the files of the schema spec
+// changed.
+func changedDocuments(fsys fs.FS, schema string, changed []string) ([]string, error) {
+	if changed == nil {
+		return nil, nil
+	}
+
+	files := make(map[string]bool, len(changed))
+	for _, name := range changed {
+		files[path.Clean(name)] = true
+	}
+
+	for _, globs := range parseSchemaSpec(schema) {
+		for _, glob := range globs {
+			if isURL(glob) {
+				continue
+			}
+
+			matches, err := fs.Glob(fsys, glob)
+			if err != nil {
+				return nil, fmt.Errorf("globbing schema files: %w: %w", ErrInvalidInput, err)
+			}
+			for _, match := range matches {
+				if files[match] {
+					return nil, nil
+				}
+			}
+		}
+	}
+
+	return changed, nil
+}
+
+// filterChanged returns the files that are among the changed files.
+func filterChanged(files, changed []string) []string {
+	keep := make(map[string]bool, len(changed))
+	for _, name := range changed {
+		keep[path.Clean(name)] = true
+	}
+
+	var filtered []string
+	for _, f := range files {
+		if keep[f] {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,34 @@
+package complexity
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// CompactFormatter writes a single line per file listing the complexity of
+// its operations as name=complexity pairs sorted by name, such as
+// "user.graphql: GetUser=12 ListOrders=30". Files keep the order in which
+// they first appear in the results.
+type CompactFormatter struct{}
+
+func (CompactFormatter) Format(w io.Writer, results []ComplexityAnalysis) error {
+	var sb strings.Builder
+	for _, group := range GroupByFile(results) {
+		ops := slices.Clone(group.Operations)
+		slices.SortStableFunc(ops, func(a, b ComplexityAnalysis) int {
+			return cmp.Compare(a.OperationName, b.OperationName)
+		})
+
+		sb.WriteString(group.Path + ":")
+		for _, op := range ops {
+			fmt.Fprintf(&sb, " %s=%d", op.OperationName, op.Complexity)
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
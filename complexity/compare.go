@@ -0,0 +1,145 @@
+package complexity
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ChangeStatus describes how an operation changed between two analyses.
+type ChangeStatus string
+
+const (
+	ChangeAdded     ChangeStatus = "added"
+	ChangeRemoved   ChangeStatus = "removed"
+	ChangeChanged   ChangeStatus = "changed"
+	ChangeUnchanged ChangeStatus = "unchanged"
+
+	// ChangeInvalidBefore and ChangeInvalidAfter mark operations of documents
+	// that only fail to validate against the schema before or after a
+	// change, see CompareSchemas.
+	ChangeInvalidBefore ChangeStatus = "invalid before"
+	ChangeInvalidAfter  ChangeStatus = "invalid after"
+)
+
+// Change holds the complexity of an operation before and after a change.
+type Change struct {
+	Path          string
+	OperationName string
+	Before        int
+	After         int
+	Delta         int
+	Status        ChangeStatus
+}
+
+// operationKey identifies an operation across analyses.
+type operationKey struct {
+	path, name string
+}
+
+// Compare matches the operations of two analyses by path and name and reports
+// how their complexity changed. Operations keep the order of before, followed
+// by the operations only present in after.
+func Compare(before, after []ComplexityAnalysis) []Change {
+	afterByKey := make(map[operationKey]ComplexityAnalysis, len(after))
+	for _, a := range after {
+		afterByKey[operationKey{a.Path, a.OperationName}] = a
+	}
+
+	var (
+		changes []Change
+		seen    = make(map[operationKey]bool, len(before))
+	)
+
+	for _, b := range before {
+		key := operationKey{b.Path, b.OperationName}
+		seen[key] = true
+
+		change := Change{Path: b.Path, OperationName: b.OperationName, Before: b.Complexity}
+
+		a, ok := afterByKey[key]
+		switch {
+		case !ok:
+			change.Delta = -b.Complexity
+			change.Status = ChangeRemoved
+		case a.Complexity == b.Complexity:
+			change.After = a.Complexity
+			change.Status = ChangeUnchanged
+		default:
+			change.After = a.Complexity
+			change.Delta = a.Complexity - b.Complexity
+			change.Status = ChangeChanged
+		}
+
+		changes = append(changes, change)
+	}
+
+	for _, a := range after {
+		if key := (operationKey{a.Path, a.OperationName}); !seen[key] {
+			seen[key] = true
+			changes = append(changes, Change{
+				Path:          a.Path,
+				OperationName: a.OperationName,
+				After:         a.Complexity,
+				Delta:         a.Complexity,
+				Status:        ChangeAdded,
+			})
+		}
+	}
+
+	return changes
+}
+
+// CompareSchemas analyses the documents against the schema before and after a
+// change, such as new field costs, and reports how the complexity of each
+// operation changed, see Compare. The schemas are globbed like the schema of
+// RunAnalysis. Operations of documents that fail to validate against only one
+// of the schemas are marked ChangeInvalidBefore or ChangeInvalidAfter rather
+// than added or removed.
+func CompareSchemas(ctx context.Context, before, after, docs string, opts ...Option) ([]Change, error) {
+	return CompareSchemasFS(ctx, os.DirFS("."), before, after, docs, opts...)
+}
+
+// CompareSchemasFS is like CompareSchemas but globs and reads the schema,
+// document and ignore files from fsys rather than the working directory.
+func CompareSchemasFS(ctx context.Context, fsys fs.FS, before, after, docs string, opts ...Option) ([]Change, error) {
+	cfg := newConfig(opts)
+
+	beforeResults, invalidBefore, err := analyseWithSchema(ctx, fsys, before, docs, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("analysing with the schema before: %w", err)
+	}
+	afterResults, invalidAfter, err := analyseWithSchema(ctx, fsys, after, docs, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("analysing with the schema after: %w", err)
+	}
+
+	changes := Compare(beforeResults, afterResults)
+	for i, change := range changes {
+		switch {
+		case change.Status == ChangeRemoved && invalidAfter[change.Path]:
+			changes[i].Status = ChangeInvalidAfter
+		case change.Status == ChangeAdded && invalidBefore[change.Path]:
+			changes[i].Status = ChangeInvalidBefore
+		}
+	}
+	return changes, nil
+}
+
+// analyseWithSchema analyses the documents against the schema and returns the
+// results together with the paths of the documents that could not be
+// analysed, which are still passed to the diagnostic handler of cfg.
+func analyseWithSchema(ctx context.Context, fsys fs.FS, schema, docs string, cfg Config) ([]ComplexityAnalysis, map[string]bool, error) {
+	invalid := make(map[string]bool)
+	handler := cfg.DiagnosticHandler
+	cfg.DiagnosticHandler = func(d Diagnostic) {
+		invalid[d.Path] = true
+		if handler != nil {
+			handler(d)
+		}
+	}
+
+	results, err := RunAnalysisFS(ctx, fsys, schema, docs, WithConfig(cfg))
+	return results, invalid, err
+}
@@ -0,0 +1,84 @@
+package complexity_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompare(t *testing.T) {
+	before := []complexity.ComplexityAnalysis{
+		{Path: "user.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "user.graphql", OperationName: "ListUsers", Complexity: 12},
+		{Path: "order.graphql", OperationName: "GetOrder", Complexity: 8},
+	}
+	after := []complexity.ComplexityAnalysis{
+		{Path: "order.graphql", OperationName: "GetOrder", Complexity: 8},
+		{Path: "user.graphql", OperationName: "GetUser", Complexity: 9},
+		{Path: "user.graphql", OperationName: "GetFriends", Complexity: 4},
+	}
+
+	expected := []complexity.Change{
+		{Path: "user.graphql", OperationName: "GetUser", Before: 5, After: 9, Delta: 4, Status: complexity.ChangeChanged},
+		{Path: "user.graphql", OperationName: "ListUsers", Before: 12, Delta: -12, Status: complexity.ChangeRemoved},
+		{Path: "order.graphql", OperationName: "GetOrder", Before: 8, After: 8, Status: complexity.ChangeUnchanged},
+		{Path: "user.graphql", OperationName: "GetFriends", After: 4, Delta: 4, Status: complexity.ChangeAdded},
+	}
+
+	if diff := cmp.Diff(expected, complexity.Compare(before, after)); diff != "" {
+		t.Errorf("Compare() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareSchemasFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"old/schema.graphqls": {Data: []byte(`type Query { user(id: ID!): User users: [User!]! }
+			type User { id: ID! name: String! email: String! }`)},
+		"new/schema.graphqls": {Data: []byte(`type Query { user(id: ID!): User @cost(weight: 3) users: [User!]! }
+			type User { id: ID! name: String! avatar: String! }`)},
+		"q/user.graphql":   {Data: []byte(`query GetUser { user(id: 1) { id name } }`)},
+		"q/users.graphql":  {Data: []byte(`query ListUsers { users { id } }`)},
+		"q/email.graphql":  {Data: []byte(`query GetEmail { user(id: 1) { email } }`)},
+		"q/avatar.graphql": {Data: []byte(`query GetAvatar { user(id: 1) { avatar } }`)},
+	}
+
+	var diagnostics []string
+	handler := complexity.WithDiagnosticHandler(func(d complexity.Diagnostic) {
+		diagnostics = append(diagnostics, d.Path)
+	})
+
+	changes, err := complexity.CompareSchemasFS(t.Context(), fsys, "old/*.graphqls", "new/*.graphqls", "q/*.graphql", handler)
+	if err != nil {
+		t.Fatalf("CompareSchemasFS() error = %v", err)
+	}
+
+	expected := []complexity.Change{
+		{Path: "q/email.graphql", OperationName: "GetEmail", Before: 2, Delta: -2, Status: complexity.ChangeInvalidAfter},
+		{Path: "q/user.graphql", OperationName: "GetUser", Before: 3, After: 5, Delta: 2, Status: complexity.ChangeChanged},
+		{Path: "q/users.graphql", OperationName: "ListUsers", Before: 2, After: 2, Status: complexity.ChangeUnchanged},
+		{Path: "q/avatar.graphql", OperationName: "GetAvatar", After: 4, Delta: 4, Status: complexity.ChangeInvalidBefore},
+	}
+	if diff := cmp.Diff(expected, changes); diff != "" {
+		t.Errorf("CompareSchemasFS() mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"q/avatar.graphql", "q/email.graphql"}, diagnostics); diff != "" {
+		t.Errorf("CompareSchemasFS() diagnostics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompareSchemasFSInvalidSchema(t *testing.T) {
+	fsys := fstest.MapFS{
+		"old/schema.graphqls": {Data: []byte(`type Query { user: String }`)},
+		"new/schema.graphqls": {Data: []byte(`type Query { user: }`)},
+		"q/user.graphql":      {Data: []byte(`query { user }`)},
+	}
+
+	_, err := complexity.CompareSchemasFS(t.Context(), fsys, "old/*.graphqls", "new/*.graphqls", "q/*.graphql")
+	if !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Errorf("CompareSchemasFS() error = %v, want %v", err, complexity.ErrInvalidInput)
+	}
+}
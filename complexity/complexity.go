@@ -3,84 +3,435 @@ package complexity
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
-	"log/slog"
 	"os"
+	"slices"
+	"strings"
 
-	"github.com/99designs/gqlgen/complexity"
 	"github.com/99designs/gqlgen/graphql"
-	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/parser"
-	"github.com/vektah/gqlparser/v2/validator"
-	"github.com/vektah/gqlparser/v2/validator/rules"
 )
 
+// ErrInvalidInput is wrapped by errors caused by invalid user input, such as a
+// malformed glob pattern or a schema that cannot be loaded.
+var ErrInvalidInput = errors.New("invalid input")
+
+// StdinDocument is the document glob reading a document from standard input,
+// or Config.Stdin, reported with the file name "<stdin>". As a schema glob it
+// reads the SDL of the schema instead, reported as "<stdin-schema>".
+const StdinDocument = "-"
+
+const (
+	stdinName       = "<stdin>"
+	stdinSchemaName = "<stdin-schema>"
+)
+
+// ErrNoMatches is wrapped, together with ErrInvalidInput, by errors for
+// document or schema globs matching no files when matches are required, see
+// Config.RequireMatches.
+var ErrNoMatches = errors.New("no files matched")
+
+// DefaultDocuments are the globs of the documents analysed by the command
+// when no others are given.
+const DefaultDocuments = "*.graphql,*.gql"
+
 // ComplexityAnalysis holds the complexity analysis result for a single operation
 type ComplexityAnalysis struct {
-	Path                string
-	OperationName       string
-	Complexity          int
-	FlattenedComplexity int
+	Path                string                   `json:"path" yaml:"path" toml:"path"`
+	OperationName       string                   `json:"operation" yaml:"operation" toml:"operation"`
+	OperationType       ast.Operation            `json:"operationType" yaml:"operationType" toml:"operationType"`
+	Line                int                      `json:"line,omitempty" yaml:"line,omitempty" toml:"line,omitzero"`
+	Column              int                      `json:"column,omitempty" yaml:"column,omitempty" toml:"column,omitzero"`
+	EndLine             int                      `json:"endLine,omitempty" yaml:"endLine,omitempty" toml:"endLine,omitzero"`
+	Complexity          int                      `json:"complexity" yaml:"complexity" toml:"complexity"`
+	InitialComplexity   int                      `json:"initialComplexity,omitempty" yaml:"initialComplexity,omitempty" toml:"initialComplexity,omitzero"`
+	FlattenedComplexity int                      `json:"flattenedComplexity" yaml:"flattenedComplexity" toml:"flattenedComplexity"`
+	RootFields          int                      `json:"rootFields" yaml:"rootFields" toml:"rootFields"`
+	Depth               int                      `json:"depth" yaml:"depth" toml:"depth"`
+	Arguments           int                      `json:"arguments" yaml:"arguments" toml:"arguments"`
+	UniqueFields        int                      `json:"uniqueFields" yaml:"uniqueFields" toml:"uniqueFields"`
+	ResponseNodes       int                      `json:"responseNodes" yaml:"responseNodes" toml:"responseNodes"`
+	MaxComplexity       int                      `json:"maxComplexity,omitempty" yaml:"maxComplexity,omitempty" toml:"maxComplexity,omitzero"`
+	UnusedVariables     []string                 `json:"unusedVariables,omitempty" yaml:"unusedVariables,omitempty" toml:"unusedVariables,omitempty"`
+	UndefinedVariables  []string                 `json:"undefinedVariables,omitempty" yaml:"undefinedVariables,omitempty" toml:"undefinedVariables,omitempty"`
+	Aliases             []AliasCount             `json:"aliases,omitempty" yaml:"aliases,omitempty" toml:"aliases,omitempty"`
+	Directives          []DirectiveCount         `json:"directives,omitempty" yaml:"directives,omitempty" toml:"directives,omitempty"`
+	Fragments           []FragmentComplexity     `json:"fragments,omitempty" yaml:"fragments,omitempty" toml:"fragments,omitempty"`
+	FragmentDeltas      []FragmentDelta          `json:"fragmentDeltas,omitempty" yaml:"fragmentDeltas,omitempty" toml:"fragmentDeltas,omitempty"`
+	Types               []TypeComplexity         `json:"types,omitempty" yaml:"types,omitempty" toml:"types,omitempty"`
+	Roots               []RootField              `json:"roots,omitempty" yaml:"roots,omitempty" toml:"roots,omitempty"`
+	Explain             []ComplexityNode         `json:"explain,omitempty" yaml:"explain,omitempty" toml:"explain,omitempty"`
+	Coverage            []FieldCoverage          `json:"coverage,omitempty" yaml:"coverage,omitempty" toml:"coverage,omitempty"`
+	Timing              *Timing                  `json:"timing,omitempty" yaml:"timing,omitempty" toml:"timing,omitempty"`
+	Source              string                   `json:"source,omitempty" yaml:"source,omitempty" toml:"source,omitempty"`
+	Flattened           *ast.OperationDefinition `json:"-" yaml:"-" toml:"-"`
+}
+
+// RunAnalysis analyses every operation in the documents matching the docs
+// glob against the schema loaded from the schema globs.
+//
+// The schema is a comma separated list of globs. Entries of the form
+// name=glob load a named schema, which documents select with a comment such
+// as "# gql:schema name". Documents without such a comment are validated
+// against the schema loaded from the plain globs.
+//
+// Documents may also be persisted query manifests, see Config.Manifest.
+//
+// Schema and document files matching the patterns of the IgnoreFile in the
+// working directory are left out unless the analysis is configured otherwise.
+func RunAnalysis(ctx context.Context, schema, docs string, opts ...Option) ([]ComplexityAnalysis, error) {
+	return RunAnalysisFS(ctx, os.DirFS("."), schema, docs, opts...)
 }
 
-func RunAnalysis(ctx context.Context, schema, docs string) ([]ComplexityAnalysis, error) {
-	schemas, err := fs.Glob(os.DirFS("."), schema)
+// RunAnalysisFS is like RunAnalysis but globs and reads the schema, document
+// and ignore files from fsys rather than the working directory.
+func RunAnalysisFS(ctx context.Context, fsys fs.FS, schema, docs string, opts ...Option) ([]ComplexityAnalysis, error) {
+	var results []ComplexityAnalysis
+	err := StreamAnalysisFS(ctx, fsys, schema, docs, func(r ComplexityAnalysis) error {
+		results = append(results, r)
+		return nil
+	}, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("globbing schema files: %w", err)
+		return nil, err
 	}
 
-	var inputs []*ast.Source
-	for _, schemaPath := range schemas {
-		fileBytes, err := os.ReadFile(schemaPath)
-		if err != nil {
-			return nil, fmt.Errorf("reading schema file %s: %w", schemaPath, err)
+	return results, nil
+}
+
+// StreamAnalysis is like RunAnalysis but calls emit with the result of each
+// operation as soon as its document is analysed rather than collecting them.
+// The analysis stops at the first error returned by emit, which is returned,
+// and when ctx is cancelled.
+func StreamAnalysis(ctx context.Context, schema, docs string, emit func(ComplexityAnalysis) error, opts ...Option) error {
+	return StreamAnalysisFS(ctx, os.DirFS("."), schema, docs, emit, opts...)
+}
+
+// errStop stops the analysis once Config.StopWhen is met.
+var errStop = errors.New("stop analysis")
+
+// StreamAnalysisFS is like StreamAnalysis but globs and reads the schema,
+// document and ignore files from fsys rather than the working directory.
+//
+// Results are only held once emitted when emit keeps them. Writers of a
+// StreamFormatter that need every result do, such as the one of
+// TableFormatter, which keeps the text of every row until it is closed to
+// align the columns, so its memory grows with the number of operations.
+func StreamAnalysisFS(ctx context.Context, fsys fs.FS, schema, docs string, emit func(ComplexityAnalysis) error, opts ...Option) error {
+	cfg := newConfig(opts)
+
+	var ignore *Ignore
+	if !cfg.NoIgnore {
+		var err error
+		if ignore, err = loadIgnore(fsys, cfg.IgnorePatterns); err != nil {
+			return err
 		}
+	}
 
-		inputs = append(inputs, &ast.Source{Input: string(fileBytes), Name: schemaPath, BuiltIn: false})
+	if err := checkStdin(schema, docs); err != nil {
+		return err
 	}
 
-	schemaDoc, err := gqlparser.LoadSchema(inputs...)
-	if err != nil {
-		return nil, fmt.Errorf("loading schema: %w", err)
+	// Unknown validation rules fail the analysis rather than every document.
+	if _, err := cfg.validationRules(); err != nil {
+		return err
 	}
 
-	matches, err := fs.Glob(os.DirFS("."), docs)
+	schemas, err := loadSchemas(ctx, fsys, schema, cfg, ignore)
 	if err != nil {
-		return nil, fmt.Errorf("globbing documents files: %w", err)
+		return err
 	}
 
-	var results []ComplexityAnalysis
-	for _, match := range matches {
-		fileBytes, err := os.ReadFile(match)
+	if cfg.ChangedFiles, err = changedDocuments(fsys, schema, cfg.ChangedFiles); err != nil {
+		return err
+	}
+
+	emitResult := func(r ComplexityAnalysis) error {
+		if err := emit(r); err != nil {
+			return err
+		}
+		if cfg.StopWhen != nil && cfg.StopWhen(r) {
+			return errStop
+		}
+		return nil
+	}
+
+	// The total of a batch is emitted after the results of its requests.
+	var total *ComplexityAnalysis
+	emitTotal := func() error {
+		if total == nil {
+			return nil
+		}
+		r := *total
+		total = nil
+		return emitResult(r)
+	}
+
+	analyse := func(doc document) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if total != nil && total.Path != doc.batch {
+			if err := emitTotal(); err != nil {
+				return err
+			}
+		}
+		if doc.batch != "" && total == nil {
+			total = &ComplexityAnalysis{Path: doc.batch, OperationName: BatchTotalOperationName}
+		}
+
+		docCfg := cfg
+		if doc.variables != nil {
+			docCfg.Variables = doc.variables
+		}
+
+		analysis, err := analyseSource(ctx, schemas, doc.Source, docCfg)
 		if err != nil {
-			slog.Warn("Reading query file", "file", match, "error", err)
-			continue
+			return err
 		}
 
-		source := ast.Source{Input: string(fileBytes), Name: match, BuiltIn: false}
+		for _, r := range analysis {
+			if doc.operationName != "" && r.OperationName != doc.operationName {
+				continue
+			}
+			if err := emitResult(r); err != nil {
+				return err
+			}
+			if total != nil {
+				addToBatch(total, r)
+			}
+		}
+		return nil
+	}
 
-		queryDoc, err := parser.ParseQuery(&source)
+	// Operations in schema files are analysed before the documents.
+	if cfg.Mixed {
+		mixed, err := mixedDocuments(fsys, schema, cfg, ignore)
 		if err != nil {
-			slog.Warn("Parsing query", "file", match, "error", err)
+			return err
+		}
+		for _, doc := range mixed {
+			if err := analyse(doc); err != nil {
+				if errors.Is(err, errStop) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+
+	err = documentSources(fsys, docs, cfg, ignore, analyse)
+	if err == nil {
+		err = emitTotal()
+	}
+	if errors.Is(err, errStop) {
+		return nil
+	}
+	return err
+}
+
+// documentSources reads the documents matching the comma separated docs globs
+// that are not ignored, and are among the Config.ChangedFiles when set, one at
+// a time and calls fn with each. Manifests yield one document per query and
+// batched requests one per request. Files that cannot be read are reported
+// and skipped. The first error returned by fn stops reading and is returned.
+func documentSources(fsys fs.FS, docs string, cfg Config, ignore *Ignore, fn func(document) error) error {
+	matches, fsys, err := globDocuments(fsys, docs)
+	if err != nil {
+		return err
+	}
+
+	files := ignore.Filter(matches)
+	if len(files) == 0 {
+		if err := cfg.noMatches("document", docs); err != nil {
+			return err
+		}
+	}
+	if cfg.ChangedFiles != nil {
+		files = filterChanged(files, cfg.ChangedFiles)
+	}
+
+	for i, match := range files {
+		for _, doc := range readDocument(fsys, match, cfg) {
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+
+		if cfg.ProgressHandler != nil {
+			cfg.ProgressHandler(i+1, len(files))
+		}
+	}
+
+	return nil
+}
+
+// globDocuments returns the files matching any of the comma separated globs,
+// in the order of the globs and without duplicates. Entries naming an archive
+// match the documents within it, see OpenArchive, below the archive's name.
+// The returned file system reads the matched files.
+func globDocuments(fsys fs.FS, docs string) ([]string, fs.FS, error) {
+	var (
+		matches  []string
+		seen     = make(map[string]bool)
+		archives = make(map[string]fs.FS)
+	)
+	for glob := range strings.SplitSeq(docs, ",") {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
 			continue
 		}
 
-		analysis, err := AnalyseDocument(ctx, schemaDoc, queryDoc)
+		var globMatches []string
+		if glob == StdinDocument {
+			globMatches = []string{StdinDocument}
+		} else if isArchive(glob) {
+			archive, err := OpenArchive(fsys, glob)
+			if err != nil {
+				return nil, nil, err
+			}
+			archives[glob] = archive
+
+			entries, err := archiveDocuments(archive)
+			if err != nil {
+				return nil, nil, fmt.Errorf("reading archive %s: %w", glob, err)
+			}
+			for _, entry := range entries {
+				globMatches = append(globMatches, glob+"/"+entry)
+			}
+		} else {
+			var err error
+			if globMatches, err = fs.Glob(fsys, glob); err != nil {
+				return nil, nil, fmt.Errorf("globbing documents files: %w: %w", ErrInvalidInput, err)
+			}
+		}
+
+		for _, match := range globMatches {
+			if !seen[match] {
+				seen[match] = true
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	if len(archives) > 0 {
+		fsys = archivesFS{FS: fsys, archives: archives}
+	}
+	return matches, fsys, nil
+}
+
+// readDocument reads the documents of the file. Files that cannot be read are
+// reported and yield no documents.
+func readDocument(fsys fs.FS, name string, cfg Config) []document {
+	var (
+		fileBytes []byte
+		err       error
+	)
+	if name == StdinDocument {
+		name = stdinName
+		fileBytes, err = io.ReadAll(cfg.stdin())
+	} else {
+		fileBytes, err = fs.ReadFile(fsys, name)
+	}
+	if err != nil {
+		cfg.report("Reading query file", name, err)
+		return nil
+	}
+
+	if cfg.Batch {
+		docs, err := batchDocuments(name, fileBytes)
 		if err != nil {
-			slog.Warn("Analysing document", "file", match, "error", err)
-			continue
+			cfg.report("Reading batched request", name, err)
+			return nil
 		}
+		return docs
+	}
 
-		for _, res := range analysis {
-			results = append(results, ComplexityAnalysis{
-				Path:                match,
-				OperationName:       res.OperationName,
-				Complexity:          res.Complexity,
-				FlattenedComplexity: res.FlattenedComplexity,
-			})
+	if name == stdinName || !cfg.Manifest && !isManifest(name) {
+		return []document{{Source: &ast.Source{Input: string(fileBytes), Name: name, BuiltIn: false}}}
+	}
+
+	sources, err := manifestSources(name, fileBytes)
+	if err != nil {
+		cfg.report("Reading manifest", name, err)
+		return nil
+	}
+
+	docs := make([]document, 0, len(sources))
+	for _, source := range sources {
+		docs = append(docs, document{Source: source})
+	}
+	return docs
+}
+
+// analyseSource analyses the operations of a single document. Documents that
+// cannot be parsed or analysed are reported and yield no results.
+func analyseSource(ctx context.Context, schemas map[string]*ast.Schema, source *ast.Source, cfg Config) ([]ComplexityAnalysis, error) {
+	start := cfg.now()
+	queryDoc, err := parser.ParseQuery(source)
+	parse := cfg.since(start)
+	if err != nil {
+		cfg.report("Parsing query", source.Name, err)
+		return nil, nil
+	}
+
+	// Empty and comment only documents, as well as documents only declaring
+	// fragments for others to use, have nothing to analyse.
+	if len(queryDoc.Operations) == 0 {
+		cfg.logger().Info("Skipping document without operations", "file", source.Name)
+		return nil, nil
+	}
+
+	schemaDoc, err := selectSchema(schemas, source.Input)
+	if err != nil {
+		return nil, fmt.Errorf("selecting schema for %s: %w", source.Name, err)
+	}
+
+	analysis, err := AnalyseDocument(ctx, schemaDoc, queryDoc, WithConfig(cfg))
+	if err != nil {
+		cfg.report("Analysing document", source.Name, err)
+		return nil, nil
+	}
+
+	var results []ComplexityAnalysis
+	for _, res := range analysis {
+		if res.Timing != nil {
+			res.Timing.Parse = parse
 		}
+		results = append(results, ComplexityAnalysis{
+			Path:                source.Name,
+			OperationName:       res.OperationName,
+			OperationType:       res.OperationType,
+			Line:                res.Line,
+			Column:              res.Column,
+			EndLine:             res.EndLine,
+			Complexity:          res.Complexity,
+			InitialComplexity:   res.InitialComplexity,
+			FlattenedComplexity: res.FlattenedComplexity,
+			RootFields:          res.RootFields,
+			Depth:               res.Depth,
+			Arguments:           res.Arguments,
+			UniqueFields:        res.UniqueFields,
+			ResponseNodes:       res.ResponseNodes,
+			MaxComplexity:       res.MaxComplexity,
+			UnusedVariables:     res.UnusedVariables,
+			UndefinedVariables:  res.UndefinedVariables,
+			Aliases:             res.Aliases,
+			Directives:          res.Directives,
+			Fragments:           res.Fragments,
+			FragmentDeltas:      res.FragmentDeltas,
+			Types:               res.Types,
+			Roots:               res.Roots,
+			Explain:             res.Explain,
+			Coverage:            res.Coverage,
+			Timing:              res.Timing,
+			Source:              res.Source,
+			Flattened:           res.Flattened,
+		})
 	}
 
 	return results, nil
@@ -88,45 +439,226 @@ func RunAnalysis(ctx context.Context, schema, docs string) ([]ComplexityAnalysis
 
 type DocumentAnalysis struct {
 	OperationName       string
+	OperationType       ast.Operation
+	Line                int
+	Column              int
+	EndLine             int
 	Complexity          int
+	InitialComplexity   int
 	FlattenedComplexity int
+	RootFields          int
+	Depth               int
+	Arguments           int
+	UniqueFields        int
+	ResponseNodes       int
+	MaxComplexity       int
+	UnusedVariables     []string
+	UndefinedVariables  []string
+	Aliases             []AliasCount
+	Directives          []DirectiveCount
+	Fragments           []FragmentComplexity
+	FragmentDeltas      []FragmentDelta
+	Types               []TypeComplexity
+	Roots               []RootField
+	Explain             []ComplexityNode
+	Coverage            []FieldCoverage
+	Timing              *Timing
+	Source              string
+	Flattened           *ast.OperationDefinition
 }
 
-func AnalyseDocument(ctx context.Context, schemaDoc *ast.Schema, queryDoc *ast.QueryDocument) ([]DocumentAnalysis, error) {
-	if err := validator.ValidateWithRules(schemaDoc, queryDoc, rules.NewDefaultRules()); err != nil {
-		return nil, fmt.Errorf("validating query document: %w", err)
+func AnalyseDocument(ctx context.Context, schemaDoc *ast.Schema, queryDoc *ast.QueryDocument, opts ...Option) ([]DocumentAnalysis, error) {
+	cfg := newConfig(opts)
+
+	start := cfg.now()
+	if !cfg.SkipValidation {
+		if err := ValidateDocument(schemaDoc, queryDoc, WithConfig(cfg)); err != nil {
+			return nil, err
+		}
 	}
+	validate := cfg.since(start)
 
 	s := graphql.ExecutableSchemaMock{
 		ComplexityFunc: func(ctx context.Context, typeName string, fieldName string, childComplexity int, args map[string]any) (int, bool) {
-			return childComplexity + 1, true
+			if cfg.ComplexityFunc != nil {
+				if c, ok := cfg.ComplexityFunc(ctx, typeName, fieldName, childComplexity, args); ok {
+					return c, true
+				}
+			}
+			def := fieldDefinition(schemaDoc, typeName, fieldName)
+			multiplier := streamedMultiplier(ctx, fieldMultiplier(def, typeName, args, cfg))
+			return safeAdd(fieldWeight(schemaDoc, def, typeName, args, cfg), safeMul(childComplexity, multiplier)), true
 		},
 		ExecFunc:   func(ctx context.Context) graphql.ResponseHandler { return nil },
 		SchemaFunc: func() *ast.Schema { return schemaDoc },
 	}
 
+	cost := func(parent *ast.Definition, selectionSet ast.SelectionSet, vars Variables, initial bool) int {
+		if cfg.DepthDecay > 0 {
+			return calculateDecayed(schemaDoc, parent, selectionSet, vars, cfg, initial)
+		}
+		return calculate(ctx, &s, parent, selectionSet, vars, cfg.logger(), cfg.WorstCase, initial)
+	}
+
 	var documentResults []DocumentAnalysis
-	for _, op := range queryDoc.Operations {
-		flatOp := flatten(queryDoc, op)
+	ends := documentEndLines(queryDoc)
+	var typed *ast.Schema
+	if cfg.TypeConditions {
+		typed = schemaDoc
+	}
+	flat := newFlattener(queryDoc, typed)
+	for i, op := range queryDoc.Operations {
+		start := cfg.now()
+		maxComplexity, err := maxComplexityHint(op)
+		if err != nil {
+			return nil, err
+		}
 
-		documentResults = append(documentResults, DocumentAnalysis{
-			OperationName:       op.Name,
-			Complexity:          complexity.Calculate(ctx, &s, op, nil),
-			FlattenedComplexity: complexity.Calculate(ctx, &s, flatOp, nil),
-		})
+		flatOp := flatten(flat, op)
+		vars := NewVariables(op, cfg.Variables)
+		root := schemaDoc.Types[rootTypeName(schemaDoc, op)]
+
+		line, column, endLine := operationPosition(op, ends)
+		res := DocumentAnalysis{
+			OperationName:       operationName(op, i),
+			OperationType:       op.Operation,
+			Line:                line,
+			Column:              column,
+			EndLine:             endLine,
+			Complexity:          cost(root, op.SelectionSet, vars, false),
+			FlattenedComplexity: cost(root, flatOp.SelectionSet, vars, false),
+			RootFields:          len(flatOp.SelectionSet),
+			Depth:               selectionSetDepth(flatOp.SelectionSet),
+			Arguments:           CountArguments(queryDoc, op),
+			UniqueFields:        CountUniqueFields(queryDoc, op),
+			ResponseNodes:       responseNodes(flatOp.SelectionSet, vars, cfg),
+			MaxComplexity:       maxComplexity,
+			Aliases:             CountAliases(queryDoc, op),
+			Directives:          CountDirectives(queryDoc, op),
+			Flattened:           flatOp,
+		}
+		if usesIncrementalDelivery(queryDoc, op.SelectionSet) {
+			res.InitialComplexity = cost(root, op.SelectionSet, vars, true)
+		}
+		if cfg.StrictVariables {
+			res.UnusedVariables, res.UndefinedVariables = operationVariableIssues(queryDoc, op)
+		}
+		if cfg.IncludeSource {
+			res.Source = operationSource(queryDoc, op)
+		}
+		if cfg.ByType {
+			res.Types = TypeBreakdown(schemaDoc, op, WithConfig(cfg))
+		}
+		if cfg.Roots {
+			res.Roots = OperationRootFields(schemaDoc, queryDoc, op)
+		}
+		if cfg.Explain {
+			res.Explain = Explain(schemaDoc, op, WithConfig(cfg))
+		}
+		if cfg.Coverage {
+			res.Coverage = OperationFieldCoverage(schemaDoc, queryDoc, op)
+		}
+		if cfg.ByFragment {
+			for _, frag := range usedFragments(queryDoc, op.SelectionSet) {
+				res.Fragments = append(res.Fragments, FragmentComplexity{
+					FragmentName: frag.Name,
+					Complexity:   cost(schemaDoc.Types[frag.TypeCondition], frag.SelectionSet, vars, false),
+				})
+			}
+		}
+		if cfg.FragmentDeltas {
+			res.FragmentDeltas = fragmentDeltas(queryDoc, op, typed, res.FlattenedComplexity, func(flatOp *ast.OperationDefinition) int {
+				return cost(root, flatOp.SelectionSet, vars, false)
+			})
+		}
+		if cfg.Profile {
+			res.Timing = &Timing{Validate: validate, Calculate: cfg.since(start)}
+		}
+
+		documentResults = append(documentResults, res)
 	}
 	return documentResults, nil
 }
 
-// flatten will flatten the operation by inlining all fragments.
-func flatten(doc *ast.QueryDocument, op *ast.OperationDefinition) *ast.OperationDefinition {
+// AnalyseString parses the query and analyses its operations against the
+// schema, see AnalyseDocument. An error parsing the query wraps
+// ErrInvalidInput and the *gqlerror.Error locating the problem in the query.
+func AnalyseString(ctx context.Context, schemaDoc *ast.Schema, query string, opts ...Option) ([]DocumentAnalysis, error) {
+	queryDoc, err := parser.ParseQuery(&ast.Source{Input: query, BuiltIn: false})
+	if err != nil {
+		return nil, fmt.Errorf("parsing query: %w: %w", ErrInvalidInput, err)
+	}
+	return AnalyseDocument(ctx, schemaDoc, queryDoc, opts...)
+}
+
+// operationName returns the name of the operation at index i of its
+// document. Anonymous operations are named after their index, such as
+// "<anonymous#0>", so they stay identifiable across runs.
+func operationName(op *ast.OperationDefinition, i int) string {
+	if op.Name != "" {
+		return op.Name
+	}
+	return fmt.Sprintf("<anonymous#%d>", i)
+}
+
+// Flatten returns the operation with all fragments of the document inlined
+// and repeated fields merged, so its selection sets only contain fields.
+// Operations without fragments or repeated fields are already flat and are
+// returned as they are rather than copied, so the result must not be
+// modified.
+func Flatten(doc *ast.QueryDocument, op *ast.OperationDefinition) *ast.OperationDefinition {
+	return flatten(newFlattener(doc, nil), op)
+}
+
+// flatten flattens the operation like Flatten, sharing the flattened
+// fragments of the flattener.
+func flatten(f *flattener, op *ast.OperationDefinition) *ast.OperationDefinition {
+	if isFlat(op.SelectionSet) {
+		return op
+	}
+	return flattenOperation(f, op)
+}
+
+// isFlat reports whether flattening the selection set would leave it
+// unchanged: it and the selection sets below it contain no fragments and no
+// fields that would be merged.
+func isFlat(selectionSet ast.SelectionSet) bool {
+	var keys map[string]bool
+	for _, selection := range selectionSet {
+		field, ok := selection.(*ast.Field)
+		if !ok {
+			return false
+		}
+
+		// Keys are only computed for selection sets with several fields.
+		if len(selectionSet) > 1 {
+			if keys == nil {
+				keys = make(map[string]bool, len(selectionSet))
+			}
+			key := fieldKey(field)
+			if keys[key] {
+				return false
+			}
+			keys[key] = true
+		}
+
+		if !isFlat(field.SelectionSet) {
+			return false
+		}
+	}
+	return true
+}
+
+// flattenOperation returns a copy of the operation with all fragments inlined
+// and repeated fields merged.
+func flattenOperation(f *flattener, op *ast.OperationDefinition) *ast.OperationDefinition {
 	// Create a deep copy of the operation
 	flattened := &ast.OperationDefinition{
 		Operation:           op.Operation,
 		Name:                op.Name,
 		VariableDefinitions: make([]*ast.VariableDefinition, len(op.VariableDefinitions)),
 		Directives:          make(ast.DirectiveList, len(op.Directives)),
-		SelectionSet:        flattenSelectionSet(op.SelectionSet, doc),
+		SelectionSet:        f.selectionSet(f.rootType(op), op.SelectionSet),
 		Position:            op.Position,
 		Comment:             op.Comment,
 	}
@@ -140,106 +672,324 @@ func flatten(doc *ast.QueryDocument, op *ast.OperationDefinition) *ast.Operation
 	return flattened
 }
 
-// flattenSelectionSet recursively flattens a selection set by inlining fragments
-func flattenSelectionSet(selectionSet ast.SelectionSet, doc *ast.QueryDocument) ast.SelectionSet {
-	fieldMap := make(map[string]*ast.Field)
+// fieldKey identifies the selections of a field that are merged into one when
+// flattening. Selections only merge when they share their alias, name,
+// arguments and directives, as fields with different arguments resolve to
+// different values, and a field left out by @skip or @include must not take
+// the selections of a field that is not along with it.
+func fieldKey(field *ast.Field) string {
+	var sb strings.Builder
+	if field.Alias != "" {
+		sb.WriteString(field.Alias + ":")
+	}
+	sb.WriteString(field.Name)
+	writeArguments(&sb, field.Arguments)
+
+	directives := slices.Clone(field.Directives)
+	slices.SortStableFunc(directives, func(a, b *ast.Directive) int { return strings.Compare(a.Name, b.Name) })
+	for _, d := range directives {
+		sb.WriteString("@" + d.Name)
+		writeArguments(&sb, d.Arguments)
+	}
+	return sb.String()
+}
 
-	for _, selection := range selectionSet {
-		switch sel := selection.(type) {
-		case *ast.Field:
-			// Create a key for deduplication based on field name and alias
-			key := sel.Name
-			if sel.Alias != "" {
-				key = sel.Alias + ":" + sel.Name
+// writeArguments writes the arguments sorted by name, or nothing when there
+// are none.
+func writeArguments(sb *strings.Builder, arguments ast.ArgumentList) {
+	if len(arguments) == 0 {
+		return
+	}
+
+	args := slices.Clone(arguments)
+	slices.SortFunc(args, func(a, b *ast.Argument) int { return strings.Compare(a.Name, b.Name) })
+
+	sb.WriteString("(")
+	for i, arg := range args {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(arg.Name + ":" + canonicalValue(arg.Value))
+	}
+	sb.WriteString(")")
+}
+
+// canonicalValue serializes a value with the fields of input objects sorted
+// by name, so equal values serialize the same regardless of field order.
+func canonicalValue(v *ast.Value) string {
+	if v == nil {
+		return ""
+	}
+
+	switch v.Kind {
+	case ast.ObjectValue:
+		children := slices.Clone(v.Children)
+		slices.SortFunc(children, func(a, b *ast.ChildValue) int { return strings.Compare(a.Name, b.Name) })
+
+		parts := make([]string, len(children))
+		for i, child := range children {
+			parts[i] = child.Name + ":" + canonicalValue(child.Value)
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	case ast.ListValue:
+		parts := make([]string, len(v.Children))
+		for i, child := range v.Children {
+			parts[i] = canonicalValue(child.Value)
+		}
+		return "[" + strings.Join(parts, ",") + "]"
+	default:
+		return v.String()
+	}
+}
+
+// flattener flattens the selection sets of a document, memoizing the
+// flattened selection set of each fragment so fragments spread many times,
+// by one operation or several, are only expanded once. Flattening a fragment
+// only depends on its type condition, not on where it is spread, so the
+// fragment's name is its key. Flattened fragments are shared between the
+// selection sets they are spread in, and are copied rather than modified when
+// fields are merged into them.
+//
+// Without a schema every fragment is merged into the selection set it is
+// spread in, or with keepTypeConditions kept apart on its type condition
+// unless it is spread in a fragment on the same type, as the types of the
+// selection sets are not known. With a schema fragments are matched with the
+// type of the selection set, see Config.TypeConditions.
+//
+// The @skip and @include directives of inlined fragments are added to the
+// selections of the fragment, so they are left out under the same conditions.
+//
+// Spreads of the omit fragment are left out, as if they were removed from the
+// document, see Config.FragmentDeltas.
+type flattener struct {
+	doc                *ast.QueryDocument
+	schema             *ast.Schema
+	fragments          map[string]ast.SelectionSet
+	omit               string
+	keepTypeConditions bool
+}
+
+func newFlattener(doc *ast.QueryDocument, schema *ast.Schema) *flattener {
+	return &flattener{doc: doc, schema: schema, fragments: make(map[string]ast.SelectionSet)}
+}
+
+// typeDefinition returns the named type when fragments are matched with
+// types, a definition holding only the name when they are kept on their type
+// conditions without a schema, and nil otherwise.
+func (f *flattener) typeDefinition(name string) *ast.Definition {
+	if f.schema == nil {
+		if f.keepTypeConditions && name != "" {
+			return &ast.Definition{Name: name}
+		}
+		return nil
+	}
+	return f.schema.Types[name]
+}
+
+// rootType returns the root type of the operation when fragments are matched
+// with types, and nil otherwise.
+func (f *flattener) rootType(op *ast.OperationDefinition) *ast.Definition {
+	if f.schema == nil {
+		return nil
+	}
+	return f.schema.Types[rootTypeName(f.schema, op)]
+}
+
+// selectionSet flattens the selection set on the parent type by inlining
+// fragments. Fields selected directly, in inline fragments and in fragment
+// spreads are merged alike, so the result does not depend on the order of the
+// selections. When fragments are matched with types, fragments narrowing an
+// abstract parent to another type are kept apart as one inline fragment per
+// type condition, after the fields, and fragments on types the parent can
+// never be are left out.
+func (f *flattener) selectionSet(parent *ast.Definition, selectionSet ast.SelectionSet) ast.SelectionSet {
+	var (
+		fieldMap       = make(map[string]*ast.Field)
+		keys           []string
+		conditions     = make(map[string]ast.SelectionSet)
+		conditionNames []string
+	)
+
+	// merge adds a flattened field, merging the selection sets of fields
+	// selected again under the same key into a copy of the first of them.
+	merge := func(field *ast.Field) {
+		key := fieldKey(field)
+		if existing, exists := fieldMap[key]; exists {
+			merged := *existing
+			merged.SelectionSet = f.selectionSet(f.fieldType(existing), slices.Concat(existing.SelectionSet, field.SelectionSet))
+			fieldMap[key] = &merged
+			return
+		}
+		fieldMap[key] = field
+		keys = append(keys, key)
+	}
+
+	// mergeAll merges the selections of a flattened fragment on the type
+	// condition.
+	var mergeAll func(typeCondition string, selections ast.SelectionSet)
+	mergeAll = func(typeCondition string, selections ast.SelectionSet) {
+		keep := func() {
+			if _, ok := conditions[typeCondition]; !ok {
+				conditionNames = append(conditionNames, typeCondition)
 			}
+			conditions[typeCondition] = slices.Concat(conditions[typeCondition], selections)
+		}
 
-			// If we've seen this field before, merge their selection sets
-			if existing, exists := fieldMap[key]; exists {
-				// Merge selection sets
-				mergedSelectionSet := make(ast.SelectionSet, 0)
-				mergedSelectionSet = append(mergedSelectionSet, existing.SelectionSet...)
-				mergedSelectionSet = append(mergedSelectionSet, sel.SelectionSet...)
+		switch {
+		case typeCondition == "" || parent != nil && typeCondition == parent.Name:
+			// The fragment applies to every object of the parent type.
+		case f.schema == nil && f.keepTypeConditions:
+			// The parent type is not known, so neither is whether the
+			// fragment applies to every object of it.
+			keep()
+			return
+		case f.schema == nil || parent == nil:
+			// The fragment is merged as if it applies to every object.
+		case !f.possibleType(parent, typeCondition):
+			// No object of the parent type is of the type condition.
+			return
+		case isAbstract(parent):
+			// Only the objects of the type condition select the fragment.
+			keep()
+			return
+		}
 
-				existing.SelectionSet = flattenSelectionSet(mergedSelectionSet, doc)
-				continue
+		for _, selection := range selections {
+			switch sel := selection.(type) {
+			case *ast.Field:
+				merge(sel)
+			case *ast.InlineFragment:
+				mergeAll(sel.TypeCondition, withConditions(sel.SelectionSet, sel.Directives))
 			}
+		}
+	}
 
-			// For fields, recursively flatten their selection sets
-			flattenedField := &ast.Field{
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			// Fields are copied so that merging does not modify the document.
+			merge(&ast.Field{
 				Alias:            sel.Alias,
 				Name:             sel.Name,
 				Arguments:        sel.Arguments,
 				Directives:       sel.Directives,
-				SelectionSet:     flattenSelectionSet(sel.SelectionSet, doc),
+				SelectionSet:     f.selectionSet(f.fieldType(sel), sel.SelectionSet),
 				Position:         sel.Position,
 				Comment:          sel.Comment,
 				Definition:       sel.Definition,
 				ObjectDefinition: sel.ObjectDefinition,
-			}
-			fieldMap[key] = flattenedField
+			})
 
 		case *ast.InlineFragment:
-			// For inline fragments, flatten their selection sets and merge them directly
-			fragmentSelections := flattenSelectionSet(sel.SelectionSet, doc)
-			for _, fragSel := range fragmentSelections {
-				if field, ok := fragSel.(*ast.Field); ok {
-					key := field.Name
-					if field.Alias != "" {
-						key = field.Alias + ":" + field.Name
-					}
-
-					if existing, exists := fieldMap[key]; exists {
-						// Merge selection sets
-						mergedSelectionSet := make(ast.SelectionSet, 0)
-						mergedSelectionSet = append(mergedSelectionSet, existing.SelectionSet...)
-						mergedSelectionSet = append(mergedSelectionSet, field.SelectionSet...)
-
-						existing.SelectionSet = flattenSelectionSet(mergedSelectionSet, doc)
-
-						continue
-					}
-
-					fieldMap[key] = field
-				}
+			typeParent := parent
+			if sel.TypeCondition != "" {
+				typeParent = f.typeDefinition(sel.TypeCondition)
 			}
+			mergeAll(sel.TypeCondition, withConditions(f.selectionSet(typeParent, sel.SelectionSet), sel.Directives))
 
 		case *ast.FragmentSpread:
-			// For fragment spreads, find the fragment definition and inline its selections
-			if fragDef := findFragmentDefinition(doc, sel.Name); fragDef != nil {
-				fragmentSelections := flattenSelectionSet(fragDef.SelectionSet, doc)
-				for _, fragSel := range fragmentSelections {
-					if field, ok := fragSel.(*ast.Field); ok {
-						key := field.Name
-						if field.Alias != "" {
-							key = field.Alias + ":" + field.Name
-						}
-
-						if existing, exists := fieldMap[key]; exists {
-							// Merge selection sets
-							mergedSelectionSet := make(ast.SelectionSet, 0)
-							mergedSelectionSet = append(mergedSelectionSet, existing.SelectionSet...)
-							mergedSelectionSet = append(mergedSelectionSet, field.SelectionSet...)
-
-							existing.SelectionSet = flattenSelectionSet(mergedSelectionSet, doc)
-							continue
-						}
-
-						fieldMap[key] = field
-					}
-				}
+			if sel.Name == f.omit {
+				continue
+			}
+			fragDef := sel.Definition
+			if fragDef == nil {
+				fragDef = findFragmentDefinition(f.doc, sel.Name)
+			}
+			if fragDef != nil {
+				mergeAll(fragDef.TypeCondition, withConditions(f.fragment(fragDef), sel.Directives))
 			}
 		}
 	}
 
-	// Convert map back to selection set
+	// Convert map back to selection set in the order fields were first selected
 	var flattened ast.SelectionSet
-	for _, field := range fieldMap {
-		flattened = append(flattened, field)
+	for _, key := range keys {
+		flattened = append(flattened, fieldMap[key])
+	}
+	for _, name := range conditionNames {
+		def := f.typeDefinition(name)
+		fragment := &ast.InlineFragment{
+			TypeCondition: name,
+			SelectionSet:  f.selectionSet(def, conditions[name]),
+		}
+		if f.schema != nil {
+			fragment.ObjectDefinition = def
+		}
+		flattened = append(flattened, fragment)
 	}
 
 	return flattened
 }
 
+// withConditions returns the selections of a fragment with the @skip and
+// @include directives of the fragment added to each of them. The selections
+// are copied rather than modified, as flattened fragments are shared.
+func withConditions(selections ast.SelectionSet, directives ast.DirectiveList) ast.SelectionSet {
+	var conditions ast.DirectiveList
+	for _, d := range directives {
+		if d.Name == "skip" || d.Name == "include" {
+			conditions = append(conditions, d)
+		}
+	}
+	if len(conditions) == 0 {
+		return selections
+	}
+
+	result := make(ast.SelectionSet, len(selections))
+	for i, selection := range selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			field := *sel
+			field.Directives = slices.Concat(sel.Directives, conditions)
+			result[i] = &field
+		case *ast.InlineFragment:
+			fragment := *sel
+			fragment.Directives = slices.Concat(sel.Directives, conditions)
+			result[i] = &fragment
+		default:
+			result[i] = selection
+		}
+	}
+	return result
+}
+
+// fragment returns the flattened selection set of the fragment.
+func (f *flattener) fragment(fragDef *ast.FragmentDefinition) ast.SelectionSet {
+	if flattened, ok := f.fragments[fragDef.Name]; ok {
+		return flattened
+	}
+
+	flattened := f.selectionSet(f.typeDefinition(fragDef.TypeCondition), fragDef.SelectionSet)
+	f.fragments[fragDef.Name] = flattened
+	return flattened
+}
+
+// fieldType returns the type of the field's selection set when fragments are
+// matched with types, and nil otherwise.
+func (f *flattener) fieldType(field *ast.Field) *ast.Definition {
+	if f.schema == nil || field.Definition == nil {
+		return nil
+	}
+	return f.schema.Types[field.Definition.Type.Name()]
+}
+
+// possibleType reports whether an object of the parent type can be of the
+// type named by the type condition: whether they share a concrete type.
+func (f *flattener) possibleType(parent *ast.Definition, typeCondition string) bool {
+	def := f.schema.Types[typeCondition]
+	if def == nil {
+		return false
+	}
+	for _, a := range f.schema.GetPossibleTypes(parent) {
+		for _, b := range f.schema.GetPossibleTypes(def) {
+			if a.Name == b.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // findFragmentDefinition finds a fragment definition by name in the document
 func findFragmentDefinition(doc *ast.QueryDocument, name string) *ast.FragmentDefinition {
 	for _, frag := range doc.Fragments {
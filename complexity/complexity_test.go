@@ -1,10 +1,17 @@
 package complexity_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/asger-noer/gql/complexity"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
 	"github.com/vektah/gqlparser/v2/parser"
@@ -40,6 +47,13 @@ const (
 )
 
 var (
+	// ignoreAST ignores the flattened operation, and the position of the
+	// operation, held by analysis results.
+	ignoreAST = cmp.Options{
+		cmpopts.IgnoreFields(complexity.DocumentAnalysis{}, "Flattened", "Line", "Column", "EndLine"),
+		cmpopts.IgnoreFields(complexity.ComplexityAnalysis{}, "Flattened", "Line", "Column", "EndLine"),
+	}
+
 	schemaSource = ast.Source{
 		Name:    "schema.graphql",
 		Input:   schema,
@@ -71,12 +85,859 @@ func TestAnalyseDocument(t *testing.T) {
 	expected := []complexity.DocumentAnalysis{
 		{
 			OperationName:       "GetOrder",
+			OperationType:       ast.Query,
 			Complexity:          5,
 			FlattenedComplexity: 3,
+			RootFields:          1,
+			Depth:               2,
+			Arguments:           1,
+			UniqueFields:        3,
+			ResponseNodes:       3,
 		},
 	}
 
-	if diff := cmp.Diff(expected, result); diff != "" {
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
 		t.Errorf("AnalyseDocument() mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestAnalyseString(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	result, err := complexity.AnalyseString(t.Context(), schemaDoc, fragmentedQuery)
+	if err != nil {
+		t.Fatalf("failed to analyse query: %v", err)
+	}
+
+	expected := []complexity.DocumentAnalysis{
+		{
+			OperationName:       "GetOrder",
+			OperationType:       ast.Query,
+			Complexity:          5,
+			FlattenedComplexity: 3,
+			RootFields:          1,
+			Depth:               2,
+			Arguments:           1,
+			UniqueFields:        3,
+			ResponseNodes:       3,
+		},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("AnalyseString() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAnalyseStringInvalid(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		invalidInput bool
+		line, column int
+	}{
+		{name: "syntax error", query: "query GetUser {\n\tuser(id: 1) { id\n}", invalidInput: true, line: 3, column: 2},
+		{name: "unknown field", query: "query GetUser {\n\tuser(id: 1) { email }\n}", line: 2, column: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := complexity.AnalyseString(t.Context(), schemaDoc, tt.query)
+			if err == nil {
+				t.Fatal("AnalyseString() error = nil, want an error")
+			}
+			if got := errors.Is(err, complexity.ErrInvalidInput); got != tt.invalidInput {
+				t.Errorf("AnalyseString() error is ErrInvalidInput = %v, want %v", got, tt.invalidInput)
+			}
+
+			diagnostics := complexity.Diagnostics("", err)
+			if len(diagnostics) == 0 {
+				t.Fatal("Diagnostics() returned no diagnostics")
+			}
+			if d := diagnostics[0]; d.Line != tt.line || d.Column != tt.column {
+				t.Errorf("AnalyseString() error at %d:%d, want %d:%d", d.Line, d.Column, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestAnalyseDocumentWithoutValidation(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, fragmentedQuery)
+	if gqlErr != nil {
+		t.Fatalf("failed to load query: %v", gqlErr)
+	}
+
+	validated, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc)
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	skipped, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithoutValidation())
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	if diff := cmp.Diff(validated, skipped, ignoreAST); diff != "" {
+		t.Errorf("AnalyseDocument() without validation mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunAnalysisFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls":           {Data: []byte(schema)},
+		"queries/order.graphql":     {Data: []byte(fragmentedQuery)},
+		"queries/generated.graphql": {Data: []byte(`query Generated { user(id: 1) { id } }`)},
+		complexity.IgnoreFile:       {Data: []byte("generated.graphql\n")},
+	}
+
+	result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "queries/*.graphql")
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{
+			Path:                "queries/order.graphql",
+			OperationName:       "GetOrder",
+			OperationType:       ast.Query,
+			Complexity:          5,
+			FlattenedComplexity: 3,
+			RootFields:          1,
+			Depth:               2,
+			Arguments:           1,
+			UniqueFields:        3,
+			ResponseNodes:       3,
+		},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysisFS() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStreamAnalysisFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"a.graphql":       {Data: []byte(`query A { user(id: 1) { id } }`)},
+		"b.graphql":       {Data: []byte(`query B { user(id: 1) { id } }`)},
+		"c.graphql":       {Data: []byte(`query C { user(id: 1) { id } }`)},
+	}
+
+	t.Run("emit error", func(t *testing.T) {
+		errStop := errors.New("stop")
+
+		var emitted []string
+		err := complexity.StreamAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", func(r complexity.ComplexityAnalysis) error {
+			emitted = append(emitted, r.OperationName)
+			if r.OperationName == "B" {
+				return errStop
+			}
+			return nil
+		})
+		if !errors.Is(err, errStop) {
+			t.Errorf("StreamAnalysisFS() error = %v, want %v", err, errStop)
+		}
+		if diff := cmp.Diff([]string{"A", "B"}, emitted); diff != "" {
+			t.Errorf("StreamAnalysisFS() emitted mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		var emitted []string
+		err := complexity.StreamAnalysisFS(ctx, fsys, "*.graphqls", "*.graphql", func(r complexity.ComplexityAnalysis) error {
+			emitted = append(emitted, r.OperationName)
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("StreamAnalysisFS() error = %v, want %v", err, context.Canceled)
+		}
+		if diff := cmp.Diff([]string{"A"}, emitted); diff != "" {
+			t.Errorf("StreamAnalysisFS() emitted mismatch (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("progress", func(t *testing.T) {
+		var progress [][2]int
+		handler := func(done, total int) { progress = append(progress, [2]int{done, total}) }
+
+		err := complexity.StreamAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", func(complexity.ComplexityAnalysis) error {
+			return nil
+		}, complexity.WithProgressHandler(handler))
+		if err != nil {
+			t.Fatalf("StreamAnalysisFS() error = %v", err)
+		}
+		if diff := cmp.Diff([][2]int{{1, 3}, {2, 3}, {3, 3}}, progress); diff != "" {
+			t.Errorf("StreamAnalysisFS() progress mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("stop when", func(t *testing.T) {
+		var checked []string
+		stop := func(r complexity.ComplexityAnalysis) bool {
+			checked = append(checked, r.OperationName)
+			return r.OperationName == "B"
+		}
+
+		result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithStopWhen(stop))
+		if err != nil {
+			t.Fatalf("RunAnalysisFS() error = %v", err)
+		}
+
+		var got []string
+		for _, r := range result {
+			got = append(got, r.OperationName)
+		}
+		if diff := cmp.Diff([]string{"A", "B"}, got); diff != "" {
+			t.Errorf("RunAnalysisFS() results mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"A", "B"}, checked); diff != "" {
+			t.Errorf("RunAnalysisFS() checked results mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func BenchmarkAnalyseDocument(b *testing.B) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		b.Fatalf("failed to load schema: %v", err)
+	}
+
+	var sb strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&sb, "query Op%d {\n", i)
+		for j := range 50 {
+			fmt.Fprintf(&sb, "\tu%d: user(id: %d) { id name }\n", j, j)
+		}
+		sb.WriteString("}\n")
+	}
+
+	// Loading the query validates it, as a server would at parse time.
+	queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, sb.String())
+	if gqlErr != nil {
+		b.Fatalf("failed to load query: %v", gqlErr)
+	}
+
+	b.Run("validate", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := complexity.AnalyseDocument(b.Context(), schemaDoc, queryDoc); err != nil {
+				b.Fatalf("failed to analyse document: %v", err)
+			}
+		}
+	})
+
+	b.Run("skip validation", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := complexity.AnalyseDocument(b.Context(), schemaDoc, queryDoc, complexity.WithoutValidation()); err != nil {
+				b.Fatalf("failed to analyse document: %v", err)
+			}
+		}
+	})
+}
+
+func TestAnalyseDocumentAnonymousOperations(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	// Documents with several operations are only valid when all are named, so
+	// the anonymous operations are analysed without validation.
+	queryDoc, err := parser.ParseQuery(&ast.Source{
+		Name: "anonymous.graphql",
+		Input: `query { user(id: 1) { id } }
+		query Named { user(id: 1) { id } }
+		{ user(id: 2) { id name } }`,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithoutValidation())
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	var names []string
+	for _, r := range result {
+		names = append(names, r.OperationName)
+	}
+
+	expected := []string{"<anonymous#0>", "Named", "<anonymous#2>"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("AnalyseDocument() operation names mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunAnalysisFSWithoutOperations(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+	}{
+		{name: "empty", document: ""},
+		{name: "whitespace only", document: "  \n\t\n"},
+		{name: "comment only", document: "# Nothing to see here\n# yet\n"},
+		{name: "fragment only", document: "fragment UserFragment on User { id name }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"schema.graphqls": {Data: []byte(schema)},
+				"doc.graphql":     {Data: []byte(tt.document)},
+			}
+
+			var diagnostics []complexity.Diagnostic
+			handler := func(d complexity.Diagnostic) { diagnostics = append(diagnostics, d) }
+
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithDiagnosticHandler(handler))
+			if err != nil {
+				t.Fatalf("RunAnalysisFS() error = %v", err)
+			}
+			if len(result) != 0 {
+				t.Errorf("RunAnalysisFS() = %v, want no results", result)
+			}
+			if len(diagnostics) != 0 {
+				t.Errorf("RunAnalysisFS() reported %v, want no diagnostics", diagnostics)
+			}
+		})
+	}
+}
+
+func TestRunAnalysisFSNoMatches(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"order.graphql":   {Data: []byte(fragmentedQuery)},
+	}
+
+	tests := []struct {
+		name   string
+		schema string
+		docs   string
+	}{
+		{name: "documents", schema: "*.graphqls", docs: "queries/*.graphql"},
+		{name: "schema", schema: "*.graphqls,schemas/*.graphqls", docs: "*.graphql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := complexity.RunAnalysisFS(t.Context(), fsys, tt.schema, tt.docs); err != nil {
+				t.Errorf("RunAnalysisFS() error = %v, want a warning only", err)
+			}
+
+			_, err := complexity.RunAnalysisFS(t.Context(), fsys, tt.schema, tt.docs, complexity.WithRequireMatches())
+			if !errors.Is(err, complexity.ErrNoMatches) || !errors.Is(err, complexity.ErrInvalidInput) {
+				t.Errorf("RunAnalysisFS() error = %v, want %v", err, complexity.ErrNoMatches)
+			}
+		})
+	}
+}
+
+func TestRunAnalysisFSDefaultDocuments(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls":    {Data: []byte(schema)},
+		"user.graphql":       {Data: []byte(`query GetUser { user(id: 1) { id } }`)},
+		"order.gql":          {Data: []byte(`query GetOrder { user(id: 1) { id name } }`)},
+		"nested/skipped.gql": {Data: []byte(`query Skipped { user(id: 1) { id } }`)},
+	}
+
+	tests := []struct {
+		name     string
+		docs     string
+		expected []string
+	}{
+		{name: "default", docs: complexity.DefaultDocuments, expected: []string{"GetUser", "GetOrder"}},
+		{name: "explicit", docs: "*.gql", expected: []string{"GetOrder"}},
+		{name: "overlapping", docs: "*.gql,order.gql", expected: []string{"GetOrder"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", tt.docs)
+			if err != nil {
+				t.Fatalf("RunAnalysisFS() error = %v", err)
+			}
+
+			var got []string
+			for _, r := range result {
+				got = append(got, r.OperationName)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("RunAnalysisFS() operations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunAnalysisFSChangedFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"user.graphql":    {Data: []byte(`query GetUser { user(id: 1) { id } }`)},
+		"name.graphql":    {Data: []byte(`query GetName { user(id: 1) { name } }`)},
+	}
+
+	tests := []struct {
+		name     string
+		changed  []string
+		expected []string
+	}{
+		{name: "all", changed: nil, expected: []string{"GetName", "GetUser"}},
+		{name: "changed document", changed: []string{"user.graphql", "README.md"}, expected: []string{"GetUser"}},
+		{name: "changed schema", changed: []string{"schema.graphqls"}, expected: []string{"GetName", "GetUser"}},
+		{name: "nothing changed", changed: []string{}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithChangedFiles(tt.changed))
+			if err != nil {
+				t.Fatalf("RunAnalysisFS() error = %v", err)
+			}
+
+			var got []string
+			for _, r := range result {
+				got = append(got, r.OperationName)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("RunAnalysisFS() operations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFlattenFlatOperation(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		reused bool
+	}{
+		{name: "flat", query: `query GetUsers { a: user(id: 1) { id name } b: user(id: 2) { id } }`, reused: true},
+		{name: "same field with other arguments", query: `query GetUsers { user(id: 1) { id } again: user(id: 2) { id } }`, reused: true},
+		{name: "repeated field", query: `query GetUser { user(id: 1) { id } user(id: 1) { name } }`},
+		{name: "inline fragment", query: `query GetUser { user(id: 1) { ... on User { id } } }`},
+		{name: "fragment spread", query: fragmentedQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, tt.query)
+			if gqlErr != nil {
+				t.Fatalf("failed to load query: %v", gqlErr)
+			}
+			op := queryDoc.Operations[0]
+
+			got := complexity.Flatten(queryDoc, op)
+			if reused := got == op; reused != tt.reused {
+				t.Errorf("Flatten() reused the operation = %v, want %v", reused, tt.reused)
+			}
+
+			want := complexity.FlattenOperation(queryDoc, op)
+			if diff := cmp.Diff(complexity.PrintOperation(want), complexity.PrintOperation(got)); diff != "" {
+				t.Errorf("Flatten() mismatch with the flattened copy (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFlattenInlinesFragments(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, `query GetUser {
+		...UserQuery
+		user(id: 1) { ... on User { id } }
+	}
+
+	fragment UserQuery on Query {
+		user(id: 1) { ...UserFields }
+	}
+
+	fragment UserFields on User {
+		... on User { name }
+	}`)
+	if gqlErr != nil {
+		t.Fatalf("failed to load query: %v", gqlErr)
+	}
+
+	flattened := complexity.Flatten(queryDoc, queryDoc.Operations[0])
+
+	var walk func(path string, selectionSet ast.SelectionSet)
+	walk = func(path string, selectionSet ast.SelectionSet) {
+		for _, selection := range selectionSet {
+			field, ok := selection.(*ast.Field)
+			if !ok {
+				t.Errorf("Flatten() left a %T in %q", selection, path)
+				continue
+			}
+			walk(path+"."+field.Alias, field.SelectionSet)
+		}
+	}
+	walk("GetUser", flattened.SelectionSet)
+
+	if diff := cmp.Diff([]string{"user", "user.id", "user.name"}, selectedPaths("", flattened.SelectionSet)); diff != "" {
+		t.Errorf("Flatten() selected paths mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFlattenSelectionOrder(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: `type Query {
+		user(id: ID!): User
+	}
+
+	type User {
+		id: ID!
+		name: String!
+		friends: [User!]!
+	}`})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	// Each selection selects user(id: 1) with overlapping fields.
+	selections := []string{
+		`user(id: 1) { friends { id } }`,
+		`... on Query { user(id: 1) { id friends { name } } }`,
+		`...UserFields`,
+		`user(id: 1) { name }`,
+	}
+	fragment := `fragment UserFields on Query { user(id: 1) { name friends { id name } } }`
+
+	expected := []string{"user", "user.friends", "user.friends.id", "user.friends.name", "user.id", "user.name"}
+
+	for _, order := range permutations(len(selections)) {
+		var parts []string
+		for _, i := range order {
+			parts = append(parts, selections[i])
+		}
+		query := "query GetUser { " + strings.Join(parts, " ") + " }\n" + fragment
+
+		t.Run(fmt.Sprint(order), func(t *testing.T) {
+			queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, query)
+			if gqlErr != nil {
+				t.Fatalf("failed to load query: %v", gqlErr)
+			}
+
+			flattened := complexity.Flatten(queryDoc, queryDoc.Operations[0])
+			if diff := cmp.Diff(expected, selectedPaths("", flattened.SelectionSet)); diff != "" {
+				t.Errorf("Flatten() selected paths mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFlattenConditionalSelections(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		selections []string
+	}{
+		{
+			name:       "skipped field",
+			selections: []string{`user(id: 1) @skip(if: true) { id }`, `user(id: 1) { name }`},
+		},
+		{
+			name:       "excluded field",
+			selections: []string{`user(id: 1) @include(if: false) { id }`, `user(id: 1) { name }`},
+		},
+		{
+			name:       "skipped fragment",
+			selections: []string{`... @skip(if: true) { user(id: 1) { id } }`, `user(id: 1) { name }`},
+		},
+	}
+
+	for _, tt := range tests {
+		var first *complexity.DocumentAnalysis
+		for _, order := range permutations(len(tt.selections)) {
+			var parts []string
+			for _, i := range order {
+				parts = append(parts, tt.selections[i])
+			}
+			query := "query S { " + strings.Join(parts, " ") + " }"
+
+			t.Run(fmt.Sprint(tt.name, order), func(t *testing.T) {
+				result, err := complexity.AnalyseString(t.Context(), schemaDoc, query)
+				if err != nil {
+					t.Fatalf("failed to analyse query: %v", err)
+				}
+				got := result[0]
+
+				// Only user(id: 1) { name } is executed.
+				if got.FlattenedComplexity != 2 {
+					t.Errorf("FlattenedComplexity = %d, want 2", got.FlattenedComplexity)
+				}
+				if got.Complexity != 2 {
+					t.Errorf("Complexity = %d, want 2", got.Complexity)
+				}
+				if got.ResponseNodes != 2 {
+					t.Errorf("ResponseNodes = %d, want 2", got.ResponseNodes)
+				}
+
+				// The measures of the flattened operation do not depend on
+				// the order of the selections.
+				if first == nil {
+					first = &got
+					return
+				}
+				if got.RootFields != first.RootFields || got.Depth != first.Depth {
+					t.Errorf("RootFields, Depth = %d, %d, want %d, %d as in the first order", got.RootFields, got.Depth, first.RootFields, first.Depth)
+				}
+			})
+		}
+	}
+}
+
+// selectedPaths returns the sorted paths of the fields in a flattened
+// selection set. Fields selected more than once appear more than once.
+func selectedPaths(prefix string, selectionSet ast.SelectionSet) []string {
+	var paths []string
+	for _, selection := range selectionSet {
+		field, ok := selection.(*ast.Field)
+		if !ok {
+			continue
+		}
+		path := prefix + field.Name
+		paths = append(paths, path)
+		paths = append(paths, selectedPaths(path+".", field.SelectionSet)...)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// permutations returns every order of the indexes below n.
+func permutations(n int) [][]int {
+	if n == 0 {
+		return [][]int{{}}
+	}
+
+	var result [][]int
+	for _, p := range permutations(n - 1) {
+		for i := 0; i <= len(p); i++ {
+			result = append(result, slices.Insert(slices.Clone(p), i, n-1))
+		}
+	}
+	return result
+}
+
+func BenchmarkFlatten(b *testing.B) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		b.Fatalf("failed to load schema: %v", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("query GetUsers {\n")
+	for i := range 1000 {
+		fmt.Fprintf(&sb, "\tu%d: user(id: %d) { id name }\n", i, i)
+	}
+	sb.WriteString("}\n")
+
+	queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, sb.String())
+	if gqlErr != nil {
+		b.Fatalf("failed to load query: %v", gqlErr)
+	}
+	op := queryDoc.Operations[0]
+
+	b.Run("copy", func(b *testing.B) {
+		for b.Loop() {
+			complexity.FlattenOperation(queryDoc, op)
+		}
+	})
+
+	b.Run("flat", func(b *testing.B) {
+		for b.Loop() {
+			complexity.Flatten(queryDoc, op)
+		}
+	})
+}
+
+func TestFlattenSharedFragment(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: uniqueFieldsSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	// The fragment is spread under different fields and has fields merged
+	// into its selections, which must not leak into the other spreads.
+	queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, `query GetUser {
+		user(id: 1) {
+			...Friends
+			friends { name }
+		}
+	}
+
+	query GetFriends {
+		user(id: 1) {
+			friends { ...Friends }
+			...Friends
+		}
+	}
+
+	fragment Friends on User {
+		friends { id }
+	}`)
+	if gqlErr != nil {
+		t.Fatalf("failed to load query: %v", gqlErr)
+	}
+
+	expected := [][]string{
+		{"user", "user.friends", "user.friends.id", "user.friends.name"},
+		{"user", "user.friends", "user.friends.friends", "user.friends.friends.id", "user.friends.id"},
+	}
+
+	for i, op := range complexity.FlattenDocument(queryDoc) {
+		if diff := cmp.Diff(expected[i], selectedPaths("", op.SelectionSet)); diff != "" {
+			t.Errorf("FlattenDocument() %s mismatch (-want +got):\n%s", op.Name, diff)
+		}
+	}
+}
+
+func BenchmarkFlattenSharedFragment(b *testing.B) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: uniqueFieldsSchema})
+	if err != nil {
+		b.Fatalf("failed to load schema: %v", err)
+	}
+
+	var sb strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&sb, "query Op%d { user(id: %d) { ...UserFields } }\n", i, i)
+	}
+	sb.WriteString("fragment UserFields on User { id name friends { ...FriendFields } }\n")
+	sb.WriteString("fragment FriendFields on User { id name friends { id name } }\n")
+
+	queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, sb.String())
+	if gqlErr != nil {
+		b.Fatalf("failed to load query: %v", gqlErr)
+	}
+
+	b.Run("per operation", func(b *testing.B) {
+		for b.Loop() {
+			for _, op := range queryDoc.Operations {
+				complexity.FlattenOperation(queryDoc, op)
+			}
+		}
+	})
+
+	b.Run("document", func(b *testing.B) {
+		for b.Loop() {
+			complexity.FlattenDocument(queryDoc)
+		}
+	})
+}
+
+func TestFlattenTypeConditions(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: `type Query {
+		node(id: ID!): Node
+		user(id: ID!): User
+	}
+
+	interface Node {
+		id: ID!
+	}
+
+	type User implements Node {
+		id: ID!
+		name: String!
+	}
+
+	type Product implements Node {
+		id: ID!
+		price: Int!
+	}`})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		merged int
+		typed  int
+	}{
+		{
+			name:   "fragments on implementations",
+			query:  `query GetNode { node(id: 1) { id ... on User { name } ... on Product { price } } }`,
+			merged: 4,
+			typed:  3,
+		},
+		{
+			name:   "fragment on a type the parent is not",
+			query:  `query GetUser { user(id: 1) { id ... on Product { price } } }`,
+			merged: 3,
+			typed:  2,
+		},
+		{
+			name: "fragment on the interface",
+			query: `query GetUser { user(id: 1) { ...NodeFields } }
+
+			fragment NodeFields on Node {
+				id
+				... on User { name }
+				... on Product { price }
+			}`,
+			merged: 4,
+			typed:  3,
+		},
+		{
+			name:   "fragment on the parent",
+			query:  `query GetNode { node(id: 1) { ... on Node { id } id } }`,
+			merged: 2,
+			typed:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Spreading fragments on types the parent is not does not pass
+			// validation.
+			queryDoc, err := parser.ParseQuery(&ast.Source{Input: tt.query})
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+			opts := []complexity.Option{complexity.WithoutRules("PossibleFragmentSpreads")}
+
+			merged, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, opts...)
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+			typed, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, append(opts, complexity.WithTypeConditions())...)
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+
+			if got := merged[0].FlattenedComplexity; got != tt.merged {
+				t.Errorf("FlattenedComplexity = %d, want %d", got, tt.merged)
+			}
+			if got := typed[0].FlattenedComplexity; got != tt.typed {
+				t.Errorf("FlattenedComplexity with type conditions = %d, want %d", got, tt.typed)
+			}
+			if merged[0].Depth != typed[0].Depth {
+				t.Errorf("Depth with type conditions = %d, want %d", typed[0].Depth, merged[0].Depth)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package complexity
+
+import (
+	"log/slog"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// included reports whether a selection with the directives is executed. It is
+// left out by @skip with a true "if" argument and by @include with a false
+// one. A condition on a variable without a value or a default cannot be
+// decided and includes the selection, so that complexity errs on the high
+// side rather than depending on a value that was never given, which is logged
+// at debug level. Flattened selections carry the conditions of the fragments
+// they were selected in as well as their own, so every @skip and @include must
+// hold.
+func included(directives ast.DirectiveList, vars Variables, log *slog.Logger) bool {
+	for _, d := range directives.ForNames("skip") {
+		if skip, ok := condition(d, vars, log); ok && skip {
+			return false
+		}
+	}
+	for _, d := range directives.ForNames("include") {
+		if include, ok := condition(d, vars, log); ok && !include {
+			return false
+		}
+	}
+	return true
+}
+
+// condition returns the value of the "if" argument of the directive, and
+// whether it is present and known.
+func condition(d *ast.Directive, vars Variables, log *slog.Logger) (bool, bool) {
+	if d == nil {
+		return false, false
+	}
+
+	arg := d.Arguments.ForName("if")
+	if arg == nil {
+		return false, false
+	}
+	b, ok := vars.Bool(arg.Value)
+	if !ok && arg.Value != nil && arg.Value.Kind == ast.Variable {
+		log.Debug("Including selection with a condition on a variable without a value", "directive", "@"+d.Name, "variable", "$"+arg.Value.Raw)
+	}
+	return b, ok
+}
+
+// selectionDirectives returns the directives of a field, fragment spread or
+// inline fragment.
+func selectionDirectives(selection ast.Selection) ast.DirectiveList {
+	switch s := selection.(type) {
+	case *ast.Field:
+		return s.Directives
+	case *ast.FragmentSpread:
+		return s.Directives
+	case *ast.InlineFragment:
+		return s.Directives
+	}
+	return nil
+}
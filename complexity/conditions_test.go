@@ -0,0 +1,92 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/vektah/gqlparser/v2"
+)
+
+func TestSkipInclude(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		vars     map[string]any
+		expected int
+	}{
+		{
+			name:     "no conditions",
+			query:    `query GetUser { user(id: 1) { id name } }`,
+			expected: 3,
+		},
+		{
+			name:     "skip literal",
+			query:    `query GetUser { user(id: 1) { id name @skip(if: true) } }`,
+			expected: 2,
+		},
+		{
+			name:     "include literal",
+			query:    `query GetUser { user(id: 1) { id name @include(if: false) } }`,
+			expected: 2,
+		},
+		{
+			name:     "include variable",
+			query:    `query GetUser($show: Boolean!) { user(id: 1) { id name @include(if: $show) } }`,
+			vars:     map[string]any{"show": false},
+			expected: 2,
+		},
+		{
+			name:     "skip variable default",
+			query:    `query GetUser($hide: Boolean = true) { user(id: 1) { id name @skip(if: $hide) } }`,
+			expected: 2,
+		},
+		{
+			name:     "variable without value is included",
+			query:    `query GetUser($show: Boolean!) { user(id: 1) { id name @include(if: $show) } }`,
+			expected: 3,
+		},
+		{
+			name:     "skipped fragment spread",
+			query:    `query GetUser { user(id: 1) { id ...Name @skip(if: true) } } fragment Name on User { name }`,
+			expected: 2,
+		},
+		{
+			name:     "skipped inline fragment",
+			query:    `query GetUser { user(id: 1) @include(if: true) { id ... @include(if: false) { name } } }`,
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, tt.query)
+			if gqlErr != nil {
+				t.Fatalf("failed to load query: %v", gqlErr)
+			}
+
+			for _, decay := range []float64{0, 1} {
+				result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithVariables(tt.vars), complexity.WithDepthDecay(decay), complexity.WithTypeBreakdown())
+				if err != nil {
+					t.Fatalf("failed to analyse document: %v", err)
+				}
+
+				if got := result[0].Complexity; got != tt.expected {
+					t.Errorf("Complexity with depth decay %v = %d, want %d", decay, got, tt.expected)
+				}
+
+				var breakdown int
+				for _, part := range result[0].Types {
+					breakdown += part.Complexity
+				}
+				if breakdown != tt.expected {
+					t.Errorf("TypeBreakdown() total = %d, want %d", breakdown, tt.expected)
+				}
+			}
+		})
+	}
+}
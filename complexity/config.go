@@ -0,0 +1,678 @@
+package complexity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/validator/rules"
+)
+
+// Config controls how schemas are loaded and operations are analysed. The zero
+// value analyses operations with the default rules.
+type Config struct {
+	// Federation declares the Apollo Federation directives and types used by
+	// subgraph schemas before loading them.
+	Federation bool
+
+	// SchemaTimeout limits each attempt to introspect a schema given as a URL.
+	// Zero only limits attempts by the context of the analysis.
+	SchemaTimeout time.Duration
+
+	// SchemaRetries is the number of times a schema given as a URL is fetched
+	// again after the endpoint could not be reached.
+	SchemaRetries int
+
+	// Registry, when set, is the schema registry the unnamed schema is
+	// fetched from in place of the plain schema globs.
+	Registry *Registry
+
+	// InlineSchema, when set, is the SDL of the unnamed schema in place of the
+	// plain schema globs.
+	InlineSchema string
+
+	// Stdin is read for the document or schema named "-" among the globs in
+	// place of os.Stdin, when set.
+	Stdin io.Reader
+
+	// RequireMatches fails the analysis when a document or schema glob
+	// matches no files, rather than warning about it.
+	RequireMatches bool
+
+	// NoIgnore analyses every matched file, even those excluded by the
+	// IgnoreFile in the working directory.
+	NoIgnore bool
+
+	// IgnorePatterns are gitignore style patterns of schema and document files
+	// to leave out in addition to those of the IgnoreFile. They are not
+	// applied when NoIgnore is set.
+	IgnorePatterns []string
+
+	// ChangedFiles limits the analysis to the documents among these files,
+	// such as the files changed by a pull request, when not nil. Every
+	// document is analysed when a schema file is among them.
+	ChangedFiles []string
+
+	// FieldWeights sets the cost of fields, keyed by "Type.field", in place
+	// of the default cost of 1. The cost of the field's selection set is
+	// added to its weight.
+	FieldWeights map[string]int
+
+	// IgnoreFields lists fields, as "Type.field", that cost nothing beyond
+	// their selection set whatever their weight, such as fields batched by a
+	// DataLoader. Unlike the FreeDirective, they are not marked in the schema.
+	IgnoreFields []string
+
+	// ArgumentCosts add to the weight of fields depending on the values of
+	// their arguments, such as the format of an export.
+	ArgumentCosts []ArgumentCost
+
+	// BaseComplexity is the cost of fields without a weight of their own in
+	// place of 1, when set.
+	BaseComplexity *int
+
+	// ScalarComplexity is the cost of scalar and enum fields without a weight
+	// of their own in place of BaseComplexity, when set.
+	ScalarComplexity *int
+
+	// CountTypename makes __typename cost like other scalar fields rather
+	// than nothing, as it is resolved without calling a resolver.
+	CountTypename bool
+
+	// FreeDirective names the directive marking field definitions that cost
+	// nothing beyond their selection set, in place of DefaultFreeDirective.
+	FreeDirective string
+
+	// ComplexityFunc, when set, computes the complexity of each field in
+	// place of the weights above, as gqlgen's ComplexityRoot does. Fields it
+	// returns false for are costed as usual. It is not used with DepthDecay
+	// or by TypeBreakdown.
+	ComplexityFunc ComplexityFunc
+
+	// WorstCase assumes objects of an interface or union type are of their
+	// most expensive concrete type, paying for the fragments on that type and
+	// on every interface or union including it. Otherwise the most expensive
+	// single type condition is counted.
+	WorstCase bool
+
+	// TypeConditions matches fragments with the type of the selection set
+	// they are spread in when flattening operations, rather than merging
+	// every fragment. Fragments narrowing an interface or union to another
+	// type cost as they do in the complexity, the most expensive type
+	// condition, and fragments on types the selection set can never be are
+	// left out of the flattened complexity.
+	TypeConditions bool
+
+	// ListMultiplierArgs names the arguments, such as "first" or "last", whose
+	// value multiplies the complexity of a field's selection set.
+	ListMultiplierArgs []string
+
+	// ListSizeArgs names the argument giving the list size of fields, keyed
+	// by "Type.field", in place of their @listSizeArg directive. It is
+	// consulted before the ListMultiplierArgs.
+	ListSizeArgs map[string]string
+
+	// DepthDecay switches to a cost model where each field costs
+	// 1/DepthDecay^depth instead of 1, with root fields at depth 0, and the
+	// total is rounded up. Zero uses gqlgen's model, and other values should
+	// be at least 1 for deeper fields to be cheaper.
+	DepthDecay float64
+
+	// Variables holds values for operation variables. Variables without a
+	// value use the default declared by the operation.
+	Variables map[string]any
+
+	// Manifest reads every document as a persisted query manifest. Documents
+	// with a .json extension are always read as manifests.
+	Manifest bool
+
+	// Batch reads every document as a batched request, a JSON array of
+	// requests with a query and optionally an operationName and variables.
+	// Each request is analysed with its own variables, which replace
+	// Variables, and a result named BatchTotalOperationName follows the
+	// requests of each batch with their total complexity.
+	Batch bool
+
+	// Mixed tolerates executable definitions, such as example operations, in
+	// schema files. They are left out of the schema and analysed along with
+	// the documents.
+	Mixed bool
+
+	// Profile sets the Timing of every result to the time spent parsing,
+	// validating and analysing it. The clock is not read without it.
+	Profile bool
+
+	// SkipValidation skips validating documents against the schema. Only skip
+	// validation for documents that were already validated, as validation
+	// annotates the document with the schema definitions the analysis uses.
+	SkipValidation bool
+
+	// Rules validates documents instead of the default rules of
+	// rules.NewDefaultRules.
+	Rules *rules.Rules
+
+	// DisabledRules names the validation rules, such as "NoUnusedFragments",
+	// that documents are not validated with. NoFragmentCycles and
+	// KnownFragmentNames cannot be disabled, as the analysis relies on them.
+	DisabledRules []string
+
+	// StrictVariables reports the variables each operation declares without
+	// using, or uses without declaring, rather than failing validation of the
+	// document, see CheckVariables.
+	StrictVariables bool
+
+	// IncludeSource sets the Source of every result to the text of the
+	// operation and the fragments it uses.
+	IncludeSource bool
+
+	// ByFragment sets the Fragments of every result to the standalone
+	// complexity of each fragment the operation uses, see FragmentComplexity.
+	ByFragment bool
+
+	// FragmentDeltas sets the FragmentDeltas of every result to how much the
+	// spreads of each fragment it uses add to its flattened complexity.
+	FragmentDeltas bool
+
+	// ByType sets the Types of every result to the complexity spent on the
+	// fields of each type, see TypeBreakdown.
+	ByType bool
+
+	// Roots sets the Roots of every result to the top level fields of the
+	// operation and the types they return, see OperationRootFields.
+	Roots bool
+
+	// Explain sets the Explain of every result to the fields of the operation
+	// with their complexity and description, see Explain.
+	Explain bool
+
+	// Coverage sets the Coverage of every result to the share of the fields
+	// of each type the operation selects, see OperationFieldCoverage.
+	Coverage bool
+
+	// DiagnosticHandler is called with the diagnostics of every document that
+	// could not be analysed.
+	DiagnosticHandler func(Diagnostic)
+
+	// Logger receives the warnings about skipped documents and globs
+	// matching no files, informational notes and debug messages. Nothing is
+	// logged when it is nil.
+	Logger *slog.Logger
+
+	// StopWhen, when set, is called with every result after it is emitted
+	// and stops the analysis, without an error, once it returns true. The
+	// remaining operations and documents are not analysed.
+	StopWhen func(ComplexityAnalysis) bool
+
+	// ProgressHandler is called after each document file is processed with
+	// the number of files done and the total number of files.
+	ProgressHandler func(done, total int)
+}
+
+// ComplexityFunc computes the complexity of a field of the named type from the
+// complexity of its selection set and its arguments. It returns false to leave
+// the field to the default cost of its weight plus its child complexity.
+type ComplexityFunc func(ctx context.Context, typeName, fieldName string, childComplexity int, args map[string]any) (int, bool)
+
+// Option modifies the Config of an analysis.
+type Option func(*Config)
+
+// WithConfig replaces the configuration of the analysis with cfg.
+func WithConfig(cfg Config) Option {
+	return func(c *Config) {
+		*c = cfg
+	}
+}
+
+// WithFederation declares the Apollo Federation directives and types before
+// loading the schema, so that subgraph schemas load without edits.
+func WithFederation() Option {
+	return func(c *Config) {
+		c.Federation = true
+	}
+}
+
+// WithSchemaFetch limits each attempt to introspect a schema given as a URL to
+// timeout and retries unreachable endpoints up to retries times.
+func WithSchemaFetch(timeout time.Duration, retries int) Option {
+	return func(c *Config) {
+		c.SchemaTimeout = timeout
+		c.SchemaRetries = retries
+	}
+}
+
+// WithSchemaRegistry fetches the unnamed schema from the schema registry in
+// place of the plain schema globs.
+func WithSchemaRegistry(reg Registry) Option {
+	return func(c *Config) {
+		c.Registry = &reg
+	}
+}
+
+// WithInlineSchema loads the unnamed schema from the SDL in place of the plain
+// schema globs.
+func WithInlineSchema(sdl string) Option {
+	return func(c *Config) {
+		c.InlineSchema = sdl
+	}
+}
+
+// WithStdin reads the document or schema named "-" among the globs from r in
+// place of os.Stdin.
+func WithStdin(r io.Reader) Option {
+	return func(c *Config) {
+		c.Stdin = r
+	}
+}
+
+// WithRequireMatches fails the analysis when a document or schema glob
+// matches no files.
+func WithRequireMatches() Option {
+	return func(c *Config) {
+		c.RequireMatches = true
+	}
+}
+
+// WithoutIgnoreFile analyses every matched file, even those excluded by the
+// IgnoreFile in the working directory.
+func WithoutIgnoreFile() Option {
+	return func(c *Config) {
+		c.NoIgnore = true
+	}
+}
+
+// WithIgnorePatterns leaves out schema and document files matching the
+// gitignore style patterns in addition to those of the IgnoreFile.
+func WithIgnorePatterns(patterns ...string) Option {
+	return func(c *Config) {
+		c.IgnorePatterns = append(c.IgnorePatterns, patterns...)
+	}
+}
+
+// WithChangedFiles only analyses the documents among the changed files,
+// unless a schema file changed.
+func WithChangedFiles(files []string) Option {
+	return func(c *Config) {
+		c.ChangedFiles = files
+	}
+}
+
+// WithFieldWeights sets the cost of fields, keyed by "Type.field", in place
+// of the default cost of 1.
+func WithFieldWeights(weights map[string]int) Option {
+	return func(c *Config) {
+		c.FieldWeights = weights
+	}
+}
+
+// WithIgnoreFields makes the fields, as "Type.field", cost nothing beyond
+// their selection set.
+func WithIgnoreFields(fields ...string) Option {
+	return func(c *Config) {
+		c.IgnoreFields = fields
+	}
+}
+
+// WithArgumentCosts adds to the weight of fields depending on the values of
+// their arguments, see ArgumentCost.
+func WithArgumentCosts(costs ...ArgumentCost) Option {
+	return func(c *Config) {
+		c.ArgumentCosts = costs
+	}
+}
+
+// WithBaseComplexity sets the cost of fields without a weight of their own in
+// place of 1.
+func WithBaseComplexity(n int) Option {
+	return func(c *Config) {
+		c.BaseComplexity = &n
+	}
+}
+
+// WithScalarComplexity sets the cost of scalar and enum fields without a
+// weight of their own, such as 0 to only count object fields.
+func WithScalarComplexity(n int) Option {
+	return func(c *Config) {
+		c.ScalarComplexity = &n
+	}
+}
+
+// WithTypename makes __typename cost like other scalar fields rather than
+// nothing.
+func WithTypename() Option {
+	return func(c *Config) {
+		c.CountTypename = true
+	}
+}
+
+// WithFreeDirective names the directive marking fields that cost nothing in
+// place of DefaultFreeDirective.
+func WithFreeDirective(name string) Option {
+	return func(c *Config) {
+		c.FreeDirective = name
+	}
+}
+
+// WithComplexityFunc computes the complexity of fields with fn in place of
+// their weights, see Config.ComplexityFunc.
+func WithComplexityFunc(fn ComplexityFunc) Option {
+	return func(c *Config) {
+		c.ComplexityFunc = fn
+	}
+}
+
+// WithWorstCase assumes objects of an interface or union type are of their
+// most expensive concrete type, see Config.WorstCase.
+func WithWorstCase() Option {
+	return func(c *Config) {
+		c.WorstCase = true
+	}
+}
+
+// WithTypeConditions matches fragments with the type of the selection set
+// they are spread in when flattening operations, see Config.TypeConditions.
+func WithTypeConditions() Option {
+	return func(c *Config) {
+		c.TypeConditions = true
+	}
+}
+
+// WithListMultiplierArgs multiplies the complexity of a field's selection set
+// by the value of the first of the named arguments given to it.
+func WithListMultiplierArgs(names ...string) Option {
+	return func(c *Config) {
+		c.ListMultiplierArgs = names
+	}
+}
+
+// WithListSizeArgs names the argument giving the list size of fields, keyed
+// by "Type.field", see Config.ListSizeArgs.
+func WithListSizeArgs(args map[string]string) Option {
+	return func(c *Config) {
+		c.ListSizeArgs = args
+	}
+}
+
+// WithDepthDecay makes each field cost 1/decay^depth instead of 1, so that
+// deeper fields are cheaper.
+func WithDepthDecay(decay float64) Option {
+	return func(c *Config) {
+		c.DepthDecay = decay
+	}
+}
+
+// WithVariables sets the values of operation variables.
+func WithVariables(vars map[string]any) Option {
+	return func(c *Config) {
+		c.Variables = vars
+	}
+}
+
+// WithManifest reads every document as a persisted query manifest.
+func WithManifest() Option {
+	return func(c *Config) {
+		c.Manifest = true
+	}
+}
+
+// WithBatch reads every document as a batched request, see Config.Batch.
+func WithBatch() Option {
+	return func(c *Config) {
+		c.Batch = true
+	}
+}
+
+// WithMixed analyses the operations in schema files rather than failing to
+// load the schema, see Config.Mixed.
+func WithMixed() Option {
+	return func(c *Config) {
+		c.Mixed = true
+	}
+}
+
+// WithProfile records the time spent on each operation, see Config.Profile.
+func WithProfile() Option {
+	return func(c *Config) {
+		c.Profile = true
+	}
+}
+
+// WithoutValidation skips validating documents that the caller has already
+// validated against the schema.
+func WithoutValidation() Option {
+	return func(c *Config) {
+		c.SkipValidation = true
+	}
+}
+
+// WithRules validates documents with r instead of the default rules.
+func WithRules(r *rules.Rules) Option {
+	return func(c *Config) {
+		c.Rules = r
+	}
+}
+
+// WithoutRules disables the named validation rules.
+func WithoutRules(names ...string) Option {
+	return func(c *Config) {
+		c.DisabledRules = append(c.DisabledRules, names...)
+	}
+}
+
+// WithStrictVariables reports unused and undefined variables of each
+// operation in its result rather than failing validation.
+func WithStrictVariables() Option {
+	return func(c *Config) {
+		c.StrictVariables = true
+	}
+}
+
+// WithSource includes the text of every operation, and the fragments it
+// uses, in its result.
+func WithSource() Option {
+	return func(c *Config) {
+		c.IncludeSource = true
+	}
+}
+
+// WithFragmentComplexity reports the complexity of the fragments each
+// operation uses.
+func WithFragmentComplexity() Option {
+	return func(c *Config) {
+		c.ByFragment = true
+	}
+}
+
+// WithFragmentDeltas reports how much the spreads of each fragment add to the
+// flattened complexity of the operations using it, see FragmentDelta.
+func WithFragmentDeltas() Option {
+	return func(c *Config) {
+		c.FragmentDeltas = true
+	}
+}
+
+// WithTypeBreakdown reports the complexity each operation spends on the
+// fields of each type.
+func WithTypeBreakdown() Option {
+	return func(c *Config) {
+		c.ByType = true
+	}
+}
+
+// WithRootFields reports the top level fields of each operation and the types
+// they return.
+func WithRootFields() Option {
+	return func(c *Config) {
+		c.Roots = true
+	}
+}
+
+// WithExplain reports the fields of each operation as a tree with the
+// complexity and description of each field.
+func WithExplain() Option {
+	return func(c *Config) {
+		c.Explain = true
+	}
+}
+
+// WithFieldCoverage reports the share of the fields of each type every
+// operation selects.
+func WithFieldCoverage() Option {
+	return func(c *Config) {
+		c.Coverage = true
+	}
+}
+
+// WithDiagnosticHandler calls handler with the diagnostics of every document
+// that could not be analysed.
+func WithDiagnosticHandler(handler func(Diagnostic)) Option {
+	return func(c *Config) {
+		c.DiagnosticHandler = handler
+	}
+}
+
+// WithLogger logs the warnings, notes and debug messages of the analysis to
+// logger rather than discarding them.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Config) {
+		c.Logger = logger
+	}
+}
+
+// WithProgressHandler calls handler after each document file is processed
+// with the number of files done and the total number of files.
+func WithProgressHandler(handler func(done, total int)) Option {
+	return func(c *Config) {
+		c.ProgressHandler = handler
+	}
+}
+
+// WithStopWhen stops the analysis after the first result stop returns true
+// for, such as the first operation exceeding a threshold.
+func WithStopWhen(stop func(ComplexityAnalysis) bool) Option {
+	return func(c *Config) {
+		c.StopWhen = stop
+	}
+}
+
+// newConfig builds the configuration from the options.
+func newConfig(opts []Option) Config {
+	var cfg Config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// requiredRules are the validation rules that cannot be disabled, as fragments
+// are inlined while walking operations: a fragment spreading itself would be
+// walked forever, and the fields of an unknown fragment would be left out.
+var requiredRules = []string{rules.NoFragmentCyclesRule.Name, rules.KnownFragmentNamesRule.Name}
+
+// validationRules returns the rules to validate documents with. The rules of
+// the configuration are copied rather than modified when rules are disabled.
+func (c Config) validationRules() (*rules.Rules, error) {
+	if len(c.DisabledRules) == 0 && !c.StrictVariables {
+		if c.Rules == nil {
+			return rules.NewDefaultRules(), nil
+		}
+		return c.Rules, nil
+	}
+
+	all := rules.NewDefaultRules().GetInner()
+	if c.Rules != nil {
+		all = c.Rules.GetInner()
+	}
+
+	known := make(map[string]bool, len(all))
+	for name := range all {
+		known[name] = true
+	}
+
+	for _, name := range c.DisabledRules {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown validation rule %q", ErrInvalidInput, name)
+		}
+		if slices.Contains(requiredRules, name) {
+			return nil, fmt.Errorf("%w: validation rule %q cannot be disabled", ErrInvalidInput, name)
+		}
+		delete(all, name)
+	}
+
+	// Strict variables are reported as violations rather than failing
+	// validation, which would leave the document out of the analysis.
+	if c.StrictVariables {
+		delete(all, rules.NoUnusedVariablesRule.Name)
+		delete(all, rules.NoUndefinedVariablesRule.Name)
+	}
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	r := rules.NewRules()
+	for _, name := range names {
+		r.AddRule(name, all[name])
+	}
+	return r, nil
+}
+
+// freeDirective returns the name of the directive marking fields that cost
+// nothing.
+func (c Config) freeDirective() string {
+	if c.FreeDirective == "" {
+		return DefaultFreeDirective
+	}
+	return c.FreeDirective
+}
+
+// discardLogger is the logger used when Config.Logger is nil.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// logger returns the Config.Logger, or a logger discarding every message.
+func (c Config) logger() *slog.Logger {
+	if c.Logger == nil {
+		return discardLogger
+	}
+	return c.Logger
+}
+
+// noMatches handles a document or schema glob, named by kind, matching no
+// files. The analysis fails when matches are required and is warned about
+// otherwise.
+func (c Config) noMatches(kind, pattern string) error {
+	if c.RequireMatches {
+		return fmt.Errorf("%w: %w by %s glob %q", ErrInvalidInput, ErrNoMatches, kind, pattern)
+	}
+	c.logger().Warn("No files matched by "+kind+" glob", "pattern", pattern)
+	return nil
+}
+
+// stdin returns the reader of the document or schema named StdinDocument.
+func (c Config) stdin() io.Reader {
+	if c.Stdin != nil {
+		return c.Stdin
+	}
+	return os.Stdin
+}
+
+// report logs why the file at path was skipped and passes its diagnostics to
+// the diagnostic handler.
+func (c Config) report(msg, path string, err error) {
+	for _, d := range Diagnostics(path, err) {
+		attrs := []any{"file", d.Path, "line", d.Line, "column", d.Column, "error", d.Message}
+		if d.Field != "" {
+			attrs = append(attrs, "field", d.Field, "type", d.Type, "suggestion", d.Suggestion)
+		}
+		c.logger().Warn(msg, attrs...)
+
+		if c.DiagnosticHandler != nil {
+			c.DiagnosticHandler(d)
+		}
+	}
+}
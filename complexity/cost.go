@@ -0,0 +1,195 @@
+package complexity
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// directiveDefinition is the SDL declaring a directive, keyed by the name it
+// declares.
+type directiveDefinition struct {
+	name string
+	sdl  string
+}
+
+// costDefinitions are the directives of the GraphQL cost specification.
+var costDefinitions = []directiveDefinition{
+	{"@cost", `directive @cost(weight: Int!) on ARGUMENT_DEFINITION | ENUM | FIELD_DEFINITION | INPUT_FIELD_DEFINITION | OBJECT | SCALAR`},
+	{"@listSize", `directive @listSize(assumedSize: Int, slicingArguments: [String!], sizedFields: [String!], requireOneSlicingArgument: Boolean = true) on FIELD_DEFINITION`},
+	{"@listSizeArg", `directive @listSizeArg(name: String!) on FIELD_DEFINITION`},
+}
+
+// DefaultFreeDirective is the name of the directive marking fields that cost
+// nothing, see Config.FreeDirective.
+const DefaultFreeDirective = "free"
+
+// directiveSource returns a source declaring the cost directives, the free
+// directive and the incremental delivery directives missing from gqlparser's
+// prelude that the schema sources do not declare, or nil when they declare
+// them all.
+func directiveSource(inputs []*ast.Source, cfg Config) (*ast.Source, error) {
+	doc, err := parser.ParseSchemas(inputs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema: %w: %w", ErrInvalidInput, err)
+	}
+
+	declared := make(map[string]bool)
+	for _, d := range doc.Directives {
+		declared["@"+d.Name] = true
+	}
+
+	var sb strings.Builder
+	free := directiveDefinition{"@" + cfg.freeDirective(), "directive @" + cfg.freeDirective() + " on FIELD_DEFINITION"}
+	for _, def := range slices.Concat(costDefinitions, []directiveDefinition{free}, incrementalDefinitions) {
+		if !declared[def.name] {
+			sb.WriteString(def.sdl + "\n")
+		}
+	}
+
+	if sb.Len() == 0 {
+		return nil, nil
+	}
+	return &ast.Source{Name: "directives.graphqls", Input: sb.String(), BuiltIn: false}, nil
+}
+
+// typenameDefinition is the definition of the __typename meta field, which
+// every object, interface and union has without declaring it.
+var typenameDefinition = &ast.FieldDefinition{Name: "__typename", Type: ast.NonNullNamedType("String", nil)}
+
+// fieldDefinition returns the definition of the field of the named type, or
+// nil when the schema has none.
+func fieldDefinition(schema *ast.Schema, typeName, fieldName string) *ast.FieldDefinition {
+	if fieldName == typenameDefinition.Name {
+		return typenameDefinition
+	}
+	def := schema.Types[typeName]
+	if def == nil {
+		return nil
+	}
+	return def.Fields.ForName(fieldName)
+}
+
+// fieldWeight returns the cost of a field of the named type given its
+// arguments, excluding its selection set. Fields among Config.IgnoreFields
+// cost nothing. Otherwise the Config.ArgumentCosts matching the arguments are
+// added to the weight of the field's definition, see definitionWeight.
+func fieldWeight(schema *ast.Schema, def *ast.FieldDefinition, typeName string, args map[string]any, cfg Config) int {
+	if def == nil {
+		return definitionWeight(schema, def, typeName, cfg)
+	}
+	field := typeName + "." + def.Name
+	if slices.Contains(cfg.IgnoreFields, field) {
+		return 0
+	}
+	return safeAdd(definitionWeight(schema, def, typeName, cfg), argumentCost(cfg.ArgumentCosts, field, args))
+}
+
+// definitionWeight returns the cost of a field of the named type whatever its
+// arguments. Weights configured with Config.FieldWeights take precedence over
+// the free directive of the field, which makes it cost nothing, and then its
+// @cost directive. __typename costs nothing unless Config.CountTypename is
+// set. Other fields with none of them cost the Config.ScalarComplexity when
+// they are scalar or enum leaves and it is set, and the
+// Config.BaseComplexity, 1 by default, otherwise.
+func definitionWeight(schema *ast.Schema, def *ast.FieldDefinition, typeName string, cfg Config) int {
+	base := 1
+	if cfg.BaseComplexity != nil {
+		base = *cfg.BaseComplexity
+	}
+	if def == nil {
+		return base
+	}
+	if weight, ok := cfg.FieldWeights[typeName+"."+def.Name]; ok {
+		return weight
+	}
+	if def.Directives.ForName(cfg.freeDirective()) != nil {
+		return 0
+	}
+	if def.Name == typenameDefinition.Name && !cfg.CountTypename {
+		return 0
+	}
+	if weight, ok := directiveInt(def.Directives.ForName("cost"), "weight"); ok && weight >= 0 {
+		return weight
+	}
+	if cfg.ScalarComplexity != nil && isLeaf(schema.Types[def.Type.Name()]) {
+		return *cfg.ScalarComplexity
+	}
+	return base
+}
+
+// isLeaf reports whether the definition is a scalar or an enum, which have no
+// selection set.
+func isLeaf(def *ast.Definition) bool {
+	return def != nil && (def.Kind == ast.Scalar || def.Kind == ast.Enum)
+}
+
+// fieldMultiplier returns the number of times the selection set of a field of
+// the named type is counted. The argument named by Config.ListSizeArgs or the
+// field's @listSizeArg directive, the slicing arguments of its @listSize
+// directive and the Config.ListMultiplierArgs give the size from the
+// arguments, in that order, falling back to the assumed size of @listSize and
+// then to 1.
+func fieldMultiplier(def *ast.FieldDefinition, typeName string, args map[string]any, cfg Config) int {
+	var listSize *ast.Directive
+	if def != nil {
+		listSize = def.Directives.ForName("listSize")
+	}
+
+	names := cfg.ListMultiplierArgs
+	if listSize != nil {
+		if arg := listSize.Arguments.ForName("slicingArguments"); arg != nil && arg.Value != nil {
+			var slicing []string
+			for _, child := range arg.Value.Children {
+				slicing = append(slicing, child.Value.Raw)
+			}
+			names = append(slicing, names...)
+		}
+	}
+	if def != nil {
+		if name, ok := cfg.ListSizeArgs[typeName+"."+def.Name]; ok {
+			names = append([]string{name}, names...)
+		} else if name, ok := directiveString(def.Directives.ForName("listSizeArg"), "name"); ok {
+			names = append([]string{name}, names...)
+		}
+	}
+
+	if n := listMultiplier(args, names); n > 1 {
+		return n
+	}
+	if size, ok := directiveInt(listSize, "assumedSize"); ok && size > 0 {
+		return size
+	}
+	return 1
+}
+
+// directiveInt returns the integer literal of the named argument of the
+// directive.
+func directiveInt(d *ast.Directive, name string) (int, bool) {
+	if d == nil {
+		return 0, false
+	}
+	arg := d.Arguments.ForName(name)
+	if arg == nil || arg.Value == nil || arg.Value.Kind != ast.IntValue {
+		return 0, false
+	}
+	n, err := strconv.Atoi(arg.Value.Raw)
+	return n, err == nil
+}
+
+// directiveString returns the string literal of the named argument of the
+// directive.
+func directiveString(d *ast.Directive, name string) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	arg := d.Arguments.ForName(name)
+	if arg == nil || arg.Value == nil || arg.Value.Kind != ast.StringValue {
+		return "", false
+	}
+	return arg.Value.Raw, true
+}
@@ -0,0 +1,375 @@
+package complexity_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+)
+
+// costSchema uses the cost directives without declaring them.
+const costSchema = `type Query {
+	search(term: String!): [Result!]! @cost(weight: 5)
+	users(first: Int): [User!]! @listSize(assumedSize: 10)
+	posts(limit: Int): [Post!]! @listSize(assumedSize: 10, slicingArguments: ["limit"])
+	members(pageSize: Int, first: Int): [User!]! @listSizeArg(name: "pageSize")
+	feed(take: Int, first: Int): [Post!]!
+}
+
+type Result {
+	id: ID!
+}
+
+type User {
+	id: ID!
+	name: String!
+}
+
+type Post {
+	id: ID!
+}
+`
+
+func TestRunAnalysisCostDirectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		opts     []complexity.Option
+		expected int
+	}{
+		{
+			name:     "cost weight",
+			query:    `query { search(term: "a") { id } }`,
+			expected: 6,
+		},
+		{
+			name:     "assumed list size",
+			query:    `query { users { id name } }`,
+			expected: 21,
+		},
+		{
+			name:     "list multiplier argument over assumed size",
+			query:    `query { users(first: 3) { id name } }`,
+			opts:     []complexity.Option{complexity.WithListMultiplierArgs("first")},
+			expected: 7,
+		},
+		{
+			name:     "slicing argument",
+			query:    `query { posts(limit: 2) { id } }`,
+			expected: 3,
+		},
+		{
+			name:     "list size argument",
+			query:    `query { members(pageSize: 4, first: 3) { id } }`,
+			opts:     []complexity.Option{complexity.WithListMultiplierArgs("first")},
+			expected: 5,
+		},
+		{
+			name:     "list multiplier argument without list size argument",
+			query:    `query { members(first: 3) { id } }`,
+			opts:     []complexity.Option{complexity.WithListMultiplierArgs("first")},
+			expected: 4,
+		},
+		{
+			name:  "configured list size argument",
+			query: `query { feed(take: 6, first: 3) { id } }`,
+			opts: []complexity.Option{
+				complexity.WithListMultiplierArgs("first"),
+				complexity.WithListSizeArgs(map[string]string{"Query.feed": "take"}),
+			},
+			expected: 7,
+		},
+		{
+			name:     "configured list size argument over directive",
+			query:    `query { members(pageSize: 4, first: 3) { id } }`,
+			opts:     []complexity.Option{complexity.WithListSizeArgs(map[string]string{"Query.members": "first"})},
+			expected: 4,
+		},
+		{
+			name:     "configured weight over cost weight",
+			query:    `query { search(term: "a") { id } }`,
+			opts:     []complexity.Option{complexity.WithFieldWeights(map[string]int{"Query.search": 2})},
+			expected: 3,
+		},
+		{
+			name:     "scalar complexity",
+			query:    `query { users { id name } }`,
+			opts:     []complexity.Option{complexity.WithScalarComplexity(0)},
+			expected: 1,
+		},
+		{
+			name:     "base complexity",
+			query:    `query { users { id name } }`,
+			opts:     []complexity.Option{complexity.WithBaseComplexity(2)},
+			expected: 42,
+		},
+		{
+			name:     "scalar complexity over base complexity",
+			query:    `query { users { id name } }`,
+			opts:     []complexity.Option{complexity.WithBaseComplexity(2), complexity.WithScalarComplexity(0)},
+			expected: 2,
+		},
+		{
+			name:     "cost weight over scalar complexity",
+			query:    `query { search(term: "a") { id } }`,
+			opts:     []complexity.Option{complexity.WithScalarComplexity(0)},
+			expected: 5,
+		},
+		{
+			name:     "scalar complexity with depth decay",
+			query:    `query { users { id } }`,
+			opts:     []complexity.Option{complexity.WithDepthDecay(2), complexity.WithScalarComplexity(0)},
+			expected: 1,
+		},
+		{
+			name:     "depth decay",
+			query:    `query { users { id } }`,
+			opts:     []complexity.Option{complexity.WithDepthDecay(2)},
+			expected: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"schema.graphqls": {Data: []byte(costSchema)},
+				"query.graphql":   {Data: []byte(tt.query)},
+			}
+
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to run analysis: %v", err)
+			}
+			if len(result) != 1 {
+				t.Fatalf("RunAnalysisFS() returned %d results, want 1", len(result))
+			}
+			if result[0].Complexity != tt.expected {
+				t.Errorf("RunAnalysisFS() Complexity = %d, want %d", result[0].Complexity, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunAnalysisComplexityFunc(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"query.graphql":   {Data: []byte(`query { user(id: 1) { id name } }`)},
+	}
+
+	// Object fields, the only ones with a child complexity, cost 2 rather
+	// than 1. Leaves are left to the default cost.
+	double := func(ctx context.Context, typeName, fieldName string, childComplexity int, args map[string]any) (int, bool) {
+		if childComplexity == 0 {
+			return 0, false
+		}
+		return 2 + childComplexity, true
+	}
+
+	result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithComplexityFunc(double))
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("RunAnalysisFS() returned %d results, want 1", len(result))
+	}
+	if result[0].Complexity != 4 {
+		t.Errorf("RunAnalysisFS() Complexity = %d, want %d", result[0].Complexity, 4)
+	}
+}
+
+func TestRunAnalysisFreeDirective(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		query    string
+		opts     []complexity.Option
+		expected int
+	}{
+		{
+			name: "free leaf",
+			schema: `type Query { user: User }
+			type User { id: ID! @free name: String! }`,
+			query:    `query { user { id name } }`,
+			expected: 2,
+		},
+		{
+			name: "free field pays for its selection set",
+			schema: `type Query { user: User }
+			type User { id: ID! friends: [User!]! @free @listSize(assumedSize: 2) }`,
+			query:    `query { user { friends { id } } }`,
+			expected: 3,
+		},
+		{
+			name: "renamed directive",
+			schema: `type Query { user: User }
+			type User { id: ID! @cached name: String! }`,
+			query:    `query { user { id name } }`,
+			opts:     []complexity.Option{complexity.WithFreeDirective("cached")},
+			expected: 2,
+		},
+		{
+			name: "declared directive",
+			schema: `directive @free on FIELD_DEFINITION
+			type Query { user: User }
+			type User { id: ID! @free name: String! }`,
+			query:    `query { user { id name } }`,
+			expected: 2,
+		},
+		{
+			name: "configured weight over free directive",
+			schema: `type Query { user: User }
+			type User { id: ID! @free name: String! }`,
+			query:    `query { user { id name } }`,
+			opts:     []complexity.Option{complexity.WithFieldWeights(map[string]int{"User.id": 3})},
+			expected: 5,
+		},
+		{
+			name: "depth decay",
+			schema: `type Query { user: User }
+			type User { id: ID! @free name: String! }`,
+			query:    `query { user { id } }`,
+			opts:     []complexity.Option{complexity.WithDepthDecay(2)},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"schema.graphqls": {Data: []byte(tt.schema)},
+				"query.graphql":   {Data: []byte(tt.query)},
+			}
+
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to run analysis: %v", err)
+			}
+			if len(result) != 1 {
+				t.Fatalf("RunAnalysisFS() returned %d results, want 1", len(result))
+			}
+			if result[0].Complexity != tt.expected {
+				t.Errorf("RunAnalysisFS() Complexity = %d, want %d", result[0].Complexity, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunAnalysisIgnoreFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		query    string
+		opts     []complexity.Option
+		expected int
+	}{
+		{
+			name: "ignored leaf",
+			schema: `type Query { user: User }
+			type User { id: ID! name: String! }`,
+			query:    `query { user { id name } }`,
+			opts:     []complexity.Option{complexity.WithIgnoreFields("User.id")},
+			expected: 2,
+		},
+		{
+			name: "ignored field pays for its selection set",
+			schema: `type Query { user: User }
+			type User { id: ID! friends: [User!]! @listSize(assumedSize: 2) }`,
+			query:    `query { user { friends { id } } }`,
+			opts:     []complexity.Option{complexity.WithIgnoreFields("User.friends")},
+			expected: 3,
+		},
+		{
+			name: "ignored root field",
+			schema: `type Query { user: User }
+			type User { id: ID! name: String! }`,
+			query:    `query { user { id name } }`,
+			opts:     []complexity.Option{complexity.WithIgnoreFields("Query.user")},
+			expected: 2,
+		},
+		{
+			name: "ignored over configured weight and cost directive",
+			schema: `type Query { user: User }
+			type User { id: ID! @cost(weight: 4) name: String! }`,
+			query: `query { user { id name } }`,
+			opts: []complexity.Option{
+				complexity.WithFieldWeights(map[string]int{"User.name": 3}),
+				complexity.WithIgnoreFields("User.id", "User.name"),
+			},
+			expected: 1,
+		},
+		{
+			name: "other type",
+			schema: `type Query { user: User post: Post }
+			type User { id: ID! }
+			type Post { id: ID! }`,
+			query:    `query { user { id } post { id } }`,
+			opts:     []complexity.Option{complexity.WithIgnoreFields("User.id")},
+			expected: 3,
+		},
+		{
+			name: "depth decay",
+			schema: `type Query { user: User }
+			type User { id: ID! name: String! }`,
+			query:    `query { user { id } }`,
+			opts:     []complexity.Option{complexity.WithDepthDecay(2), complexity.WithIgnoreFields("User.id")},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"schema.graphqls": {Data: []byte(tt.schema)},
+				"query.graphql":   {Data: []byte(tt.query)},
+			}
+
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to run analysis: %v", err)
+			}
+			if len(result) != 1 {
+				t.Fatalf("RunAnalysisFS() returned %d results, want 1", len(result))
+			}
+			if result[0].Complexity != tt.expected {
+				t.Errorf("RunAnalysisFS() Complexity = %d, want %d", result[0].Complexity, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRunAnalysisCountTypename(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		opts     []complexity.Option
+		expected int
+	}{
+		{name: "free", query: `query { __typename user(id: 1) { __typename id } }`, expected: 2},
+		{name: "counted", query: `query { __typename user(id: 1) { __typename id } }`, opts: []complexity.Option{complexity.WithTypename()}, expected: 4},
+		{name: "counted with scalar complexity", query: `query { user(id: 1) { __typename id } }`, opts: []complexity.Option{complexity.WithTypename(), complexity.WithScalarComplexity(2)}, expected: 5},
+		{name: "free with depth decay", query: `query { user(id: 1) { __typename } }`, opts: []complexity.Option{complexity.WithDepthDecay(2)}, expected: 1},
+		{name: "counted with depth decay", query: `query { user(id: 1) { __typename } }`, opts: []complexity.Option{complexity.WithDepthDecay(2), complexity.WithTypename()}, expected: 2},
+		{name: "configured weight", query: `query { user(id: 1) { __typename } }`, opts: []complexity.Option{complexity.WithFieldWeights(map[string]int{"User.__typename": 3})}, expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"schema.graphqls": {Data: []byte(schema)},
+				"query.graphql":   {Data: []byte(tt.query)},
+			}
+
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to run analysis: %v", err)
+			}
+			if len(result) != 1 {
+				t.Fatalf("RunAnalysisFS() returned %d results, want 1", len(result))
+			}
+			if result[0].Complexity != tt.expected {
+				t.Errorf("RunAnalysisFS() Complexity = %d, want %d", result[0].Complexity, tt.expected)
+			}
+		})
+	}
+}
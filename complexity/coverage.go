@@ -0,0 +1,84 @@
+package complexity
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// FieldCoverage is the number of fields of a type that an operation selects,
+// out of the fields the type has.
+type FieldCoverage struct {
+	TypeName string `json:"type" yaml:"type" toml:"type"`
+	Selected int    `json:"selected" yaml:"selected" toml:"selected"`
+	Fields   int    `json:"fields" yaml:"fields" toml:"fields"`
+}
+
+// Ratio returns the share of the type's fields that are selected.
+func (c FieldCoverage) Ratio() float64 {
+	if c.Fields == 0 {
+		return 0
+	}
+	return float64(c.Selected) / float64(c.Fields)
+}
+
+// OperationFieldCoverage returns the coverage of every object and interface
+// type the operation and the fragments it uses select fields on, sorted by
+// type name. Root operation types, whose fields are separate entry points
+// rather than the columns of an object, are left out. Selecting a field
+// again, under an alias or with other arguments, does not add to the
+// coverage, and neither do introspection fields such as __typename. Fields of
+// documents not validated against the schema are not counted.
+func OperationFieldCoverage(schema *ast.Schema, doc *ast.QueryDocument, op *ast.OperationDefinition) []FieldCoverage {
+	selected := make(map[string]map[string]bool)
+	collectCoverage(op.SelectionSet, selected)
+	for _, frag := range usedFragments(doc, op.SelectionSet) {
+		collectCoverage(frag.SelectionSet, selected)
+	}
+
+	var coverage []FieldCoverage
+	for _, name := range slices.Sorted(maps.Keys(selected)) {
+		def := schema.Types[name]
+		if def == nil || isRootType(schema, def) {
+			continue
+		}
+
+		var fields int
+		for _, field := range def.Fields {
+			if !strings.HasPrefix(field.Name, "__") {
+				fields++
+			}
+		}
+		coverage = append(coverage, FieldCoverage{TypeName: name, Selected: len(selected[name]), Fields: fields})
+	}
+	return coverage
+}
+
+// collectCoverage adds the fields selected in the selection set to selected,
+// keyed by the type they are selected on, leaving out the fragments it
+// spreads.
+func collectCoverage(selectionSet ast.SelectionSet, selected map[string]map[string]bool) {
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if sel.ObjectDefinition != nil && !strings.HasPrefix(sel.Name, "__") {
+				name := sel.ObjectDefinition.Name
+				if selected[name] == nil {
+					selected[name] = make(map[string]bool)
+				}
+				selected[name][sel.Name] = true
+			}
+			collectCoverage(sel.SelectionSet, selected)
+		case *ast.InlineFragment:
+			collectCoverage(sel.SelectionSet, selected)
+		}
+	}
+}
+
+// isRootType reports whether the type is one of the schema's root operation
+// types.
+func isRootType(schema *ast.Schema, def *ast.Definition) bool {
+	return def == schema.Query || def == schema.Mutation || def == schema.Subscription
+}
@@ -0,0 +1,96 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const coverageSchema = `type Query {
+	user(id: ID!): User
+	users: [User!]!
+}
+
+type User {
+	id: ID!
+	name: String!
+	email: String!
+	avatar: String!
+	friends: [User!]!
+}
+`
+
+func TestOperationFieldCoverage(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: coverageSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []complexity.FieldCoverage
+	}{
+		{
+			name:  "some fields",
+			query: `query GetUser { user(id: 1) { id name } }`,
+			expected: []complexity.FieldCoverage{
+				{TypeName: "User", Selected: 2, Fields: 5},
+			},
+		},
+		{
+			name: "fields across selections and fragments",
+			query: `query GetUsers {
+				users { id ...UserFields __typename }
+				user(id: 1) { id other: name friends { ... on User { avatar } } }
+			}
+
+			fragment UserFields on User { name email }`,
+			expected: []complexity.FieldCoverage{
+				{TypeName: "User", Selected: 5, Fields: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, tt.query)
+			if gqlErr != nil {
+				t.Fatalf("failed to load query: %v", gqlErr)
+			}
+
+			got := complexity.OperationFieldCoverage(schemaDoc, queryDoc, queryDoc.Operations[0])
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("OperationFieldCoverage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckFieldCoverage(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "users.graphql", OperationName: "GetUsers", Coverage: []complexity.FieldCoverage{
+			{TypeName: "Query", Selected: 1, Fields: 2},
+			{TypeName: "User", Selected: 9, Fields: 10},
+		}},
+		{Path: "users.graphql", OperationName: "GetUser", Coverage: []complexity.FieldCoverage{
+			{TypeName: "User", Selected: 8, Fields: 10},
+		}},
+	}
+
+	expected := []complexity.Violation{
+		{
+			Path:          "users.graphql",
+			OperationName: "GetUsers",
+			Rule:          complexity.RuleMaxFieldCoverage,
+			Subject:       "User",
+			Message:       "selects 9 of 10 fields (90%), limit is 80%",
+		},
+	}
+	if diff := cmp.Diff(expected, complexity.CheckFieldCoverage(results, 0.8)); diff != "" {
+		t.Errorf("CheckFieldCoverage() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,130 @@
+package complexity
+
+import (
+	"math"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// calculateDecayed computes the complexity of a selection set on the parent
+// type where each field costs its weight divided by cfg.DepthDecay^depth
+// rather than its weight, with the fields of the selection set at depth 0.
+// Deeper fields are cheaper as fewer of them execute per parent. Selection
+// sets are multiplied by list sizes and abstract types cost their most
+// expensive type as in calculate, and initial has the same meaning as there.
+// The total is rounded up.
+func calculateDecayed(schema *ast.Schema, parent *ast.Definition, selectionSet ast.SelectionSet, vars Variables, cfg Config, initial bool) int {
+	w := decayWalker{
+		schema:  schema,
+		vars:    vars,
+		cfg:     cfg,
+		initial: initial,
+	}
+
+	c := math.Ceil(w.selectionSetComplexity(parent, selectionSet, 0))
+	if c >= float64(maxInt) {
+		return maxInt
+	}
+	return int(c)
+}
+
+type decayWalker struct {
+	schema  *ast.Schema
+	vars    Variables
+	cfg     Config
+	initial bool
+}
+
+// selectionSetComplexity computes the complexity of a selection set on the
+// given parent type whose fields are at the given depth.
+func (w decayWalker) selectionSetComplexity(parent *ast.Definition, selectionSet ast.SelectionSet, depth int) float64 {
+	var (
+		complexity float64
+		conditions = make(map[string]float64)
+	)
+
+	for _, selection := range selectionSet {
+		if !included(selectionDirectives(selection), w.vars, w.cfg.logger()) {
+			continue
+		}
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			complexity += w.fieldComplexity(s, depth)
+
+		case *ast.FragmentSpread:
+			if s.Definition == nil || w.initial && isDeferred(s.Directives, w.vars) {
+				continue
+			}
+			complexity += w.fragmentComplexity(parent, s.Definition.TypeCondition, s.Definition.SelectionSet, depth, conditions)
+
+		case *ast.InlineFragment:
+			if w.initial && isDeferred(s.Directives, w.vars) {
+				continue
+			}
+			complexity += w.fragmentComplexity(parent, s.TypeCondition, s.SelectionSet, depth, conditions)
+		}
+	}
+
+	conditionComplexity, _ := mostExpensiveCondition(w.schema, parent, conditions, w.cfg.WorstCase, addFloat)
+	return complexity + conditionComplexity
+}
+
+func addFloat(a, b float64) float64 { return a + b }
+
+// fragmentComplexity returns the complexity a fragment adds to its selection
+// set. Fragments narrowing an abstract parent to another type are instead
+// summed per type condition and add nothing.
+func (w decayWalker) fragmentComplexity(parent *ast.Definition, typeCondition string, selectionSet ast.SelectionSet, depth int, conditions map[string]float64) float64 {
+	if typeCondition == "" || parent == nil || typeCondition == parent.Name {
+		return w.selectionSetComplexity(parent, selectionSet, depth)
+	}
+
+	fragmentComplexity := w.selectionSetComplexity(w.schema.Types[typeCondition], selectionSet, depth)
+	if !isAbstract(parent) {
+		return fragmentComplexity
+	}
+
+	conditions[typeCondition] += fragmentComplexity
+	return 0
+}
+
+// fieldComplexity computes the complexity of a single field at the given
+// depth including its selection set.
+func (w decayWalker) fieldComplexity(field *ast.Field, depth int) float64 {
+	if field.Definition == nil || field.ObjectDefinition == nil {
+		// The document has not been validated against the schema.
+		return 0
+	}
+
+	fieldType := w.schema.Types[field.Definition.Type.Name()]
+	if fieldType == nil || fieldType.Name == "__Schema" {
+		return 0
+	}
+
+	var childComplexity float64
+	switch fieldType.Kind {
+	case ast.Object, ast.Interface, ast.Union:
+		childComplexity = w.selectionSetComplexity(fieldType, field.SelectionSet, depth+1)
+	}
+
+	// As in calculate, a field of an interface costs as much as it does on
+	// the most expensive implementation.
+	objects := []*ast.Definition{field.ObjectDefinition}
+	if field.ObjectDefinition.Kind == ast.Interface {
+		objects = w.schema.GetPossibleTypes(field.ObjectDefinition)
+	}
+
+	args := w.vars.Arguments(field)
+	var complexity float64
+	for _, object := range objects {
+		def := fieldDefinition(w.schema, object.Name, field.Name)
+		weight := float64(fieldWeight(w.schema, def, object.Name, args, w.cfg))
+		multiplier := fieldMultiplier(def, object.Name, args, w.cfg)
+		if n, ok := streamedItems(field, w.vars); ok && w.initial {
+			multiplier = min(multiplier, n)
+		}
+		complexity = max(complexity, weight*math.Pow(w.cfg.DepthDecay, -float64(depth))+childComplexity*float64(multiplier))
+	}
+	return complexity
+}
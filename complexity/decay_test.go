@@ -0,0 +1,100 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestAnalyseDocumentDepthDecay(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "abstract.graphqls", Input: abstractSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		decay    float64
+		expected int
+	}{
+		{
+			name:     "no decay",
+			query:    `query GetNode { node(id: 1) { id } }`,
+			decay:    1,
+			expected: 2,
+		},
+		{
+			name: "halving",
+			query: `query GetNode {
+				node(id: 1) {
+					id
+					... on User { name friends { id } }
+					... on Bot { name }
+				}
+			}`,
+			decay: 2,
+			// node 1 + id 0.5 + the User branch (name 0.5 + friends 0.5 + id 0.25)
+			expected: 3,
+		},
+		{
+			name: "union",
+			query: `query Search {
+				search(term: "a") {
+					... on User { friends { name } }
+					... on Bot { name }
+				}
+			}`,
+			decay: 4,
+			// search 1 + the User branch (friends 0.25 + name 0.0625)
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, err := parser.ParseQuery(&ast.Source{Name: "query.graphql", Input: tt.query})
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithDepthDecay(tt.decay))
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+
+			if result[0].Complexity != tt.expected {
+				t.Errorf("AnalyseDocument() Complexity = %d, want %d", result[0].Complexity, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyseDocumentDepthDecayInterfaceFields(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "abstract.graphqls", Input: abstractSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{Name: "query.graphql", Input: `query GetNode { node(id: 1) { id } }`})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	// The id of a User is the most expensive implementation of Node.id. A
+	// decay of 1 leaves deeper fields as expensive as in gqlgen's model.
+	weights := complexity.WithFieldWeights(map[string]int{"User.id": 5})
+	for _, decay := range []float64{0, 1} {
+		result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, weights, complexity.WithDepthDecay(decay))
+		if err != nil {
+			t.Fatalf("failed to analyse document: %v", err)
+		}
+
+		if result[0].Complexity != 6 {
+			t.Errorf("AnalyseDocument() with decay %v Complexity = %d, want 6", decay, result[0].Complexity)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package complexity
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+// CountDepth returns the deepest nesting of fields selected by the operation
+// once fragments are expanded. Root fields are at depth 1.
+func CountDepth(doc *ast.QueryDocument, op *ast.OperationDefinition) int {
+	return selectionSetDepth(Flatten(doc, op).SelectionSet)
+}
+
+// selectionSetDepth returns the depth of a flattened selection set.
+func selectionSetDepth(selectionSet ast.SelectionSet) int {
+	var depth int
+	for _, selection := range selectionSet {
+		switch s := selection.(type) {
+		case *ast.Field:
+			depth = max(depth, 1+selectionSetDepth(s.SelectionSet))
+		case *ast.InlineFragment:
+			// Fragments kept apart by type condition, see
+			// Config.TypeConditions, are as deep as their fields.
+			depth = max(depth, selectionSetDepth(s.SelectionSet))
+		}
+	}
+	return depth
+}
@@ -0,0 +1,48 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestCountDepth(t *testing.T) {
+	queryDoc, err := parser.ParseQuery(&ast.Source{
+		Name: "depth.graphql",
+		Input: `query Friends {
+			user(id: 1) {
+				id
+				...Friends
+			}
+		}
+
+		fragment Friends on User {
+			friends { friends { name } }
+		}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	if got := complexity.CountDepth(queryDoc, queryDoc.Operations[0]); got != 4 {
+		t.Errorf("CountDepth() = %d, want 4", got)
+	}
+}
+
+func TestCheckDepth(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "Shallow", Depth: 2},
+		{Path: "a.graphql", OperationName: "Deep", Depth: 6},
+	}
+
+	expected := []complexity.Violation{
+		{Path: "a.graphql", OperationName: "Deep", Rule: complexity.RuleMaxDepth, Value: 6, Limit: 5},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckDepth(results, 5)); diff != "" {
+		t.Errorf("CheckDepth() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,51 @@
+package complexity
+
+import (
+	"errors"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Diagnostic describes a problem with a file found during analysis, such as a
+// syntax or validation error, positioned for editor integrations. Line and
+// Column are 1-based and zero when the position is unknown.
+//
+// Diagnostics of fields unknown to their parent type also name the Field and
+// its Type, and suggest the closest field name of that type when one is
+// likely a typo.
+type Diagnostic struct {
+	Path       string
+	Line       int
+	Column     int
+	Message    string
+	Field      string
+	Type       string
+	Suggestion string
+}
+
+// Diagnostics converts an error found while analysing the file at path into
+// diagnostics, one for each GraphQL error it wraps.
+func Diagnostics(path string, err error) []Diagnostic {
+	var list gqlerror.List
+	if !errors.As(err, &list) {
+		var gqlErr *gqlerror.Error
+		if !errors.As(err, &gqlErr) {
+			return []Diagnostic{{Path: path, Message: err.Error()}}
+		}
+		list = gqlerror.List{gqlErr}
+	}
+
+	var diagnostics []Diagnostic
+	for _, e := range list {
+		d := Diagnostic{Path: path, Message: e.Message}
+		d.Field, _ = e.Extensions[extensionField].(string)
+		d.Type, _ = e.Extensions[extensionType].(string)
+		d.Suggestion, _ = e.Extensions[extensionSuggestion].(string)
+		if len(e.Locations) > 0 {
+			d.Line = e.Locations[0].Line
+			d.Column = e.Locations[0].Column
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
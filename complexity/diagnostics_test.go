@@ -0,0 +1,82 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestRunAnalysisDiagnostics(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"schema.graphqls": schema,
+		"invalid.graphql": "query GetUser {\n  user(id: 1) {\n    email\n  }\n}",
+		"syntax.graphql":  "query GetUser {\n  user(id: 1) {\n",
+	})
+
+	var diagnostics []complexity.Diagnostic
+	_, err := complexity.RunAnalysis(t.Context(), "*.graphqls", "*.graphql", complexity.WithDiagnosticHandler(func(d complexity.Diagnostic) {
+		diagnostics = append(diagnostics, d)
+	}))
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.Diagnostic{
+		{Path: "invalid.graphql", Line: 3, Column: 5, Message: `Cannot query field "email" on type "User".`, Field: "email", Type: "User"},
+		{Path: "syntax.graphql", Line: 3, Column: 1, Message: "Expected Name, found <EOF>"},
+	}
+
+	if diff := cmp.Diff(expected, diagnostics); diff != "" {
+		t.Errorf("diagnostics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiagnosticsSuggestField(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{
+		Name: "typo.graphql",
+		Input: `query GetUser {
+	user(id: 1) {
+		nmae
+		email
+	}
+}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	err = complexity.ValidateDocument(schemaDoc, queryDoc)
+
+	expected := []complexity.Diagnostic{
+		{
+			Path:       "typo.graphql",
+			Line:       3,
+			Column:     3,
+			Message:    `Cannot query field "nmae" on type "User". Did you mean "name"?`,
+			Field:      "nmae",
+			Type:       "User",
+			Suggestion: "name",
+		},
+		{
+			Path:    "typo.graphql",
+			Line:    4,
+			Column:  3,
+			Message: `Cannot query field "email" on type "User".`,
+			Field:   "email",
+			Type:    "User",
+		},
+	}
+
+	if diff := cmp.Diff(expected, complexity.Diagnostics("typo.graphql", err)); diff != "" {
+		t.Errorf("Diagnostics() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,62 @@
+package complexity
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// DirectiveCount holds the number of directives given to a single selection
+// of a field.
+type DirectiveCount struct {
+	Field string `json:"field" yaml:"field" toml:"field"`
+	Count int    `json:"count" yaml:"count" toml:"count"`
+}
+
+// CountDirectives counts the directives given to each field the operation
+// selects, including in the fragments it spreads. For each field the highest
+// count of any of its selections is returned, sorted by field name. Fields
+// without directives are left out.
+func CountDirectives(doc *ast.QueryDocument, op *ast.OperationDefinition) []DirectiveCount {
+	counts := make(map[string]int)
+	countDirectives(op.SelectionSet, doc, counts, make(map[string]bool))
+
+	var result []DirectiveCount
+	for field, count := range counts {
+		result = append(result, DirectiveCount{Field: field, Count: count})
+	}
+
+	slices.SortFunc(result, func(a, b DirectiveCount) int {
+		return cmp.Compare(a.Field, b.Field)
+	})
+
+	return result
+}
+
+// countDirectives records the directive counts of the fields in the selection
+// set in counts. Fragments are only visited once.
+func countDirectives(selectionSet ast.SelectionSet, doc *ast.QueryDocument, counts map[string]int, visited map[string]bool) {
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if n := len(sel.Directives); n > 0 {
+				field := fieldName(sel)
+				counts[field] = max(counts[field], n)
+			}
+			countDirectives(sel.SelectionSet, doc, counts, visited)
+
+		case *ast.InlineFragment:
+			countDirectives(sel.SelectionSet, doc, counts, visited)
+
+		case *ast.FragmentSpread:
+			if visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			if fragDef := findFragmentDefinition(doc, sel.Name); fragDef != nil {
+				countDirectives(fragDef.SelectionSet, doc, counts, visited)
+			}
+		}
+	}
+}
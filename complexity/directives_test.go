@@ -0,0 +1,70 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestCountDirectives(t *testing.T) {
+	// Repeating @include fails validation, so the document is only parsed
+	// and fields are not qualified by their type.
+	queryDoc, err := parser.ParseQuery(&ast.Source{Name: "user.graphql", Input: `query GetUser($a: Boolean!, $b: Boolean!) {
+		user(id: 1) @include(if: $a) {
+			id
+			name @include(if: $a) @skip(if: $b) @include(if: true)
+			...Names
+		}
+	}
+
+	fragment Names on User {
+		name @skip(if: $b)
+	}`})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	got := complexity.CountDirectives(queryDoc, queryDoc.Operations[0])
+
+	expected := []complexity.DirectiveCount{
+		{Field: "name", Count: 3},
+		{Field: "user", Count: 1},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("CountDirectives() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckDirectives(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{
+			Path:          "user.graphql",
+			OperationName: "GetUser",
+			Directives: []complexity.DirectiveCount{
+				{Field: "Query.user", Count: 1},
+				{Field: "User.name", Count: 3},
+			},
+		},
+	}
+
+	got := complexity.CheckDirectives(results, 2)
+
+	expected := []complexity.Violation{
+		{
+			Path:          "user.graphql",
+			OperationName: "GetUser",
+			Rule:          complexity.RuleMaxDirectives,
+			Subject:       "User.name",
+			Value:         3,
+			Limit:         2,
+		},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("CheckDirectives() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,146 @@
+package complexity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// JSONFormatter writes the results as an indented JSON array.
+type JSONFormatter struct {
+	GroupByFile bool
+}
+
+func (f JSONFormatter) Format(w io.Writer, results []ComplexityAnalysis) error {
+	if f.GroupByFile {
+		return writeJSON(w, nonNil(GroupByFile(results)))
+	}
+	return formatAll(f, w, results)
+}
+
+// Stream writes each result as an element of the array as soon as it is
+// written. Grouped results are written on Close.
+func (f JSONFormatter) Stream(w io.Writer) ResultWriter {
+	if f.GroupByFile {
+		return &bufferedWriter{flush: func(results []ComplexityAnalysis) error {
+			return f.Format(w, results)
+		}}
+	}
+	return &jsonWriter{w: w}
+}
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(r ComplexityAnalysis) error {
+	b, err := json.MarshalIndent(r, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "[\n  "
+	}
+	j.count++
+
+	_, err = fmt.Fprintf(j.w, "%s%s", sep, b)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+// YAMLFormatter writes the results as a YAML sequence.
+type YAMLFormatter struct {
+	GroupByFile bool
+}
+
+func (f YAMLFormatter) Format(w io.Writer, results []ComplexityAnalysis) error {
+	if f.GroupByFile {
+		return writeYAML(w, nonNil(GroupByFile(results)))
+	}
+	return formatAll(f, w, results)
+}
+
+// Stream writes each result as an item of the sequence as soon as it is
+// written. Grouped results are written on Close.
+func (f YAMLFormatter) Stream(w io.Writer) ResultWriter {
+	if f.GroupByFile {
+		return &bufferedWriter{flush: func(results []ComplexityAnalysis) error {
+			return f.Format(w, results)
+		}}
+	}
+	return &yamlWriter{w: w}
+}
+
+type yamlWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (y *yamlWriter) Write(r ComplexityAnalysis) error {
+	y.count++
+	return writeYAML(y.w, []ComplexityAnalysis{r})
+}
+
+func (y *yamlWriter) Close() error {
+	if y.count == 0 {
+		return writeYAML(y.w, []ComplexityAnalysis{})
+	}
+	return nil
+}
+
+// TOMLFormatter writes the results as an "operation" array of tables, or a
+// "file" array of tables when grouped by file.
+type TOMLFormatter struct {
+	GroupByFile bool
+}
+
+func (f TOMLFormatter) Format(w io.Writer, results []ComplexityAnalysis) error {
+	if f.GroupByFile {
+		return toml.NewEncoder(w).Encode(struct {
+			Files []FileGroup `toml:"file"`
+		}{nonNil(GroupByFile(results))})
+	}
+	return toml.NewEncoder(w).Encode(struct {
+		Operations []ComplexityAnalysis `toml:"operation"`
+	}{nonNil(results)})
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAML writes v as YAML.
+func writeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// nonNil returns an empty slice in place of nil, so that no results encode as
+// an empty list rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
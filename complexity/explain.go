@@ -0,0 +1,64 @@
+package complexity
+
+import (
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// ComplexityNode is a field selected by an operation with the complexity it
+// adds, including its selection set, and the fields selected on it.
+type ComplexityNode struct {
+	Field       string           `json:"field" yaml:"field" toml:"field"`
+	Type        string           `json:"type" yaml:"type" toml:"type"`
+	Complexity  int              `json:"complexity" yaml:"complexity" toml:"complexity"`
+	Description string           `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+	Children    []ComplexityNode `json:"children,omitempty" yaml:"children,omitempty" toml:"children,omitempty"`
+}
+
+// Explain returns the fields the operation selects as a tree, with the
+// complexity of each field and the description the schema gives it. Fields
+// selected by fragments are listed with the fields around them, including
+// every type condition of an abstract selection although only the most
+// expensive one is counted towards its parent. Like TypeBreakdown it does not
+// apply Config.DepthDecay.
+func Explain(schema *ast.Schema, op *ast.OperationDefinition, opts ...Option) []ComplexityNode {
+	cfg := newConfig(opts)
+	w := breakdownWalker{
+		schema: schema,
+		vars:   NewVariables(op, cfg.Variables),
+		cfg:    cfg,
+	}
+	return w.explainSelectionSet(op.SelectionSet)
+}
+
+func (w breakdownWalker) explainSelectionSet(selectionSet ast.SelectionSet) []ComplexityNode {
+	var nodes []ComplexityNode
+	for _, selection := range selectionSet {
+		if !included(selectionDirectives(selection), w.vars, w.cfg.logger()) {
+			continue
+		}
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.Definition == nil {
+				// The document has not been validated against the schema.
+				continue
+			}
+			nodes = append(nodes, ComplexityNode{
+				Field:       s.Alias,
+				Type:        s.Definition.Type.String(),
+				Complexity:  w.fieldCosts(s).total(),
+				Description: s.Definition.Description,
+				Children:    w.explainSelectionSet(s.SelectionSet),
+			})
+
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				nodes = append(nodes, w.explainSelectionSet(s.Definition.SelectionSet)...)
+			}
+
+		case *ast.InlineFragment:
+			nodes = append(nodes, w.explainSelectionSet(s.SelectionSet)...)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,91 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestExplain(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: `
+		type Query {
+			"The user with the given id."
+			user(id: ID!): User
+		}
+
+		type User {
+			id: ID!
+			name: String!
+			"""
+			The most recent orders of the user.
+			"""
+			orders(first: Int): [Order!]!
+		}
+
+		type Order {
+			id: ID!
+			total: Int!
+		}
+	`})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, errs := gqlparser.LoadQuery(schemaDoc, `query GetUser {
+		user(id: 1) {
+			name
+			latest: orders(first: 3) {
+				...OrderFields
+			}
+		}
+	}
+
+	fragment OrderFields on Order {
+		id
+		total
+	}`)
+	if errs != nil {
+		t.Fatalf("failed to load query: %v", errs)
+	}
+
+	opts := []complexity.Option{complexity.WithListMultiplierArgs("first")}
+	got := complexity.Explain(schemaDoc, queryDoc.Operations[0], opts...)
+
+	expected := []complexity.ComplexityNode{
+		{
+			Field:       "user",
+			Type:        "User",
+			Complexity:  9,
+			Description: "The user with the given id.",
+			Children: []complexity.ComplexityNode{
+				{Field: "name", Type: "String!", Complexity: 1},
+				{
+					Field:       "latest",
+					Type:        "[Order!]!",
+					Complexity:  7,
+					Description: "The most recent orders of the user.",
+					Children: []complexity.ComplexityNode{
+						{Field: "id", Type: "ID!", Complexity: 1},
+						{Field: "total", Type: "Int!", Complexity: 1},
+					},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Explain() mismatch (-want +got):\n%s", diff)
+	}
+
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, append(opts, complexity.WithExplain())...)
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	if result[0].Explain[0].Complexity != result[0].Complexity {
+		t.Errorf("root field complexity = %d, want the operation complexity %d", result[0].Explain[0].Complexity, result[0].Complexity)
+	}
+}
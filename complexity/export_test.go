@@ -0,0 +1,29 @@
+package complexity
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+// FlattenOperation exposes the flattening of operations to the tests, without
+// the path for operations that are already flat.
+func FlattenOperation(doc *ast.QueryDocument, op *ast.OperationDefinition) *ast.OperationDefinition {
+	return flattenOperation(newFlattener(doc, nil), op)
+}
+
+// FlattenDocument flattens every operation of the document, sharing the
+// flattened fragments between them as AnalyseDocument does.
+func FlattenDocument(doc *ast.QueryDocument) []*ast.OperationDefinition {
+	f := newFlattener(doc, nil)
+	var ops []*ast.OperationDefinition
+	for _, op := range doc.Operations {
+		ops = append(ops, flattenOperation(f, op))
+	}
+	return ops
+}
+
+// SplitDefinitions exposes the splitting of mixed schema files to the tests.
+func SplitDefinitions(src *ast.Source) (schema, executable *ast.Source, err error) {
+	return splitDefinitions(src)
+}
+
+// MaxArchiveFileSize exposes the size of the largest file read from an
+// archive to the tests.
+const MaxArchiveFileSize = maxArchiveFileSize
@@ -0,0 +1,116 @@
+package complexity
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// federationDefinitions are the directives and types of Apollo Federation
+// subgraph schemas, keyed by the name they declare.
+var federationDefinitions = []struct {
+	name string
+	sdl  string
+}{
+	{"@authenticated", `directive @authenticated on FIELD_DEFINITION | OBJECT | INTERFACE | SCALAR | ENUM`},
+	{"@composeDirective", `directive @composeDirective(name: String!) repeatable on SCHEMA`},
+	{"@extends", `directive @extends on OBJECT | INTERFACE`},
+	{"@external", `directive @external(reason: String) on OBJECT | FIELD_DEFINITION`},
+	{"@key", `directive @key(fields: FieldSet!, resolvable: Boolean = true) repeatable on OBJECT | INTERFACE`},
+	{"@inaccessible", `directive @inaccessible on ARGUMENT_DEFINITION | ENUM | ENUM_VALUE | FIELD_DEFINITION | INPUT_FIELD_DEFINITION | INPUT_OBJECT | INTERFACE | OBJECT | SCALAR | UNION`},
+	{"@interfaceObject", `directive @interfaceObject on OBJECT`},
+	{"@link", `directive @link(url: String!, as: String, for: link__Purpose, import: [link__Import]) repeatable on SCHEMA`},
+	{"@override", `directive @override(from: String!, label: String) on FIELD_DEFINITION`},
+	{"@policy", `directive @policy(policies: [[federation__Policy!]!]!) on FIELD_DEFINITION | OBJECT | INTERFACE | SCALAR | ENUM`},
+	{"@provides", `directive @provides(fields: FieldSet!) on FIELD_DEFINITION`},
+	{"@requires", `directive @requires(fields: FieldSet!) on FIELD_DEFINITION`},
+	{"@requiresScopes", `directive @requiresScopes(scopes: [[federation__Scope!]!]!) on FIELD_DEFINITION | OBJECT | INTERFACE | SCALAR | ENUM`},
+	{"@shareable", `directive @shareable repeatable on FIELD_DEFINITION | OBJECT`},
+	{"@tag", `directive @tag(name: String!) repeatable on ARGUMENT_DEFINITION | ENUM | ENUM_VALUE | FIELD_DEFINITION | INPUT_FIELD_DEFINITION | INPUT_OBJECT | INTERFACE | OBJECT | SCALAR | UNION`},
+	{"_Any", `scalar _Any`},
+	{"_FieldSet", `scalar _FieldSet`},
+	{"FieldSet", `scalar FieldSet`},
+	{"link__Import", `scalar link__Import`},
+	{"link__Purpose", `enum link__Purpose { SECURITY EXECUTION }`},
+	{"federation__Policy", `scalar federation__Policy`},
+	{"federation__Scope", `scalar federation__Scope`},
+	{"_Service", `type _Service { sdl: String }`},
+}
+
+// federationSource returns a source declaring the Apollo Federation directives
+// and types that the schema sources use without declaring, so that subgraph
+// schemas load like the schema a subgraph server exposes.
+func federationSource(inputs []*ast.Source) (*ast.Source, error) {
+	doc, err := parser.ParseSchemas(inputs...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema: %w: %w", ErrInvalidInput, err)
+	}
+
+	declared := make(map[string]bool)
+	for _, d := range doc.Directives {
+		declared["@"+d.Name] = true
+	}
+	for _, d := range doc.Definitions {
+		declared[d.Name] = true
+	}
+
+	var sb strings.Builder
+	for _, def := range federationDefinitions {
+		if !declared[def.name] {
+			sb.WriteString(def.sdl + "\n")
+		}
+	}
+
+	var (
+		queryType = "Query"
+		fields    = make(map[string]bool)
+		entities  []string
+	)
+
+	for _, schemaDef := range slices.Concat(doc.Schema, doc.SchemaExtension) {
+		for _, op := range schemaDef.OperationTypes {
+			if op.Operation == ast.Query {
+				queryType = op.Type
+			}
+		}
+	}
+
+	for _, d := range slices.Concat(doc.Definitions, doc.Extensions) {
+		if d.Name == queryType {
+			for _, f := range d.Fields {
+				fields[f.Name] = true
+			}
+		}
+		if d.Kind == ast.Object && d.Directives.ForName("key") != nil && !slices.Contains(entities, d.Name) {
+			entities = append(entities, d.Name)
+		}
+	}
+
+	var queryFields []string
+	if !fields["_service"] {
+		queryFields = append(queryFields, "_service: _Service!")
+	}
+	if len(entities) > 0 && !declared["_Entity"] {
+		sb.WriteString("union _Entity = " + strings.Join(entities, " | ") + "\n")
+	}
+	if len(entities) > 0 && !fields["_entities"] {
+		queryFields = append(queryFields, "_entities(representations: [_Any!]!): [_Entity]!")
+	}
+
+	if len(queryFields) > 0 {
+		if !declared[queryType] {
+			sb.WriteString("type " + queryType + " {\n")
+		} else {
+			sb.WriteString("extend type " + queryType + " {\n")
+		}
+		for _, f := range queryFields {
+			sb.WriteString("\t" + f + "\n")
+		}
+		sb.WriteString("}\n")
+	}
+
+	return &ast.Source{Name: "federation.graphqls", Input: sb.String(), BuiltIn: false}, nil
+}
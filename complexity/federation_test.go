@@ -0,0 +1,56 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const federatedSchema = `extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key", "@shareable"])
+
+type Query {
+	me: User
+}
+
+type User @key(fields: "id") {
+	id: ID!
+	name: String! @shareable
+	reviews: [Review!]!
+}
+
+type Review @key(fields: "id") {
+	id: ID!
+	body: String!
+}
+`
+
+func TestRunAnalysisFederation(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"schema.graphqls": federatedSchema,
+		"entities.graphql": `query Entities($representations: [_Any!]!) {
+			_entities(representations: $representations) {
+				... on User { id name }
+				... on Review { body }
+			}
+		}`,
+	})
+
+	if _, err := complexity.RunAnalysis(t.Context(), "*.graphqls", "*.graphql"); err == nil {
+		t.Fatal("RunAnalysis() without federation succeeded, want error")
+	}
+
+	result, err := complexity.RunAnalysis(t.Context(), "*.graphqls", "*.graphql", complexity.WithFederation())
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "entities.graphql", OperationName: "Entities", OperationType: ast.Query, Complexity: 3, FlattenedComplexity: 4, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 4, ResponseNodes: 4},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysis() mismatch (-want +got):\n%s", diff)
+	}
+}
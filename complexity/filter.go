@@ -0,0 +1,95 @@
+package complexity
+
+import "slices"
+
+// FilterOperations returns the results of the named operations, in the order
+// of results, and the names that matched no result.
+func FilterOperations(results []ComplexityAnalysis, names []string) ([]ComplexityAnalysis, []string) {
+	f := NewOperationFilter(names)
+
+	var filtered []ComplexityAnalysis
+	for _, r := range results {
+		if f.Match(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, f.Missing()
+}
+
+// OperationFilter selects the results of named operations one at a time, as
+// they are analysed, keeping track of the names that matched no result, see
+// FilterOperations.
+type OperationFilter struct {
+	names []string
+	found map[string]bool
+}
+
+// NewOperationFilter returns a filter selecting the results of the named
+// operations.
+func NewOperationFilter(names []string) *OperationFilter {
+	found := make(map[string]bool, len(names))
+	for _, name := range names {
+		found[name] = false
+	}
+	return &OperationFilter{names: names, found: found}
+}
+
+// Match reports whether the result is of one of the named operations.
+func (f *OperationFilter) Match(r ComplexityAnalysis) bool {
+	if _, ok := f.found[r.OperationName]; !ok {
+		return false
+	}
+	f.found[r.OperationName] = true
+	return true
+}
+
+// Missing returns the names that matched no result so far, in the order they
+// were given. Names given more than once are reported a single time.
+func (f *OperationFilter) Missing() []string {
+	var missing []string
+	for _, name := range f.names {
+		if !f.found[name] && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// HiddenResults counts the results left out of the output for their low
+// complexity, which summaries still include, see FilterMinComplexity.
+type HiddenResults struct {
+	Count      int
+	Complexity int
+}
+
+// Add counts the hidden result.
+func (h *HiddenResults) Add(r ComplexityAnalysis) {
+	h.Count++
+	h.Complexity = safeAdd(h.Complexity, r.Complexity)
+}
+
+// Hides reports whether the result has a complexity below min and is left
+// out, counting it when it is. Results are filtered one at a time with it as
+// they are analysed, see FilterMinComplexity.
+func (h *HiddenResults) Hides(r ComplexityAnalysis, min int) bool {
+	if r.Complexity >= min {
+		return false
+	}
+	h.Add(r)
+	return true
+}
+
+// FilterMinComplexity returns the results with a complexity of at least min,
+// in the order of results, and counts the results left out.
+func FilterMinComplexity(results []ComplexityAnalysis, min int) ([]ComplexityAnalysis, HiddenResults) {
+	var (
+		filtered []ComplexityAnalysis
+		hidden   HiddenResults
+	)
+	for _, r := range results {
+		if !hidden.Hides(r, min) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, hidden
+}
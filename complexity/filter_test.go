@@ -0,0 +1,115 @@
+package complexity_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilterOperations(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10},
+		{Path: "b.graphql", OperationName: "GetUser", Complexity: 7},
+		{Path: "b.graphql", OperationName: "GetOrder", Complexity: 3},
+	}
+
+	filtered, missing := complexity.FilterOperations(results, []string{"GetUser", "GetOrder", "Missing", "Missing"})
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "b.graphql", OperationName: "GetUser", Complexity: 7},
+		{Path: "b.graphql", OperationName: "GetOrder", Complexity: 3},
+	}
+
+	if diff := cmp.Diff(expected, filtered); diff != "" {
+		t.Errorf("FilterOperations() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Missing"}, missing); diff != "" {
+		t.Errorf("FilterOperations() missing mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOperationFilter(t *testing.T) {
+	f := complexity.NewOperationFilter([]string{"GetUser", "GetOrder"})
+
+	if diff := cmp.Diff([]string{"GetUser", "GetOrder"}, f.Missing()); diff != "" {
+		t.Errorf("Missing() before any result mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, tt := range []struct {
+		name string
+		want bool
+	}{
+		{name: "GetUser", want: true},
+		{name: "ListUsers", want: false},
+		{name: "GetUser", want: true},
+	} {
+		if got := f.Match(complexity.ComplexityAnalysis{OperationName: tt.name}); got != tt.want {
+			t.Errorf("Match(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"GetOrder"}, f.Missing()); diff != "" {
+		t.Errorf("Missing() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilterMinComplexity(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", Complexity: 1},
+		{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10},
+		{Path: "b.graphql", OperationName: "GetOrder", Complexity: 2},
+		{Path: "b.graphql", OperationName: "ListOrders", Complexity: 3},
+	}
+
+	filtered, hidden := complexity.FilterMinComplexity(results, 3)
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10},
+		{Path: "b.graphql", OperationName: "ListOrders", Complexity: 3},
+	}
+	if diff := cmp.Diff(expected, filtered); diff != "" {
+		t.Errorf("FilterMinComplexity() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(complexity.HiddenResults{Count: 2, Complexity: 3}, hidden); diff != "" {
+		t.Errorf("FilterMinComplexity() hidden mismatch (-want +got):\n%s", diff)
+	}
+
+	// The summaries of the filtered results count the hidden results, so
+	// they are the same as without filtering.
+	tests := []struct {
+		format string
+		total  string
+	}{
+		{format: "table", total: "Total: 4 operations, 2 hidden 16"},
+		{format: "markdown", total: "**Total complexity:** 16 across 4 operations, 2 of them hidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f, err := complexity.NewFormatter(tt.format, complexity.FormatOptions{Summary: true, Hidden: &hidden})
+			if err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := f.Format(&buf, filtered); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			// Table columns are padded to line up.
+			got := strings.Join(strings.Fields(buf.String()), " ")
+			if !strings.Contains(got, tt.total) {
+				t.Errorf("Format() output does not contain %q:\n%s", tt.total, got)
+			}
+			for _, name := range []string{"GetUser", "GetOrder"} {
+				if strings.Contains(got, name) {
+					t.Errorf("Format() output contains hidden operation %s:\n%s", name, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package complexity
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Formatter writes analysis results in an output format.
+type Formatter interface {
+	Format(w io.Writer, results []ComplexityAnalysis) error
+}
+
+// StreamFormatter is a Formatter that can also write results one at a time,
+// as they are analysed, see StreamAnalysis.
+type StreamFormatter interface {
+	Formatter
+	Stream(w io.Writer) ResultWriter
+}
+
+// ResultWriter writes results as they are produced. Close writes anything
+// that can only follow the last result, such as a summary.
+type ResultWriter interface {
+	Write(r ComplexityAnalysis) error
+	Close() error
+}
+
+// FormatOptions configures the formatters returned by NewFormatter.
+// Formatters ignore the options they have no use for.
+type FormatOptions struct {
+	// Summary appends the total complexity of all operations.
+	Summary bool
+
+	// Hidden, when set, counts the results left out of the output, which
+	// the Summary still includes. It is read once every result is written.
+	Hidden *HiddenResults
+
+	// GroupByFile nests the operations of each file under it together with
+	// the file's total complexity, see GroupByFile.
+	GroupByFile bool
+
+	// Budget adds each operation's complexity as a percentage of it. Rows
+	// are colored from BudgetWarn and BudgetCritical percent when Color is
+	// set, or from BudgetWarn percent of the Limits without a Budget. Only
+	// the table is colored.
+	Budget         int
+	BudgetWarn     int
+	BudgetCritical int
+	Color          bool
+
+	// Limits highlights the operations exceeding them, in the formats that
+	// can, such as html and a colored table.
+	Limits ComplexityLimits
+}
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]func(FormatOptions) Formatter{
+		"compact": func(FormatOptions) Formatter { return CompactFormatter{} },
+		"html":    func(o FormatOptions) Formatter { return HTMLFormatter{Limits: o.Limits} },
+		"json":    func(o FormatOptions) Formatter { return JSONFormatter{GroupByFile: o.GroupByFile} },
+		"markdown": func(o FormatOptions) Formatter {
+			return MarkdownFormatter{Summary: o.Summary, Hidden: o.Hidden}
+		},
+		"prometheus": func(FormatOptions) Formatter { return PrometheusFormatter{} },
+		"table": func(o FormatOptions) Formatter {
+			return TableFormatter{
+				Summary:        o.Summary,
+				Hidden:         o.Hidden,
+				Budget:         o.Budget,
+				BudgetWarn:     o.BudgetWarn,
+				BudgetCritical: o.BudgetCritical,
+				Limits:         o.Limits,
+				Color:          o.Color,
+			}
+		},
+		"toml": func(o FormatOptions) Formatter { return TOMLFormatter{GroupByFile: o.GroupByFile} },
+		"yaml": func(o FormatOptions) Formatter { return YAMLFormatter{GroupByFile: o.GroupByFile} },
+	}
+)
+
+// RegisterFormatter makes a formatter available to NewFormatter under name,
+// replacing any formatter registered under the same name before.
+func RegisterFormatter(name string, newFormatter func(FormatOptions) Formatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	formatters[name] = newFormatter
+}
+
+// FormatterNames returns the sorted names of the registered formatters.
+func FormatterNames() []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	return slices.Sorted(maps.Keys(formatters))
+}
+
+// NewFormatter returns the formatter registered under name configured with
+// opts.
+func NewFormatter(name string, opts FormatOptions) (Formatter, error) {
+	formattersMu.RLock()
+	newFormatter, ok := formatters[name]
+	formattersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown format %q, valid formats are %s", ErrInvalidInput, name, strings.Join(FormatterNames(), ", "))
+	}
+	return newFormatter(opts), nil
+}
+
+// NewResultWriter returns a writer writing results to w with the formatter.
+// Results are written as they arrive by a StreamFormatter, and all at once on
+// Close by other formatters.
+func NewResultWriter(f Formatter, w io.Writer) ResultWriter {
+	if sf, ok := f.(StreamFormatter); ok {
+		return sf.Stream(w)
+	}
+	return &bufferedWriter{flush: func(results []ComplexityAnalysis) error {
+		return f.Format(w, results)
+	}}
+}
+
+// NewBufferedWriter returns a writer collecting the results and passing all
+// of them to flush on Close, for outputs that need every result, such as per
+// file totals.
+func NewBufferedWriter(flush func(results []ComplexityAnalysis) error) ResultWriter {
+	return &bufferedWriter{flush: flush}
+}
+
+// bufferedWriter collects the results and writes them all on Close.
+type bufferedWriter struct {
+	results []ComplexityAnalysis
+	flush   func(results []ComplexityAnalysis) error
+}
+
+func (b *bufferedWriter) Write(r ComplexityAnalysis) error {
+	b.results = append(b.results, r)
+	return nil
+}
+
+func (b *bufferedWriter) Close() error {
+	return b.flush(b.results)
+}
+
+// formatAll writes the results to a stream of the formatter.
+func formatAll(f StreamFormatter, w io.Writer, results []ComplexityAnalysis) error {
+	stream := f.Stream(w)
+	for _, r := range results {
+		if err := stream.Write(r); err != nil {
+			return err
+		}
+	}
+	return stream.Close()
+}
+
+// MarkdownFormatter writes the results as a Markdown table, see
+// WriteMarkdown. The summary includes the Hidden results.
+type MarkdownFormatter struct {
+	Summary bool
+	Hidden  *HiddenResults
+}
+
+func (f MarkdownFormatter) Format(w io.Writer, results []ComplexityAnalysis) error {
+	return writeMarkdown(w, results, f.Summary, f.Hidden)
+}
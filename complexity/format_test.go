@@ -0,0 +1,236 @@
+package complexity_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+var formatResults = []complexity.ComplexityAnalysis{
+	{Path: "a.graphql", OperationName: "GetUser", OperationType: "query", Complexity: 5, FlattenedComplexity: 3, RootFields: 1, Depth: 2},
+	{Path: "b.graphql", OperationName: "GetOrder", OperationType: "query", Complexity: 7, FlattenedComplexity: 7, RootFields: 2, Depth: 3},
+}
+
+func TestNewFormatterUnknown(t *testing.T) {
+	_, err := complexity.NewFormatter("xml", complexity.FormatOptions{})
+	if !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Fatalf("NewFormatter() error = %v, want %v", err, complexity.ErrInvalidInput)
+	}
+	if !strings.Contains(err.Error(), "json, markdown, prometheus, table, toml, yaml") {
+		t.Errorf("NewFormatter() error = %q, want the valid formats listed", err)
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	complexity.RegisterFormatter("count", func(complexity.FormatOptions) complexity.Formatter {
+		return countFormatter{}
+	})
+
+	f, err := complexity.NewFormatter("count", complexity.FormatOptions{})
+	if err != nil {
+		t.Fatalf("NewFormatter() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	w := complexity.NewResultWriter(f, &buf)
+	for _, r := range formatResults {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if diff := cmp.Diff("2 operations\n", buf.String()); diff != "" {
+		t.Errorf("NewResultWriter() output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type countFormatter struct{}
+
+func (countFormatter) Format(w io.Writer, results []complexity.ComplexityAnalysis) error {
+	_, err := fmt.Fprintf(w, "%d operations\n", len(results))
+	return err
+}
+
+// TestFormatterStream checks that streaming formatters write the same output
+// one result at a time as all at once.
+func TestFormatterStream(t *testing.T) {
+	for _, name := range []string{"json", "table", "yaml"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := complexity.NewFormatter(name, complexity.FormatOptions{Summary: true})
+			if err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+
+			var want bytes.Buffer
+			if err := f.Format(&want, formatResults); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			var got bytes.Buffer
+			w := complexity.NewResultWriter(f, &got)
+			for _, r := range formatResults {
+				if err := w.Write(r); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+				t.Errorf("streamed output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTOMLFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (complexity.TOMLFormatter{}).Format(&buf, formatResults); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	// Decoding into untyped values shows that numbers stay integers.
+	var got map[string][]map[string]any
+	if _, err := toml.Decode(buf.String(), &got); err != nil {
+		t.Fatalf("decoding TOML: %v", err)
+	}
+
+	expected := map[string][]map[string]any{
+		"operation": {
+			{"path": "a.graphql", "operation": "GetUser", "operationType": "query", "complexity": int64(5), "flattenedComplexity": int64(3), "rootFields": int64(1), "depth": int64(2), "arguments": int64(0), "uniqueFields": int64(0), "responseNodes": int64(0)},
+			{"path": "b.graphql", "operation": "GetOrder", "operationType": "query", "complexity": int64(7), "flattenedComplexity": int64(7), "rootFields": int64(2), "depth": int64(3), "arguments": int64(0), "uniqueFields": int64(0), "responseNodes": int64(0)},
+		},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Format() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTOMLFormatterGroupByFile(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (complexity.TOMLFormatter{GroupByFile: true}).Format(&buf, formatResults); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var got struct {
+		Files []complexity.FileGroup `toml:"file"`
+	}
+	if _, err := toml.Decode(buf.String(), &got); err != nil {
+		t.Fatalf("decoding TOML: %v", err)
+	}
+
+	if diff := cmp.Diff(complexity.GroupByFile(formatResults), got.Files); diff != "" {
+		t.Errorf("Format() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompactFormatter(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "b.graphql", OperationName: "ListOrders", Complexity: 30},
+		{Path: "a.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "b.graphql", OperationName: "GetUser", Complexity: 12},
+	}
+
+	var buf bytes.Buffer
+	if err := (complexity.CompactFormatter{}).Format(&buf, results); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	expected := "b.graphql: GetUser=12 ListOrders=30\na.graphql: GetUser=5\n"
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("Format() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTableFormatterColor(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", OperationType: "query", Complexity: 5},
+		{Path: "a.graphql", OperationName: "ListUsers", OperationType: "query", Complexity: 8},
+		{Path: "b.graphql", OperationName: "CreateUser", OperationType: "mutation", Complexity: 12},
+		{Path: "b.graphql", OperationName: "Export", OperationType: "query", Complexity: 12, MaxComplexity: 100},
+	}
+
+	tests := []struct {
+		name     string
+		opts     complexity.FormatOptions
+		expected []string
+	}{
+		{
+			name:     "limits",
+			opts:     complexity.FormatOptions{Color: true, BudgetWarn: 75, Limits: complexity.ComplexityLimits{Default: 10}},
+			expected: []string{"\x1b[32m", "\x1b[33m", "\x1b[31m", "\x1b[32m"},
+		},
+		{
+			name:     "limit of the operation type",
+			opts:     complexity.FormatOptions{Color: true, BudgetWarn: 75, Limits: complexity.ComplexityLimits{Mutation: 20}},
+			expected: []string{"\x1b[39m", "\x1b[39m", "\x1b[32m", "\x1b[32m"},
+		},
+		{
+			name:     "budget",
+			opts:     complexity.FormatOptions{Color: true, Budget: 10, BudgetWarn: 75, BudgetCritical: 100, Limits: complexity.ComplexityLimits{Default: 100}},
+			expected: []string{"\x1b[32m", "\x1b[33m", "\x1b[31m", "\x1b[31m"},
+		},
+		{
+			name:     "without color",
+			opts:     complexity.FormatOptions{BudgetWarn: 75, Limits: complexity.ComplexityLimits{Default: 10}},
+			expected: []string{"", "", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := complexity.NewFormatter("table", tt.opts)
+			if err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := f.Format(&buf, results); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")[1:] {
+				color, _, _ := strings.Cut(line, results[len(got)].Path)
+				got = append(got, color)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("row colors mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatterColorOnlyTable(t *testing.T) {
+	for _, name := range complexity.FormatterNames() {
+		if name == "table" {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			f, err := complexity.NewFormatter(name, complexity.FormatOptions{Color: true, Budget: 10, BudgetWarn: 75, BudgetCritical: 100})
+			if err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := f.Format(&buf, formatResults); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if strings.Contains(buf.String(), "\x1b[") {
+				t.Errorf("Format() output contains ANSI escape codes:\n%q", buf.String())
+			}
+		})
+	}
+}
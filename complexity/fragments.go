@@ -0,0 +1,83 @@
+package complexity
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// FragmentComplexity is the standalone complexity of a fragment used by an
+// operation: the complexity of the fragment's selection set on its type
+// condition, including the fragments it spreads in turn.
+type FragmentComplexity struct {
+	FragmentName string `json:"fragment" yaml:"fragment" toml:"fragment"`
+	Complexity   int    `json:"complexity" yaml:"complexity" toml:"complexity"`
+}
+
+// FragmentDelta is how much the spreads of a fragment add to the flattened
+// complexity of an operation: its flattened complexity minus the flattened
+// complexity without them. Fields the fragment shares with other selections
+// are merged into them, so a fragment whose fields are all selected elsewhere
+// adds nothing.
+type FragmentDelta struct {
+	FragmentName string `json:"fragment" yaml:"fragment" toml:"fragment"`
+	Delta        int    `json:"delta" yaml:"delta" toml:"delta"`
+}
+
+// fragmentDeltas returns the delta of every fragment the operation uses,
+// sorted by descending delta. The flattened complexity of the operation is
+// given, and cost computes it for the operation flattened without a fragment.
+func fragmentDeltas(doc *ast.QueryDocument, op *ast.OperationDefinition, schema *ast.Schema, flattened int, cost func(*ast.OperationDefinition) int) []FragmentDelta {
+	var deltas []FragmentDelta
+	for _, frag := range usedFragments(doc, op.SelectionSet) {
+		f := newFlattener(doc, schema)
+		f.omit = frag.Name
+		deltas = append(deltas, FragmentDelta{
+			FragmentName: frag.Name,
+			Delta:        flattened - cost(flatten(f, op)),
+		})
+	}
+
+	slices.SortStableFunc(deltas, func(a, b FragmentDelta) int {
+		return cmp.Compare(b.Delta, a.Delta)
+	})
+	return deltas
+}
+
+// usedFragments returns the definitions of the fragments spread in the
+// selection set, directly or through other fragments, in the order they are
+// first spread. Each fragment is returned once.
+func usedFragments(doc *ast.QueryDocument, selectionSet ast.SelectionSet) []*ast.FragmentDefinition {
+	var (
+		used []*ast.FragmentDefinition
+		seen = make(map[string]bool)
+		walk func(ast.SelectionSet)
+	)
+
+	walk = func(selectionSet ast.SelectionSet) {
+		for _, selection := range selectionSet {
+			switch s := selection.(type) {
+			case *ast.Field:
+				walk(s.SelectionSet)
+
+			case *ast.InlineFragment:
+				walk(s.SelectionSet)
+
+			case *ast.FragmentSpread:
+				if seen[s.Name] {
+					continue
+				}
+				seen[s.Name] = true
+
+				if def := findFragmentDefinition(doc, s.Name); def != nil {
+					used = append(used, def)
+					walk(def.SelectionSet)
+				}
+			}
+		}
+	}
+
+	walk(selectionSet)
+	return used
+}
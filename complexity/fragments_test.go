@@ -0,0 +1,152 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestAnalyseDocumentFragmentComplexity(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: `
+		type Query {
+			user(id: ID!): User
+		}
+
+		type User {
+			id: ID!
+			name: String!
+			friends: [User!]!
+		}
+	`})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{Name: "query.graphql", Input: `
+		query GetUser {
+			user(id: 1) {
+				...UserFields
+				friends {
+					...FriendFields
+				}
+			}
+		}
+
+		query GetName {
+			user(id: 1) {
+				name
+			}
+		}
+
+		fragment UserFields on User {
+			id
+			...FriendFields
+		}
+
+		fragment FriendFields on User {
+			name
+			friends {
+				id
+			}
+		}
+	`})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithFragmentComplexity())
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	expected := map[string][]complexity.FragmentComplexity{
+		"GetUser": {
+			{FragmentName: "UserFields", Complexity: 4},
+			{FragmentName: "FriendFields", Complexity: 3},
+		},
+		"GetName": nil,
+	}
+
+	got := make(map[string][]complexity.FragmentComplexity)
+	for _, r := range result {
+		got[r.OperationName] = r.Fragments
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("AnalyseDocument() Fragments mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAnalyseDocumentFragmentDeltas(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: `
+		type Query {
+			user(id: ID!): User
+		}
+
+		type User {
+			id: ID!
+			name: String!
+			email: String!
+			friends: [User!]!
+		}
+	`})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{Name: "query.graphql", Input: `
+		query GetUser {
+			user(id: 1) {
+				id
+				name
+				...Profile
+				...Friends
+				...ID
+			}
+		}
+
+		fragment Profile on User {
+			id
+			name
+			email
+		}
+
+		fragment Friends on User {
+			friends {
+				...ID
+				name
+			}
+		}
+
+		fragment ID on User {
+			id
+		}
+	`})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithFragmentDeltas())
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	// Profile only adds email, and ID only adds the id of friends as the
+	// operation selects the id of the user itself.
+	expected := []complexity.FragmentDelta{
+		{FragmentName: "Friends", Delta: 3},
+		{FragmentName: "Profile", Delta: 1},
+		{FragmentName: "ID", Delta: 1},
+	}
+
+	if diff := cmp.Diff(expected, result[0].FragmentDeltas); diff != "" {
+		t.Errorf("AnalyseDocument() FragmentDeltas mismatch (-want +got):\n%s", diff)
+	}
+	if got := result[0].FlattenedComplexity; got != 7 {
+		t.Errorf("AnalyseDocument() FlattenedComplexity = %d, want 7", got)
+	}
+}
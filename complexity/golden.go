@@ -0,0 +1,157 @@
+package complexity
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GoldenExt is the extension of golden files. The golden file of a document
+// is named after the document's path with the extension appended, such as
+// queries/user.graphql.golden.
+const GoldenExt = ".golden"
+
+// RuleGolden is the rule name used when reporting operations whose complexity
+// differs from their golden file, see CheckGolden.
+const RuleGolden = "golden"
+
+// ReadGolden reads the golden files below dir, see ReadGoldenFS.
+func ReadGolden(dir string) ([]ComplexityAnalysis, error) {
+	return ReadGoldenFS(os.DirFS(dir))
+}
+
+// ReadGoldenFS reads the golden files in fsys, returning the path, operation
+// name and complexity of every operation they record. Each line of a golden
+// file holds the name and complexity of one operation of its document,
+// separated by a space:
+//
+//	GetUser 5
+//	ListUsers 20
+func ReadGoldenFS(fsys fs.FS) ([]ComplexityAnalysis, error) {
+	var golden []ComplexityAnalysis
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(name) != GoldenExt {
+			return nil
+		}
+
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		docPath := strings.TrimSuffix(name, GoldenExt)
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 0 {
+				continue
+			}
+
+			var complexity int
+			if len(fields) == 2 {
+				complexity, err = strconv.Atoi(fields[1])
+			}
+			if len(fields) != 2 || err != nil {
+				return fmt.Errorf("%w: %s:%d: golden line must be an operation name and its complexity, got %q", ErrInvalidInput, name, line, scanner.Text())
+			}
+			golden = append(golden, ComplexityAnalysis{Path: docPath, OperationName: fields[0], Complexity: complexity})
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading golden files: %w", err)
+	}
+	return golden, nil
+}
+
+// WriteGolden replaces the golden files below dir with those of the results,
+// one per document with its operations sorted by name. Golden files of
+// documents without results are removed.
+func WriteGolden(dir string, results []ComplexityAnalysis) error {
+	byPath := make(map[string][]ComplexityAnalysis)
+	for _, r := range results {
+		byPath[r.Path] = append(byPath[r.Path], r)
+	}
+
+	// Stale golden files would report their operations as removed.
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(name) != GoldenExt {
+			return err
+		}
+		return os.Remove(name)
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("removing golden files: %w", err)
+	}
+
+	for docPath, ops := range byPath {
+		slices.SortFunc(ops, func(a, b ComplexityAnalysis) int {
+			return cmp.Compare(a.OperationName, b.OperationName)
+		})
+
+		var b strings.Builder
+		for _, op := range ops {
+			fmt.Fprintf(&b, "%s %d\n", op.OperationName, op.Complexity)
+		}
+
+		name := filepath.Join(dir, filepath.FromSlash(docPath)+GoldenExt)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return fmt.Errorf("writing golden file: %w", err)
+		}
+		if err := os.WriteFile(name, []byte(b.String()), 0o644); err != nil {
+			return fmt.Errorf("writing golden file: %w", err)
+		}
+	}
+	return nil
+}
+
+// CheckGolden reports every operation whose complexity differs from the
+// golden results, matched by path and operation name, and every operation
+// added to or removed from them.
+func CheckGolden(golden, results []ComplexityAnalysis) []Violation {
+	positions := make(map[operationKey]ComplexityAnalysis, len(results))
+	for _, r := range results {
+		positions[operationKey{r.Path, r.OperationName}] = r
+	}
+
+	var violations []Violation
+	for _, change := range Compare(golden, results) {
+		r := positions[operationKey{change.Path, change.OperationName}]
+		v := Violation{
+			Path:          change.Path,
+			Line:          r.Line,
+			Column:        r.Column,
+			OperationName: change.OperationName,
+			Rule:          RuleGolden,
+		}
+
+		switch change.Status {
+		case ChangeChanged:
+			v.Subject = "complexity"
+			v.Message = fmt.Sprintf("changed from %d to %d (%+d)", change.Before, change.After, change.Delta)
+		case ChangeAdded:
+			v.Subject = "operation"
+			v.Message = fmt.Sprintf("was added with complexity %d", change.After)
+		case ChangeRemoved:
+			v.Subject = "operation"
+			v.Message = fmt.Sprintf("was removed, its golden complexity is %d", change.Before)
+		default:
+			continue
+		}
+
+		violations = append(violations, v)
+	}
+	return violations
+}
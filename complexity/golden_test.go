@@ -0,0 +1,84 @@
+package complexity_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteGolden(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "old.graphql.golden")
+	if err := os.WriteFile(stale, []byte("GetOld 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []complexity.ComplexityAnalysis{
+		{Path: "queries/user.graphql", OperationName: "ListUsers", Complexity: 20, Line: 3},
+		{Path: "queries/user.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "order.graphql", OperationName: "GetOrder", Complexity: 7},
+	}
+	if err := complexity.WriteGolden(dir, results); err != nil {
+		t.Fatalf("WriteGolden() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "queries", "user.graphql.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("GetUser 5\nListUsers 20\n", string(data)); diff != "" {
+		t.Errorf("golden file mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(stale); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("stale golden file was not removed, stat error = %v", err)
+	}
+
+	golden, err := complexity.ReadGolden(dir)
+	if err != nil {
+		t.Fatalf("ReadGolden() error = %v", err)
+	}
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "order.graphql", OperationName: "GetOrder", Complexity: 7},
+		{Path: "queries/user.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "queries/user.graphql", OperationName: "ListUsers", Complexity: 20},
+	}
+	if diff := cmp.Diff(expected, golden); diff != "" {
+		t.Errorf("ReadGolden() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadGoldenInvalid(t *testing.T) {
+	fsys := fstest.MapFS{
+		"user.graphql.golden": {Data: []byte("GetUser 5\nListUsers many\n")},
+	}
+	if _, err := complexity.ReadGoldenFS(fsys); !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Errorf("ReadGoldenFS() error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestCheckGolden(t *testing.T) {
+	golden := []complexity.ComplexityAnalysis{
+		{Path: "user.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "user.graphql", OperationName: "ListUsers", Complexity: 20},
+		{Path: "user.graphql", OperationName: "DeleteUser", Complexity: 3},
+	}
+	results := []complexity.ComplexityAnalysis{
+		{Path: "user.graphql", OperationName: "GetUser", Complexity: 5, Line: 1, Column: 1},
+		{Path: "user.graphql", OperationName: "ListUsers", Complexity: 24, Line: 5, Column: 1},
+		{Path: "user.graphql", OperationName: "CreateUser", Complexity: 4, Line: 9, Column: 1},
+	}
+
+	expected := []complexity.Violation{
+		{Path: "user.graphql", OperationName: "ListUsers", Rule: complexity.RuleGolden, Subject: "complexity", Message: "changed from 20 to 24 (+4)", Line: 5, Column: 1},
+		{Path: "user.graphql", OperationName: "DeleteUser", Rule: complexity.RuleGolden, Subject: "operation", Message: "was removed, its golden complexity is 3"},
+		{Path: "user.graphql", OperationName: "CreateUser", Rule: complexity.RuleGolden, Subject: "operation", Message: "was added with complexity 4", Line: 9, Column: 1},
+	}
+	if diff := cmp.Diff(expected, complexity.CheckGolden(golden, results)); diff != "" {
+		t.Errorf("CheckGolden() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,32 @@
+package complexity
+
+// FileGroup holds the results of the operations of a single file.
+type FileGroup struct {
+	Path       string               `json:"path" yaml:"path" toml:"path"`
+	Operations []ComplexityAnalysis `json:"operations" yaml:"operations" toml:"operations"`
+	Total      int                  `json:"total" yaml:"total" toml:"total"`
+}
+
+// GroupByFile groups the results by file, summing the complexity of the
+// operations of each file. Files keep the order in which they first appear in
+// results.
+func GroupByFile(results []ComplexityAnalysis) []FileGroup {
+	var (
+		groups  []FileGroup
+		indexes = make(map[string]int)
+	)
+
+	for _, r := range results {
+		i, ok := indexes[r.Path]
+		if !ok {
+			i = len(groups)
+			indexes[r.Path] = i
+			groups = append(groups, FileGroup{Path: r.Path})
+		}
+
+		groups[i].Operations = append(groups[i].Operations, r)
+		groups[i].Total = safeAdd(groups[i].Total, r.Complexity)
+	}
+
+	return groups
+}
@@ -0,0 +1,122 @@
+package complexity
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+//go:embed html.tmpl
+var htmlTemplate string
+
+var reportTemplate = template.Must(template.New("report").Parse(htmlTemplate))
+
+// Layout of the bar chart of the HTML report, in pixels.
+const (
+	chartBarWidth  = 500
+	chartBarHeight = 14
+	chartRowHeight = 36
+	chartMargin    = 80
+)
+
+// HTMLFormatter writes the results as a standalone HTML report, see
+// WriteHTML.
+type HTMLFormatter struct {
+	Limits ComplexityLimits
+}
+
+func (f HTMLFormatter) Format(w io.Writer, results []ComplexityAnalysis) error {
+	return WriteHTML(w, results, f.Limits)
+}
+
+type htmlReport struct {
+	Operations int
+	Files      int
+	Total      int
+	Max        int
+	Mean       string
+	Exceeded   int
+	Rows       []htmlRow
+	Chart      htmlChart
+}
+
+type htmlRow struct {
+	ComplexityAnalysis
+	Limit    int
+	Exceeded bool
+}
+
+type htmlChart struct {
+	Width     int
+	Height    int
+	BarHeight int
+	Bars      []htmlBar
+}
+
+type htmlBar struct {
+	Label      string
+	Complexity int
+	Exceeded   bool
+	Width      int
+	LabelY     int
+	BarY       int
+	ValueX     int
+	ValueY     int
+}
+
+// WriteHTML renders the results as a standalone HTML page without external
+// assets: summary statistics, a table of the operations that sorts by the
+// column clicked and a bar chart of their complexity. Operations exceeding the
+// limits, or the limit of their MaxComplexityHint, are highlighted.
+func WriteHTML(w io.Writer, results []ComplexityAnalysis, limits ComplexityLimits) error {
+	report := htmlReport{
+		Operations: len(results),
+		Files:      len(GroupByFile(results)),
+		Mean:       "0",
+		Chart: htmlChart{
+			Width:     chartBarWidth + chartMargin,
+			Height:    len(results) * chartRowHeight,
+			BarHeight: chartBarHeight,
+		},
+	}
+
+	for _, r := range results {
+		limit, _ := limits.limit(r.OperationType)
+		if r.MaxComplexity > 0 {
+			limit = r.MaxComplexity
+		}
+
+		row := htmlRow{ComplexityAnalysis: r, Limit: limit, Exceeded: limit > 0 && r.Complexity > limit}
+		if row.Exceeded {
+			report.Exceeded++
+		}
+		report.Rows = append(report.Rows, row)
+		report.Total = safeAdd(report.Total, r.Complexity)
+		report.Max = max(report.Max, r.Complexity)
+	}
+	if len(results) > 0 {
+		report.Mean = fmt.Sprintf("%.1f", float64(report.Total)/float64(len(results)))
+	}
+
+	for i, row := range report.Rows {
+		width := 0
+		if report.Max > 0 {
+			width = int(float64(row.Complexity) / float64(report.Max) * chartBarWidth)
+		}
+
+		y := i * chartRowHeight
+		report.Chart.Bars = append(report.Chart.Bars, htmlBar{
+			Label:      row.Path + ": " + row.OperationName,
+			Complexity: row.Complexity,
+			Exceeded:   row.Exceeded,
+			Width:      width,
+			LabelY:     y + 12,
+			BarY:       y + 16,
+			ValueX:     width + 6,
+			ValueY:     y + 16 + chartBarHeight - 2,
+		})
+	}
+
+	return reportTemplate.Execute(w, report)
+}
@@ -0,0 +1,65 @@
+package complexity_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestWriteHTML(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", OperationType: ast.Query, Complexity: 5, FlattenedComplexity: 3, Depth: 2},
+		{Path: "a.graphql", OperationName: "ListUsers", OperationType: ast.Query, Complexity: 20, FlattenedComplexity: 20, Depth: 3},
+		{Path: "b.graphql", OperationName: "CreateUser", OperationType: ast.Mutation, Complexity: 4, FlattenedComplexity: 4, Depth: 2, MaxComplexity: 3},
+		{Path: "<b>.graphql", OperationName: "Escaped", OperationType: ast.Query, Complexity: 1, FlattenedComplexity: 1, Depth: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := complexity.WriteHTML(&buf, results, complexity.ComplexityLimits{Default: 10}); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<dt>Operations</dt><dd>4</dd>",
+		"<dt>Files</dt><dd>3</dd>",
+		"<dt>Total complexity</dt><dd>30</dd>",
+		"<dt>Highest complexity</dt><dd>20</dd>",
+		"<dt>Mean complexity</dt><dd>7.5</dd>",
+		"<dt>Over limit</dt><dd>2</dd>",
+		`<tr><td>a.graphql</td><td>GetUser</td><td>query</td><td class="number">5</td>`,
+		`<tr class="exceeded"><td>a.graphql</td><td>ListUsers</td>`,
+		`<tr class="exceeded"><td>b.graphql</td><td>CreateUser</td><td>mutation</td><td class="number">4</td><td class="number">4</td><td class="number">2</td><td class="number">3</td></tr>`,
+		`<rect x="0" y="52" width="500" height="14" class="exceeded">`,
+		`<rect x="0" y="16" width="125" height="14">`,
+		"&lt;b&gt;.graphql",
+		"<script>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteHTML() output does not contain %q", want)
+		}
+	}
+
+	for _, external := range []string{"<link", "src=", "href="} {
+		if strings.Contains(got, external) {
+			t.Errorf("WriteHTML() output refers to an external asset with %q", external)
+		}
+	}
+}
+
+func TestWriteHTMLEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := complexity.WriteHTML(&buf, nil, complexity.ComplexityLimits{}); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+
+	for _, want := range []string{"<dt>Operations</dt><dd>0</dd>", "<dt>Mean complexity</dt><dd>0</dd>", `height="0"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteHTML() output does not contain %q", want)
+		}
+	}
+}
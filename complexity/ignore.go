@@ -0,0 +1,194 @@
+package complexity
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFile is the name of the file listing gitignore style patterns of
+// schema and document files to leave out of the analysis.
+const IgnoreFile = ".gqlignore"
+
+// Ignore matches paths against gitignore style patterns. Patterns support
+// "*", "?", "**" and negation with a leading "!". The last pattern matching a
+// path decides whether it is ignored. As with git, files inside an ignored
+// directory stay ignored, as negated patterns cannot re-include them.
+type Ignore struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ParseIgnore parses gitignore style patterns, one per line. Blank lines and
+// lines starting with "#" are skipped.
+func ParseIgnore(r io.Reader) (*Ignore, error) {
+	var ignore Ignore
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if pattern, ok := strings.CutPrefix(line, "!"); ok {
+			rule.negate = true
+			line = pattern
+		}
+		if pattern, ok := strings.CutSuffix(line, "/"); ok {
+			rule.dirOnly = true
+			line = pattern
+		}
+
+		re, err := regexp.Compile(ignorePatternRegexp(line))
+		if err != nil {
+			return nil, fmt.Errorf("parsing ignore pattern %q: %w", line, err)
+		}
+		rule.re = re
+
+		ignore.rules = append(ignore.rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading ignore patterns: %w", err)
+	}
+
+	return &ignore, nil
+}
+
+// Match reports whether the slash separated path is ignored. A pattern
+// matching a directory matches every path inside it.
+func (i *Ignore) Match(name string) bool {
+	if i == nil {
+		return false
+	}
+
+	name = path.Clean(name)
+
+	// Git does not look inside ignored directories, so the first ignored
+	// directory on the path decides.
+	for end, c := range name {
+		if c == '/' && end > 0 && i.ignored(name[:end], true) {
+			return true
+		}
+	}
+	return i.ignored(name, false)
+}
+
+// ignored reports whether the patterns ignore the path itself, without
+// looking at the directories it is in.
+func (i *Ignore) ignored(name string, dir bool) bool {
+	var ignored bool
+	for _, rule := range i.rules {
+		if (dir || !rule.dirOnly) && rule.re.MatchString(name) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// Filter returns the paths that are not ignored.
+func (i *Ignore) Filter(names []string) []string {
+	var kept []string
+	for _, name := range names {
+		if !i.Match(name) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// ignorePatternRegexp translates a gitignore style pattern into a regular
+// expression. Patterns without a slash match at any depth, while patterns
+// containing one are relative to the directory of the ignore file.
+func ignorePatternRegexp(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	if !strings.Contains(pattern, "/") {
+		sb.WriteString("(?:.*/)?")
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + negated
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// loadIgnore reads the ignore file from the root of fsys followed by the
+// given patterns, which take precedence. A missing file ignores nothing.
+func loadIgnore(fsys fs.FS, patterns []string) (*Ignore, error) {
+	ignore, err := loadIgnoreFile(fsys)
+	if err != nil || len(patterns) == 0 {
+		return ignore, err
+	}
+
+	extra, err := ParseIgnore(strings.NewReader(strings.Join(patterns, "\n")))
+	if err != nil {
+		return nil, fmt.Errorf("ignore patterns: %w: %w", ErrInvalidInput, err)
+	}
+	if ignore == nil {
+		return extra, nil
+	}
+
+	ignore.rules = append(ignore.rules, extra.rules...)
+	return ignore, nil
+}
+
+// loadIgnoreFile reads the ignore file from the root of fsys. A missing file
+// ignores nothing.
+func loadIgnoreFile(fsys fs.FS) (*Ignore, error) {
+	f, err := fsys.Open(IgnoreFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", IgnoreFile, err)
+	}
+	defer f.Close()
+
+	ignore, err := ParseIgnore(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", IgnoreFile, ErrInvalidInput, err)
+	}
+	return ignore, nil
+}
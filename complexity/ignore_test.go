@@ -0,0 +1,112 @@
+package complexity_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIgnore(t *testing.T) {
+	ignore, err := complexity.ParseIgnore(strings.NewReader(`# generated by codegen
+generated/
+**/*.generated.graphql
+!keep.generated.graphql
+/root.graphql
+docs/**/draft-*.graphql
+`))
+	if err != nil {
+		t.Fatalf("failed to parse ignore patterns: %v", err)
+	}
+
+	tests := map[string]bool{
+		"user.graphql":                        false,
+		"generated/user.graphql":              true,
+		"api/generated/nested/user.graphql":   true,
+		"user.generated.graphql":              true,
+		"api/user.generated.graphql":          true,
+		"keep.generated.graphql":              false,
+		"api/keep.generated.graphql":          false,
+		"root.graphql":                        true,
+		"api/root.graphql":                    false,
+		"docs/draft-user.graphql":             true,
+		"docs/v1/beta/draft-user.graphql":     true,
+		"docs/v1/beta/published-user.graphql": false,
+	}
+
+	for name, expected := range tests {
+		if got := ignore.Match(name); got != expected {
+			t.Errorf("Match(%q) = %v, want %v", name, got, expected)
+		}
+	}
+}
+
+func TestIgnoreNegationInIgnoredDirectory(t *testing.T) {
+	ignore, err := complexity.ParseIgnore(strings.NewReader(`generated/
+!generated/keep.graphql
+vendor/*
+!vendor/api/
+`))
+	if err != nil {
+		t.Fatalf("failed to parse ignore patterns: %v", err)
+	}
+
+	// As with git, a file cannot be re-included when a directory it is in
+	// is ignored, while a re-included directory is no longer ignored.
+	tests := map[string]bool{
+		"generated/user.graphql":     true,
+		"generated/keep.graphql":     true,
+		"vendor/user.graphql":        true,
+		"vendor/other/user.graphql":  true,
+		"vendor/api/user.graphql":    false,
+		"vendor/api/v1/user.graphql": false,
+	}
+
+	for name, expected := range tests {
+		if got := ignore.Match(name); got != expected {
+			t.Errorf("Match(%q) = %v, want %v", name, got, expected)
+		}
+	}
+}
+
+func TestRunAnalysisIgnoreFile(t *testing.T) {
+	writeFiles(t, map[string]string{
+		complexity.IgnoreFile:    "generated/\n",
+		"schema.graphqls":        schema,
+		"user.graphql":           `query GetUser { user(id: 1) { id } }`,
+		"generated/user.graphql": `query Generated { user(id: 1) { id } }`,
+	})
+
+	tests := []struct {
+		name     string
+		opts     []complexity.Option
+		expected []string
+	}{
+		{name: "ignore", expected: []string{"GetUser"}},
+		{name: "no ignore", opts: []complexity.Option{complexity.WithoutIgnoreFile()}, expected: []string{"Generated", "GetUser"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := complexity.RunAnalysis(t.Context(), "*.graphqls", "*/*.graphql", tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to run analysis: %v", err)
+			}
+
+			root, err := complexity.RunAnalysis(t.Context(), "*.graphqls", "*.graphql", tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to run analysis: %v", err)
+			}
+
+			var names []string
+			for _, r := range append(result, root...) {
+				names = append(names, r.OperationName)
+			}
+
+			if diff := cmp.Diff(tt.expected, names); diff != "" {
+				t.Errorf("RunAnalysis() operations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package complexity
+
+import (
+	"context"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// incrementalDefinitions are the directives of incremental delivery missing
+// from gqlparser's prelude, which declares @defer but not @stream.
+var incrementalDefinitions = []directiveDefinition{
+	{"@stream", `directive @stream(if: Boolean! = true, label: String, initialCount: Int = 0) on FIELD`},
+}
+
+// isDeferred reports whether a fragment with the directives is deferred to a
+// later payload, which it is unless @defer is missing or disabled with
+// "if: false".
+func isDeferred(directives ast.DirectiveList, vars Variables) bool {
+	return enabled(directives.ForName("defer"), vars)
+}
+
+// streamedItems returns the number of items of a streamed list field sent in
+// the initial payload, and whether the field is streamed.
+func streamedItems(field *ast.Field, vars Variables) (int, bool) {
+	d := field.Directives.ForName("stream")
+	if !enabled(d, vars) {
+		return 0, false
+	}
+
+	arg := d.Arguments.ForName("initialCount")
+	if arg == nil {
+		return 0, true
+	}
+	n, _ := vars.Int(arg.Value)
+	return max(n, 0), true
+}
+
+// enabled reports whether the directive is present and not disabled by its
+// "if" argument.
+func enabled(d *ast.Directive, vars Variables) bool {
+	if d == nil {
+		return false
+	}
+
+	arg := d.Arguments.ForName("if")
+	if arg == nil {
+		return true
+	}
+	b, ok := vars.Bool(arg.Value)
+	return !ok || b
+}
+
+// usesIncrementalDelivery reports whether the selection set, or a fragment it
+// spreads, uses @defer or @stream.
+func usesIncrementalDelivery(doc *ast.QueryDocument, selectionSet ast.SelectionSet) bool {
+	for _, selection := range selectionSet {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.Directives.ForName("stream") != nil || usesIncrementalDelivery(doc, s.SelectionSet) {
+				return true
+			}
+		case *ast.InlineFragment:
+			if s.Directives.ForName("defer") != nil || usesIncrementalDelivery(doc, s.SelectionSet) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if s.Directives.ForName("defer") != nil {
+				return true
+			}
+		}
+	}
+
+	for _, frag := range usedFragments(doc, selectionSet) {
+		if usesIncrementalDelivery(doc, frag.SelectionSet) {
+			return true
+		}
+	}
+	return false
+}
+
+type streamedItemsKey struct{}
+
+// withStreamedItems returns a context telling the complexity function that
+// only the first n items of the list field it is called for are counted.
+func withStreamedItems(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, streamedItemsKey{}, n)
+}
+
+// streamedMultiplier limits the list multiplier of a field to the number of
+// items set by withStreamedItems, if any.
+func streamedMultiplier(ctx context.Context, multiplier int) int {
+	if n, ok := ctx.Value(streamedItemsKey{}).(int); ok {
+		return min(multiplier, n)
+	}
+	return multiplier
+}
+
+// isStreamed reports whether withStreamedItems limits the items of the field
+// the complexity function is called for.
+func isStreamed(ctx context.Context) bool {
+	_, ok := ctx.Value(streamedItemsKey{}).(int)
+	return ok
+}
@@ -0,0 +1,112 @@
+package complexity_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunAnalysisFSIncrementalDelivery(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(`
+			type Query {
+				user(id: ID!): User
+			}
+
+			type User {
+				id: ID!
+				name: String!
+				friends(first: Int): [User!]!
+			}
+		`)},
+		"query.graphql": {Data: []byte(`
+			query Deferred {
+				user(id: 1) {
+					id
+					...Details @defer
+				}
+			}
+
+			query NotDeferred {
+				user(id: 1) {
+					id
+					...Details @defer(if: false)
+				}
+			}
+
+			query Streamed {
+				user(id: 1) {
+					friends(first: 10) @stream(initialCount: 2) {
+						id
+					}
+				}
+			}
+
+			query StreamedNone {
+				user(id: 1) {
+					friends(first: 10) @stream(initialCount: 0) {
+						id
+						name
+					}
+				}
+			}
+
+			query Plain {
+				user(id: 1) {
+					id
+				}
+			}
+
+			fragment Details on User {
+				name
+				friends {
+					id
+				}
+			}
+		`)},
+	}
+
+	result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithListMultiplierArgs("first"))
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	type complexities struct {
+		Complexity, Initial int
+	}
+
+	expected := map[string]complexities{
+		"Deferred":     {Complexity: 5, Initial: 2},
+		"NotDeferred":  {Complexity: 5, Initial: 5},
+		"Streamed":     {Complexity: 12, Initial: 4},
+		"StreamedNone": {Complexity: 22, Initial: 2},
+		"Plain":        {Complexity: 2},
+	}
+
+	got := make(map[string]complexities)
+	for _, r := range result {
+		got[r.OperationName] = complexities{Complexity: r.Complexity, Initial: r.InitialComplexity}
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("RunAnalysisFS() complexities mismatch (-want +got):\n%s", diff)
+	}
+
+	// A depth decay of 1 leaves deeper fields as expensive, so the decayed
+	// complexities must be the same.
+	decayed, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithListMultiplierArgs("first"), complexity.WithDepthDecay(1))
+	if err != nil {
+		t.Fatalf("failed to run analysis with depth decay: %v", err)
+	}
+
+	gotDecayed := make(map[string]complexities)
+	for _, r := range decayed {
+		gotDecayed[r.OperationName] = complexities{Complexity: r.Complexity, Initial: r.InitialComplexity}
+	}
+
+	if diff := cmp.Diff(expected, gotDecayed); diff != "" {
+		t.Errorf("RunAnalysisFS() decayed complexities mismatch (-want +got):\n%s", diff)
+	}
+}
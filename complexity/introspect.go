@@ -0,0 +1,323 @@
+package complexity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var (
+	// ErrSchemaUnreachable is wrapped by errors fetching a schema from a URL
+	// that did not answer, or answered with an unsuccessful HTTP status.
+	ErrSchemaUnreachable = errors.New("schema endpoint unreachable")
+
+	// ErrIntrospectionDisabled is wrapped by errors fetching a schema from a
+	// server that answered but refused the introspection query.
+	ErrIntrospectionDisabled = errors.New("introspection disabled")
+)
+
+// retryBackoff is the delay before the first retry of a schema fetch. Each
+// following retry waits twice as long as the one before.
+const retryBackoff = 200 * time.Millisecond
+
+// introspectionQuery asks for the types and directives needed to rebuild the
+// schema as SDL.
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types {
+      kind
+      name
+      fields(includeDeprecated: true) { name args { ...InputValue } type { ...TypeRef } }
+      inputFields { ...InputValue }
+      interfaces { ...TypeRef }
+      enumValues(includeDeprecated: true) { name }
+      possibleTypes { ...TypeRef }
+    }
+    directives { name locations args { ...InputValue } }
+  }
+}
+
+fragment InputValue on __InputValue {
+  name
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
+
+// isURL reports whether the schema entry is a URL to introspect rather than a
+// glob.
+func isURL(entry string) bool {
+	return strings.HasPrefix(entry, "http://") || strings.HasPrefix(entry, "https://")
+}
+
+// fetchSchema introspects the GraphQL server at url and returns its schema as
+// SDL. Servers refusing introspection are not retried.
+func fetchSchema(ctx context.Context, url string, cfg Config) (*ast.Source, error) {
+	sdl, err := withRetries(ctx, cfg, func(ctx context.Context) (string, error) {
+		return introspect(ctx, url, cfg.SchemaTimeout)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching schema %s: %w", url, err)
+	}
+	return &ast.Source{Name: url, Input: sdl}, nil
+}
+
+// withRetries calls fetch until it succeeds. Each attempt is limited to
+// cfg.SchemaTimeout by fetch, attempts failing with ErrSchemaUnreachable are
+// retried up to cfg.SchemaRetries times with exponential backoff.
+func withRetries(ctx context.Context, cfg Config, fetch func(context.Context) (string, error)) (string, error) {
+	for attempt := 0; ; attempt++ {
+		sdl, err := fetch(ctx)
+		if err == nil {
+			return sdl, nil
+		}
+		if !errors.Is(err, ErrSchemaUnreachable) || attempt >= cfg.SchemaRetries || ctx.Err() != nil {
+			return "", err
+		}
+
+		timer := time.NewTimer(retryBackoff << attempt)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// introspect sends the introspection query to url once.
+func introspect(ctx context.Context, url string, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	body, err := json.Marshal(map[string]string{"query": introspectionQuery})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSchemaUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: reading response: %w", ErrSchemaUnreachable, err)
+	}
+
+	var result struct {
+		Data struct {
+			Schema *introspectionSchema `json:"__schema"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%w: %s", ErrSchemaUnreachable, resp.Status)
+		}
+		return "", fmt.Errorf("%w: decoding response: %w", ErrInvalidInput, err)
+	}
+
+	// Servers refusing introspection answer with GraphQL errors, often with a
+	// 400 status, rather than failing the request.
+	if result.Data.Schema == nil {
+		if len(result.Errors) == 0 {
+			if resp.StatusCode != http.StatusOK {
+				return "", fmt.Errorf("%w: %s", ErrSchemaUnreachable, resp.Status)
+			}
+			return "", fmt.Errorf("%w: response has no schema", ErrIntrospectionDisabled)
+		}
+
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+		return "", fmt.Errorf("%w: %s", ErrIntrospectionDisabled, strings.Join(messages, "; "))
+	}
+
+	return result.Data.Schema.sdl(), nil
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionTypeRef    `json:"queryType"`
+	MutationType     *introspectionTypeRef    `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef    `json:"subscriptionType"`
+	Types            []introspectionType      `json:"types"`
+	Directives       []introspectionDirective `json:"directives"`
+}
+
+type introspectionType struct {
+	Kind          string                    `json:"kind"`
+	Name          string                    `json:"name"`
+	Fields        []introspectionField      `json:"fields"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+	Interfaces    []introspectionTypeRef    `json:"interfaces"`
+	EnumValues    []struct{ Name string }   `json:"enumValues"`
+	PossibleTypes []introspectionTypeRef    `json:"possibleTypes"`
+}
+
+type introspectionField struct {
+	Name string                    `json:"name"`
+	Args []introspectionInputValue `json:"args"`
+	Type introspectionTypeRef      `json:"type"`
+}
+
+type introspectionInputValue struct {
+	Name         string               `json:"name"`
+	Type         introspectionTypeRef `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+type introspectionDirective struct {
+	Name      string                    `json:"name"`
+	Locations []string                  `json:"locations"`
+	Args      []introspectionInputValue `json:"args"`
+}
+
+// builtInTypes and builtInDirectives are declared by the gqlparser prelude.
+var (
+	builtInTypes      = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+	builtInDirectives = map[string]bool{"skip": true, "include": true, "deprecated": true, "specifiedBy": true, "defer": true, "oneOf": true}
+)
+
+// sdl renders the introspected schema as SDL.
+func (s *introspectionSchema) sdl() string {
+	var sb strings.Builder
+
+	sb.WriteString("schema {\n")
+	if s.QueryType != nil {
+		fmt.Fprintf(&sb, "  query: %s\n", s.QueryType.Name)
+	}
+	if s.MutationType != nil {
+		fmt.Fprintf(&sb, "  mutation: %s\n", s.MutationType.Name)
+	}
+	if s.SubscriptionType != nil {
+		fmt.Fprintf(&sb, "  subscription: %s\n", s.SubscriptionType.Name)
+	}
+	sb.WriteString("}\n")
+
+	for _, d := range s.Directives {
+		if builtInDirectives[d.Name] {
+			continue
+		}
+		fmt.Fprintf(&sb, "\ndirective @%s%s on %s\n", d.Name, argumentsSDL(d.Args), strings.Join(d.Locations, " | "))
+	}
+
+	for _, t := range s.Types {
+		if strings.HasPrefix(t.Name, "__") || builtInTypes[t.Name] {
+			continue
+		}
+
+		sb.WriteString("\n")
+		switch t.Kind {
+		case "SCALAR":
+			fmt.Fprintf(&sb, "scalar %s\n", t.Name)
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if t.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			fmt.Fprintf(&sb, "%s %s", keyword, t.Name)
+			if len(t.Interfaces) > 0 {
+				names := make([]string, 0, len(t.Interfaces))
+				for _, i := range t.Interfaces {
+					names = append(names, i.Name)
+				}
+				fmt.Fprintf(&sb, " implements %s", strings.Join(names, " & "))
+			}
+			sb.WriteString(" {\n")
+			for _, f := range t.Fields {
+				fmt.Fprintf(&sb, "  %s%s: %s\n", f.Name, argumentsSDL(f.Args), f.Type.sdl())
+			}
+			sb.WriteString("}\n")
+		case "UNION":
+			names := make([]string, 0, len(t.PossibleTypes))
+			for _, p := range t.PossibleTypes {
+				names = append(names, p.Name)
+			}
+			fmt.Fprintf(&sb, "union %s = %s\n", t.Name, strings.Join(names, " | "))
+		case "ENUM":
+			fmt.Fprintf(&sb, "enum %s {\n", t.Name)
+			for _, v := range t.EnumValues {
+				fmt.Fprintf(&sb, "  %s\n", v.Name)
+			}
+			sb.WriteString("}\n")
+		case "INPUT_OBJECT":
+			fmt.Fprintf(&sb, "input %s {\n", t.Name)
+			for _, f := range t.InputFields {
+				fmt.Fprintf(&sb, "  %s\n", f.sdl())
+			}
+			sb.WriteString("}\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// argumentsSDL renders an argument list, or nothing when there are none.
+func argumentsSDL(args []introspectionInputValue) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	rendered := make([]string, 0, len(args))
+	for _, a := range args {
+		rendered = append(rendered, a.sdl())
+	}
+	return "(" + strings.Join(rendered, ", ") + ")"
+}
+
+// sdl renders the input value as an argument or input field definition.
+func (v introspectionInputValue) sdl() string {
+	if v.DefaultValue != nil {
+		return fmt.Sprintf("%s: %s = %s", v.Name, v.Type.sdl(), *v.DefaultValue)
+	}
+	return fmt.Sprintf("%s: %s", v.Name, v.Type.sdl())
+}
+
+// sdl renders the type reference, such as "[User!]!".
+func (r introspectionTypeRef) sdl() string {
+	switch {
+	case r.Kind == "NON_NULL" && r.OfType != nil:
+		return r.OfType.sdl() + "!"
+	case r.Kind == "LIST" && r.OfType != nil:
+		return "[" + r.OfType.sdl() + "]"
+	default:
+		return r.Name
+	}
+}
@@ -0,0 +1,149 @@
+package complexity_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// introspectionResponse is the introspection result of the test schema.
+const introspectionResponse = `{"data": {"__schema": {
+	"queryType": {"name": "Query"},
+	"mutationType": null,
+	"subscriptionType": null,
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "user", "args": [{"name": "id", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID"}}, "defaultValue": null}],
+			 "type": {"kind": "OBJECT", "name": "User", "ofType": null}}
+		], "interfaces": []},
+		{"kind": "OBJECT", "name": "User", "fields": [
+			{"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID"}}},
+			{"name": "name", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "String"}}}
+		], "interfaces": []},
+		{"kind": "SCALAR", "name": "ID"},
+		{"kind": "SCALAR", "name": "String"},
+		{"kind": "OBJECT", "name": "__Schema", "fields": [], "interfaces": []}
+	],
+	"directives": [
+		{"name": "include", "locations": ["FIELD"], "args": [{"name": "if", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "Boolean"}}, "defaultValue": null}]}
+	]
+}}}`
+
+func TestRunAnalysisSchemaURL(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The endpoint is flaky and fails the first request.
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(introspectionResponse))
+	}))
+	defer server.Close()
+
+	fsys := fstest.MapFS{
+		"order.graphql": {Data: []byte(fragmentedQuery)},
+	}
+
+	result, err := complexity.RunAnalysisFS(t.Context(), fsys, server.URL, "*.graphql", complexity.WithSchemaFetch(time.Second, 1))
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{
+			Path:                "order.graphql",
+			OperationName:       "GetOrder",
+			OperationType:       ast.Query,
+			Complexity:          5,
+			FlattenedComplexity: 3,
+			RootFields:          1,
+			Depth:               2,
+			Arguments:           1,
+			UniqueFields:        3,
+			ResponseNodes:       3,
+		},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysisFS() mismatch (-want +got):\n%s", diff)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("schema fetched %d times, want 2", got)
+	}
+}
+
+func TestRunAnalysisSchemaURLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		retries int
+		want    error
+	}{
+		{
+			name: "introspection disabled",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errors": [{"message": "GraphQL introspection is not allowed"}]}`))
+			},
+			retries: 2,
+			want:    complexity.ErrIntrospectionDisabled,
+		},
+		{
+			name: "unreachable",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			retries: 1,
+			want:    complexity.ErrSchemaUnreachable,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(500 * time.Millisecond)
+			},
+			want: complexity.ErrSchemaUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			opt := complexity.WithSchemaFetch(100*time.Millisecond, tt.retries)
+			_, err := complexity.RunAnalysisFS(t.Context(), fstest.MapFS{}, server.URL, "*.graphql", opt)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("RunAnalysisFS() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAnalysisSchemaURLCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := complexity.RunAnalysisFS(ctx, fstest.MapFS{}, server.URL, "*.graphql", complexity.WithSchemaFetch(0, 5))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RunAnalysisFS() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("RunAnalysisFS() returned after %v, want it to abort promptly", elapsed)
+	}
+}
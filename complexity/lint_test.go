@@ -0,0 +1,37 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLintOperations(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "empty.graphql", OperationName: "Empty", FlattenedComplexity: 0, RootFields: 1},
+		{Path: "shallow.graphql", OperationName: "Shallow", FlattenedComplexity: 2, RootFields: 2},
+		{Path: "user.graphql", OperationName: "GetUser", FlattenedComplexity: 3, RootFields: 1},
+	}
+
+	expected := []complexity.Violation{
+		{
+			Path:          "empty.graphql",
+			OperationName: "Empty",
+			Rule:          complexity.RuleEmptySelection,
+			Subject:       "flattened complexity",
+			Message:       "is 0, the operation selects nothing with a cost",
+		},
+		{
+			Path:          "shallow.graphql",
+			OperationName: "Shallow",
+			Rule:          complexity.RuleRootFieldsOnly,
+			Subject:       "flattened complexity",
+			Message:       "equals its 2 root field(s), nothing below the root fields is selected",
+		},
+	}
+
+	if diff := cmp.Diff(expected, complexity.LintOperations(results)); diff != "" {
+		t.Errorf("LintOperations() mismatch (-want +got):\n%s", diff)
+	}
+}
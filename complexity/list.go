@@ -0,0 +1,67 @@
+package complexity
+
+import (
+	"context"
+	"io/fs"
+	"os"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Operation identifies an operation declared by a document.
+type Operation struct {
+	Path          string        `json:"path" yaml:"path" toml:"path"`
+	OperationName string        `json:"operation" yaml:"operation" toml:"operation"`
+	OperationType ast.Operation `json:"operationType" yaml:"operationType" toml:"operationType"`
+}
+
+// ListOperations returns every operation of the documents matching the docs
+// glob, in the order the documents are read, without loading a schema or
+// computing complexity. Documents are found and read as by RunAnalysis, and
+// operations of the same name in several documents are all returned.
+// Documents that cannot be parsed are reported and skipped.
+func ListOperations(ctx context.Context, docs string, opts ...Option) ([]Operation, error) {
+	return ListOperationsFS(ctx, os.DirFS("."), docs, opts...)
+}
+
+// ListOperationsFS is like ListOperations but globs and reads the document
+// and ignore files from fsys rather than the working directory.
+func ListOperationsFS(ctx context.Context, fsys fs.FS, docs string, opts ...Option) ([]Operation, error) {
+	cfg := newConfig(opts)
+
+	var ignore *Ignore
+	if !cfg.NoIgnore {
+		var err error
+		if ignore, err = loadIgnore(fsys, cfg.IgnorePatterns); err != nil {
+			return nil, err
+		}
+	}
+
+	var operations []Operation
+	err := documentSources(fsys, docs, cfg, ignore, func(source document) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		queryDoc, err := parser.ParseQuery(source.Source)
+		if err != nil {
+			cfg.report("Parsing query", source.Name, err)
+			return nil
+		}
+
+		for i, op := range queryDoc.Operations {
+			operations = append(operations, Operation{
+				Path:          source.Name,
+				OperationName: operationName(op, i),
+				OperationType: op.Operation,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return operations, nil
+}
@@ -0,0 +1,44 @@
+package complexity_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestListOperations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.graphql": {Data: []byte(`query GetUser { user(id: 1) { id } }
+			mutation RenameUser { rename(id: 1) { id } }`)},
+		"b.graphql":        {Data: []byte(`query GetUser { user(id: 2) { name } }`)},
+		"c.graphql":        {Data: []byte(`{ user(id: 3) { id } }`)},
+		"broken.graphql":   {Data: []byte(`query {`)},
+		"fragment.graphql": {Data: []byte(`fragment UserFields on User { id }`)},
+	}
+
+	var diagnostics []complexity.Diagnostic
+	operations, err := complexity.ListOperationsFS(t.Context(), fsys, "*.graphql", complexity.WithDiagnosticHandler(func(d complexity.Diagnostic) {
+		diagnostics = append(diagnostics, d)
+	}))
+	if err != nil {
+		t.Fatalf("ListOperationsFS() error = %v", err)
+	}
+
+	// The documents are not validated, as no schema is loaded.
+	expected := []complexity.Operation{
+		{Path: "a.graphql", OperationName: "GetUser", OperationType: ast.Query},
+		{Path: "a.graphql", OperationName: "RenameUser", OperationType: ast.Mutation},
+		{Path: "b.graphql", OperationName: "GetUser", OperationType: ast.Query},
+		{Path: "c.graphql", OperationName: "<anonymous#0>", OperationType: ast.Query},
+	}
+
+	if diff := cmp.Diff(expected, operations); diff != "" {
+		t.Errorf("ListOperationsFS() mismatch (-want +got):\n%s", diff)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Path != "broken.graphql" {
+		t.Errorf("ListOperationsFS() diagnostics = %+v, want one for broken.graphql", diagnostics)
+	}
+}
@@ -0,0 +1,54 @@
+package complexity_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+)
+
+func TestRunAnalysisLogger(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"query.graphql":   {Data: []byte(`query GetUser($skip: Boolean!) { user(id: 1) { id @skip(if: $skip) } }`)},
+		"empty.graphql":   {Data: []byte(`# Nothing to analyse`)},
+		"invalid.graphql": {Data: []byte(`query { unknown }`)},
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls,missing/*.graphqls", "*.graphql", complexity.WithLogger(logger)); err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	for _, want := range []string{
+		`level=WARN msg="No files matched by schema glob" pattern=missing/*.graphqls`,
+		`level=WARN msg="Analysing document" file=invalid.graphql`,
+		`level=INFO msg="Skipping document without operations" file=empty.graphql`,
+		`level=DEBUG msg="Including selection with a condition on a variable without a value" directive=@skip variable=$skip`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("RunAnalysisFS() logged %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestRunAnalysisWithoutLogger(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"invalid.graphql": {Data: []byte(`query { unknown }`)},
+	}
+
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if _, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls,missing/*.graphqls", "*.graphql"); err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("RunAnalysisFS() logged %q without a logger, want nothing", buf.String())
+	}
+}
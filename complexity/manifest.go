@@ -0,0 +1,62 @@
+package complexity
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// ManifestSeparator separates the manifest path from the key of an entry in
+// the paths of documents read from persisted query manifests.
+const ManifestSeparator = "#"
+
+// apolloManifest is the persisted query manifest format generated by Apollo
+// clients.
+type apolloManifest struct {
+	Format     string `json:"format"`
+	Operations []struct {
+		ID   string `json:"id"`
+		Body string `json:"body"`
+	} `json:"operations"`
+}
+
+// isManifest reports whether the document at name is read as a persisted
+// query manifest by default.
+func isManifest(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".json")
+}
+
+// manifestSources reads the documents of a persisted query manifest. Both the
+// Apollo manifest format and Relay style maps from keys to query text are
+// supported. Each document is named by the manifest path and its key.
+func manifestSources(name string, data []byte) ([]*ast.Source, error) {
+	var apollo apolloManifest
+	if err := json.Unmarshal(data, &apollo); err == nil && apollo.Format != "" {
+		var sources []*ast.Source
+		for _, op := range apollo.Operations {
+			sources = append(sources, &ast.Source{Name: name + ManifestSeparator + op.ID, Input: op.Body})
+		}
+		return sources, nil
+	}
+
+	var relay map[string]string
+	if err := json.Unmarshal(data, &relay); err != nil {
+		return nil, fmt.Errorf("decoding persisted query manifest: %w", err)
+	}
+
+	var sources []*ast.Source
+	for key, query := range relay {
+		sources = append(sources, &ast.Source{Name: name + ManifestSeparator + key, Input: query})
+	}
+
+	slices.SortFunc(sources, func(a, b *ast.Source) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return sources, nil
+}
@@ -0,0 +1,41 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestRunAnalysisManifest(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"schema.graphqls": schema,
+		"queryMap.json": `{
+			"a1b2": "query GetUser { user(id: 1) { id name } }",
+			"c3d4": "query GetUserID { user(id: 2) { id } }"
+		}`,
+		"apollo.json": `{
+			"format": "apollo-persisted-query-manifest",
+			"version": 1,
+			"operations": [
+				{"id": "e5f6", "name": "GetName", "type": "query", "body": "query GetName { user(id: 1) { name } }"}
+			]
+		}`,
+	})
+
+	result, err := complexity.RunAnalysis(t.Context(), "*.graphqls", "*.json")
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "apollo.json#e5f6", OperationName: "GetName", OperationType: ast.Query, Complexity: 2, FlattenedComplexity: 2, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 2, ResponseNodes: 2},
+		{Path: "queryMap.json#a1b2", OperationName: "GetUser", OperationType: ast.Query, Complexity: 3, FlattenedComplexity: 3, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 3, ResponseNodes: 3},
+		{Path: "queryMap.json#c3d4", OperationName: "GetUserID", OperationType: ast.Query, Complexity: 2, FlattenedComplexity: 2, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 2, ResponseNodes: 2},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysis() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,51 @@
+package complexity
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// WriteMarkdown renders the results as a GitHub flavored Markdown table sorted
+// by descending complexity. With summary set a line with the total complexity
+// follows the table.
+func WriteMarkdown(w io.Writer, results []ComplexityAnalysis, summary bool) error {
+	return writeMarkdown(w, results, summary, nil)
+}
+
+// writeMarkdown writes the Markdown table with a summary including the hidden
+// results, when there are any.
+func writeMarkdown(w io.Writer, results []ComplexityAnalysis, summary bool, hidden *HiddenResults) error {
+	rows := slices.Clone(results)
+	slices.SortStableFunc(rows, func(a, b ComplexityAnalysis) int {
+		return cmp.Compare(b.Complexity, a.Complexity)
+	})
+
+	var sb strings.Builder
+	sb.WriteString("| Path | Operation | Complexity | Flattened |\n")
+	sb.WriteString("|------|-----------|-----------:|----------:|\n")
+
+	var total int
+	for _, r := range rows {
+		fmt.Fprintf(&sb, "| %s | %s | %d | %d |\n", escapeMarkdownCell(r.Path), escapeMarkdownCell(r.OperationName), r.Complexity, r.FlattenedComplexity)
+		total = safeAdd(total, r.Complexity)
+	}
+
+	if summary {
+		if hidden != nil && hidden.Count > 0 {
+			fmt.Fprintf(&sb, "\n**Total complexity:** %d across %d operations, %d of them hidden\n", safeAdd(total, hidden.Complexity), len(rows)+hidden.Count, hidden.Count)
+		} else {
+			fmt.Fprintf(&sb, "\n**Total complexity:** %d across %d operations\n", total, len(rows))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeMarkdownCell escapes characters that would end a table cell.
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,33 @@
+package complexity_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "user.graphql", OperationName: "GetUser", Complexity: 5, FlattenedComplexity: 3},
+		{Path: "order.graphql", OperationName: "Get|Order", Complexity: 12, FlattenedComplexity: 9},
+	}
+
+	var sb strings.Builder
+	if err := complexity.WriteMarkdown(&sb, results, true); err != nil {
+		t.Fatalf("failed to write markdown: %v", err)
+	}
+
+	expected := `| Path | Operation | Complexity | Flattened |
+|------|-----------|-----------:|----------:|
+| order.graphql | Get\|Order | 12 | 9 |
+| user.graphql | GetUser | 5 | 3 |
+
+**Total complexity:** 17 across 2 operations
+`
+
+	if diff := cmp.Diff(expected, sb.String()); diff != "" {
+		t.Errorf("WriteMarkdown() mismatch (-want +got):\n%s", diff)
+	}
+}
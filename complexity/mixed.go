@@ -0,0 +1,190 @@
+package complexity
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/lexer"
+)
+
+// executableKeywords start the executable definitions of a document.
+var executableKeywords = []string{"query", "mutation", "subscription", "fragment"}
+
+// definitionKeywords start the definitions of a document.
+var definitionKeywords = slices.Concat(executableKeywords, []string{
+	"schema", "scalar", "type", "interface", "union", "enum", "input", "directive", "extend",
+})
+
+// bodilessKeywords start the definitions that never have a body in braces.
+var bodilessKeywords = []string{"scalar", "union", "directive"}
+
+// splitDefinitions splits a source mixing type system and executable
+// definitions, such as a schema file with example operations, into a source
+// of each kind. The definitions of the other kind are blanked out rather than
+// removed, so that both sources keep the lines and columns of the original.
+// Descriptions and comments go with the definition they precede.
+func splitDefinitions(src *ast.Source) (schema, executable *ast.Source, err error) {
+	var (
+		lex     = lexer.New(src)
+		starts  []int
+		kinds   []bool
+		pending = -1
+		depth   int
+		prev    lexer.Token
+		hasPrev bool
+		// body is whether the current definition may still be followed by
+		// its body, so that braces open the body rather than an anonymous
+		// operation.
+		body bool
+	)
+	for {
+		tok, err := lex.ReadToken()
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing schema: %w: %w", ErrInvalidInput, err)
+		}
+		if tok.Kind == lexer.EOF {
+			break
+		}
+
+		switch tok.Kind {
+		case lexer.BraceL, lexer.ParenL, lexer.BracketL:
+			depth++
+		case lexer.BraceR, lexer.ParenR, lexer.BracketR:
+			depth--
+		}
+		if depth > 1 || depth == 1 && tok.Kind != lexer.BraceL {
+			continue
+		}
+
+		// Descriptions and comments between definitions belong to the next.
+		if depth == 0 && (tok.Kind == lexer.String || tok.Kind == lexer.BlockString || tok.Kind == lexer.Comment) {
+			if pending < 0 && (!hasPrev || endsDefinition(prev)) {
+				pending = tok.Pos.Start
+			}
+			continue
+		}
+
+		if isExecutable, ok := definitionStart(tok, prev, hasPrev, depth, body); ok {
+			if pending < 0 {
+				pending = tok.Pos.Start
+			}
+			starts = append(starts, pending)
+			kinds = append(kinds, isExecutable)
+		}
+		switch {
+		case tok.Kind == lexer.BraceL:
+			// Either the body of the definition or an anonymous operation,
+			// which is all body.
+			body = false
+		case depth == 0 && tok.Kind == lexer.Name && slices.Contains(definitionKeywords, tok.Value) && (!hasPrev || endsDefinition(prev) || prev.Value == "extend"):
+			body = !slices.Contains(bodilessKeywords, tok.Value)
+		}
+		pending = -1
+		prev, hasPrev = tok, true
+	}
+
+	if !slices.Contains(kinds, true) {
+		return src, nil, nil
+	}
+
+	input := []rune(src.Input)
+	blank := func(keep bool) *ast.Source {
+		out := slices.Clone(input)
+		for i, start := range starts {
+			if kinds[i] == keep {
+				continue
+			}
+			end := len(out)
+			if i+1 < len(starts) {
+				end = starts[i+1]
+			}
+			for j := start; j < end; j++ {
+				if out[j] != '\n' && out[j] != '\r' {
+					out[j] = ' '
+				}
+			}
+		}
+		return &ast.Source{Name: src.Name, Input: string(out), BuiltIn: src.BuiltIn}
+	}
+	return blank(false), blank(true), nil
+}
+
+// definitionStart reports whether the token starts a definition, and whether
+// that definition is executable. Keywords only start a definition where the
+// previous definition may end, rather than naming a type or directive, and
+// a selection set only does so where the previous definition cannot be
+// followed by its body: at the start, after a body, or after a scalar, union
+// or directive definition.
+func definitionStart(tok, prev lexer.Token, hasPrev bool, depth int, body bool) (executable, ok bool) {
+	if tok.Kind == lexer.BraceL {
+		if depth == 1 && !body && (!hasPrev || endsDefinition(prev)) {
+			return true, true
+		}
+		return false, false
+	}
+	if depth != 0 || tok.Kind != lexer.Name || !slices.Contains(definitionKeywords, tok.Value) {
+		return false, false
+	}
+	if hasPrev && !endsDefinition(prev) {
+		return false, false
+	}
+	return slices.Contains(executableKeywords, tok.Value), true
+}
+
+// endsDefinition reports whether a definition may end with the token.
+func endsDefinition(tok lexer.Token) bool {
+	switch tok.Kind {
+	case lexer.BraceR, lexer.ParenR, lexer.BracketR, lexer.String, lexer.BlockString:
+		return true
+	case lexer.Name:
+		return !slices.Contains(definitionKeywords, tok.Value) && tok.Value != "on" && tok.Value != "implements" && tok.Value != "repeatable"
+	}
+	return false
+}
+
+// mixedDocuments returns the executable definitions of the schema files
+// matching the spec that are not ignored, as documents named after the
+// files. Schema files without executable definitions yield no documents, and
+// plain globs are not read when a schema registry or inline schema replaces
+// them.
+func mixedDocuments(fsys fs.FS, spec string, cfg Config, ignore *Ignore) ([]document, error) {
+	var docs []document
+	for name, globs := range parseSchemaSpec(spec) {
+		if name == "" && (cfg.Registry != nil || cfg.InlineSchema != "") {
+			continue
+		}
+		for _, glob := range globs {
+			if isURL(glob) {
+				continue
+			}
+
+			matches, err := fs.Glob(fsys, glob)
+			if err != nil {
+				return nil, fmt.Errorf("globbing schema files: %w: %w", ErrInvalidInput, err)
+			}
+
+			for _, name := range ignore.Filter(matches) {
+				fileBytes, err := fs.ReadFile(fsys, name)
+				if err != nil {
+					return nil, fmt.Errorf("reading schema file %s: %w", name, err)
+				}
+
+				_, executable, err := splitDefinitions(&ast.Source{Name: name, Input: string(fileBytes)})
+				if err != nil {
+					return nil, fmt.Errorf("splitting %s: %w", name, err)
+				}
+				if executable != nil && strings.TrimSpace(executable.Input) != "" {
+					docs = append(docs, document{Source: executable})
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(docs, func(a, b document) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return docs, nil
+}
@@ -0,0 +1,130 @@
+package complexity_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// mixedSchema declares a type and an example operation using it.
+const mixedSchema = `"""
+The root query.
+"""
+type Query {
+	user(id: ID!): User
+}
+
+# Fetches a user by id.
+query GetUser {
+	user(id: 1) {
+		id
+		name
+	}
+}
+
+type User {
+	id: ID!
+	name: String!
+}
+`
+
+func TestRunAnalysisMixed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(mixedSchema)},
+		"query.graphql":   {Data: []byte(`query GetName { user(id: 2) { name } }`)},
+	}
+
+	if _, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql"); err == nil {
+		t.Fatal("RunAnalysisFS() error = nil, want an error loading a schema with an operation")
+	}
+
+	result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithMixed())
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "schema.graphqls", OperationName: "GetUser", OperationType: ast.Query, Complexity: 3, FlattenedComplexity: 3, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 3, ResponseNodes: 3},
+		{Path: "query.graphql", OperationName: "GetName", OperationType: ast.Query, Complexity: 2, FlattenedComplexity: 2, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 2, ResponseNodes: 2},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysisFS() mismatch (-want +got):\n%s", diff)
+	}
+	if result[0].Line != 9 {
+		t.Errorf("RunAnalysisFS() Line = %d, want the operation's line 9 in the schema file", result[0].Line)
+	}
+}
+
+func TestSplitDefinitions(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		schema     string
+		executable string
+	}{
+		{
+			name:   "schema only",
+			input:  "type Query { a: Int }",
+			schema: "type Query { a: Int }",
+		},
+		{
+			name:       "union and directive before an operation",
+			input:      "union U = A | B\ndirective @d on FIELD | QUERY\nquery Q { a }",
+			schema:     "union U = A | B\ndirective @d on FIELD | QUERY\n             ",
+			executable: "               \n                             \nquery Q { a }",
+		},
+		{
+			name:       "anonymous operation after a type",
+			input:      "type Query { a: Int } { a }",
+			schema:     "type Query { a: Int }      ",
+			executable: "                      { a }",
+		},
+		{
+			name:       "anonymous operation first",
+			input:      "{ a }\ntype Query { a: Int }",
+			schema:     "     \ntype Query { a: Int }",
+			executable: "{ a }\n                     ",
+		},
+		{
+			name:       "anonymous operations after definitions without a body",
+			input:      "scalar Date\n{ a }\nunion U = A | B\n{ b }\ndirective @d on FIELD\n{ c }",
+			schema:     "scalar Date\n     \nunion U = A | B\n     \ndirective @d on FIELD\n     ",
+			executable: "           \n{ a }\n               \n{ b }\n                     \n{ c }",
+		},
+		{
+			name:   "body after directives",
+			input:  "type Query @key(fields: \"a\")\n{ a: Int }\nextend type Query\n{ b: Int }",
+			schema: "type Query @key(fields: \"a\")\n{ a: Int }\nextend type Query\n{ b: Int }",
+		},
+		{
+			name:       "fragment named like a keyword",
+			input:      "fragment type on Query { a }\ntype Query { a: Int }",
+			schema:     "                            \ntype Query { a: Int }",
+			executable: "fragment type on Query { a }\n                     ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, executable, err := complexity.SplitDefinitions(&ast.Source{Name: "schema.graphqls", Input: tt.input})
+			if err != nil {
+				t.Fatalf("SplitDefinitions() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.schema, schema.Input); diff != "" {
+				t.Errorf("SplitDefinitions() schema mismatch (-want +got):\n%s", diff)
+			}
+			var got string
+			if executable != nil {
+				got = executable.Input
+			}
+			if diff := cmp.Diff(tt.executable, got); diff != "" {
+				t.Errorf("SplitDefinitions() executable mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package complexity
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// listMultiplier returns the list size given by the first of the named
+// arguments holding a positive integer, or 1 when none does.
+func listMultiplier(args map[string]any, names []string) int {
+	for _, name := range names {
+		if n, ok := intValue(args[name]); ok && n > 0 {
+			return n
+		}
+	}
+	return 1
+}
+
+// intValue converts a literal or decoded JSON number into an int.
+func intValue(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, false
+		}
+		return int(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	default:
+		return 0, false
+	}
+}
+
+// safeMul is a saturating multiplication of non-negative a and b.
+func safeMul(a, b int) int {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	if a > maxInt/b {
+		return maxInt
+	}
+	return a * b
+}
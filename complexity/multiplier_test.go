@@ -0,0 +1,77 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const listSchema = `type Query {
+	users(first: Int, last: Int): [User!]!
+}
+
+type User {
+	id: ID!
+	name: String!
+}
+`
+
+func TestAnalyseDocumentListMultiplier(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "list.graphqls", Input: listSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		vars     map[string]any
+		expected int
+	}{
+		{
+			name:     "literal",
+			query:    `query { users(first: 10) { id name } }`,
+			expected: 21,
+		},
+		{
+			name:     "variable with default",
+			query:    `query ($count: Int = 50) { users(last: $count) { id } }`,
+			expected: 51,
+		},
+		{
+			name:     "variable without value",
+			query:    `query ($count: Int) { users(first: $count) { id name } }`,
+			expected: 3,
+		},
+		{
+			name:     "provided variable",
+			query:    `query ($count: Int = 50) { users(first: $count) { id name } }`,
+			vars:     map[string]any{"count": float64(5)},
+			expected: 11,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, err := parser.ParseQuery(&ast.Source{Name: "users.graphql", Input: tt.query})
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc,
+				complexity.WithListMultiplierArgs("first", "last"),
+				complexity.WithVariables(tt.vars),
+			)
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+
+			if got := result[0].Complexity; got != tt.expected {
+				t.Errorf("Complexity = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package complexity
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+// EstimateResponseNodes estimates the number of fields in the response to the
+// operation, counting each field once for every item of the lists it is
+// nested in. List sizes are taken from the arguments and @listSize
+// directives as for the complexity, so `users(first: 10) { posts(first: 5) {
+// title } }` has 1 users, 10 posts and 50 title nodes. Fragments are inlined
+// as by Flatten, and fields left out by @skip or @include are not counted.
+func EstimateResponseNodes(doc *ast.QueryDocument, op *ast.OperationDefinition, opts ...Option) int {
+	cfg := newConfig(opts)
+	return responseNodes(Flatten(doc, op).SelectionSet, NewVariables(op, cfg.Variables), cfg)
+}
+
+// responseNodes estimates the number of response fields of a flattened
+// selection set. Fragments kept apart by type condition, see
+// Config.TypeConditions, select different objects and count the largest.
+func responseNodes(selectionSet ast.SelectionSet, vars Variables, cfg Config) int {
+	var nodes, conditions int
+	for _, selection := range selectionSet {
+		if !included(selectionDirectives(selection), vars, cfg.logger()) {
+			continue
+		}
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			multiplier := 1
+			if s.Definition != nil {
+				multiplier = fieldMultiplier(s.Definition, s.ObjectDefinition.Name, vars.Arguments(s), cfg)
+			}
+			nodes = safeAdd(nodes, safeAdd(1, safeMul(multiplier, responseNodes(s.SelectionSet, vars, cfg))))
+		case *ast.InlineFragment:
+			conditions = max(conditions, responseNodes(s.SelectionSet, vars, cfg))
+		}
+	}
+	return safeAdd(nodes, conditions)
+}
@@ -0,0 +1,113 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const responseNodesSchema = `type Query {
+	user(id: ID!): User
+	users(first: Int): [User!]!
+}
+
+type User {
+	id: ID!
+	name: String!
+	posts(first: Int, last: Int): [Post!]!
+}
+
+type Post {
+	title: String!
+}
+`
+
+func TestEstimateResponseNodes(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: responseNodesSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		vars     map[string]any
+		expected int
+	}{
+		{
+			name:     "no lists",
+			query:    `query GetUser { user(id: 1) { id name } }`,
+			expected: 3,
+		},
+		{
+			name:     "list without size",
+			query:    `query GetUsers { users { id } }`,
+			expected: 2,
+		},
+		{
+			name:     "single list",
+			query:    `query GetUsers { users(first: 10) { id name } }`,
+			expected: 1 + 10*2,
+		},
+		{
+			name:     "nested lists",
+			query:    `query GetPosts { users(first: 10) { posts(first: 5) { title } } }`,
+			expected: 1 + 10*(1+5*1),
+		},
+		{
+			name:     "nested list sized by a variable",
+			query:    `query GetPosts($n: Int) { users(first: 10) { id posts(last: $n) { title } } }`,
+			vars:     map[string]any{"n": 3},
+			expected: 1 + 10*(2+3*1),
+		},
+		{
+			name:     "fragments",
+			query:    `query GetUsers { users(first: 2) { ...UserFields id } } fragment UserFields on User { id name }`,
+			expected: 1 + 2*2,
+		},
+		{
+			name:     "skipped field",
+			query:    `query GetUsers { users(first: 10) { id posts(first: 5) @skip(if: true) { title } } }`,
+			expected: 1 + 10*1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, tt.query)
+			if gqlErr != nil {
+				t.Fatalf("failed to load query: %v", gqlErr)
+			}
+
+			opts := []complexity.Option{complexity.WithListMultiplierArgs("first", "last"), complexity.WithVariables(tt.vars)}
+			if got := complexity.EstimateResponseNodes(queryDoc, queryDoc.Operations[0], opts...); got != tt.expected {
+				t.Errorf("EstimateResponseNodes() = %d, want %d", got, tt.expected)
+			}
+
+			results, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, opts...)
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+			if got := results[0].ResponseNodes; got != tt.expected {
+				t.Errorf("AnalyseDocument() ResponseNodes = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckResponseNodes(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "users.graphql", OperationName: "GetPosts", ResponseNodes: 61},
+		{Path: "users.graphql", OperationName: "GetUser", ResponseNodes: 3},
+	}
+
+	expected := []complexity.Violation{
+		{Path: "users.graphql", OperationName: "GetPosts", Rule: complexity.RuleMaxResponseNodes, Value: 61, Limit: 50},
+	}
+	if diff := cmp.Diff(expected, complexity.CheckResponseNodes(results, 50)); diff != "" {
+		t.Errorf("CheckResponseNodes() mismatch (-want +got):\n%s", diff)
+	}
+}
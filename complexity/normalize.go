@@ -0,0 +1,191 @@
+package complexity
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+	"github.com/vektah/gqlparser/v2/validator/rules"
+)
+
+// NormalizedOperation holds the canonical form of an operation declared by a
+// document, see NormalizeOperation.
+type NormalizedOperation struct {
+	Path          string        `json:"path" yaml:"path" toml:"path"`
+	OperationName string        `json:"operation" yaml:"operation" toml:"operation"`
+	OperationType ast.Operation `json:"operationType" yaml:"operationType" toml:"operationType"`
+	Normalized    string        `json:"normalized" yaml:"normalized" toml:"normalized"`
+}
+
+// NormalizeOperation returns the canonical form of the operation, for stable
+// diffs and deduplicating persisted queries. Fragments are inlined and
+// repeated fields merged as by Flatten, variable definitions, arguments and
+// the fields of input objects are sorted by name, and the operation is
+// formatted by gqlparser's formatter without comments. Operations differing
+// only in these respects normalize to the same text, and normalizing the text
+// again returns it unchanged.
+//
+// Without a schema the type of a selection set is not known, so fragments
+// with a type condition, such as those on the types of an interface or
+// union, are not merged into it. Their fields are merged into one inline
+// fragment per type condition, after the fields of the selection set.
+//
+// The fragments the operation spreads must be defined by the document and
+// must not spread themselves, as NormalizeOperations checks.
+func NormalizeOperation(doc *ast.QueryDocument, op *ast.OperationDefinition) string {
+	f := newFlattener(doc, nil)
+	f.keepTypeConditions = true
+	flat := flatten(f, op)
+
+	// The flattened operation may be the operation of the document itself,
+	// so sorting works on copies.
+	vars := make(ast.VariableDefinitionList, len(flat.VariableDefinitions))
+	for i, def := range flat.VariableDefinitions {
+		normalized := *def
+		normalized.DefaultValue = normalizeValue(def.DefaultValue)
+		normalized.Directives = normalizeDirectives(def.Directives)
+		vars[i] = &normalized
+	}
+	slices.SortFunc(vars, func(a, b *ast.VariableDefinition) int { return strings.Compare(a.Variable, b.Variable) })
+
+	return PrintOperation(&ast.OperationDefinition{
+		Operation:           flat.Operation,
+		Name:                flat.Name,
+		VariableDefinitions: vars,
+		Directives:          normalizeDirectives(flat.Directives),
+		SelectionSet:        normalizeSelectionSet(flat.SelectionSet),
+	})
+}
+
+// normalizeSelectionSet returns a copy of the flattened selection set with
+// the arguments of its fields and directives sorted. The order of the fields
+// is kept, as it is the order of the response.
+func normalizeSelectionSet(selectionSet ast.SelectionSet) ast.SelectionSet {
+	normalized := make(ast.SelectionSet, 0, len(selectionSet))
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			field := *sel
+			field.Arguments = normalizeArguments(sel.Arguments)
+			field.Directives = normalizeDirectives(sel.Directives)
+			field.SelectionSet = normalizeSelectionSet(sel.SelectionSet)
+			normalized = append(normalized, &field)
+		case *ast.InlineFragment:
+			fragment := *sel
+			fragment.Directives = normalizeDirectives(sel.Directives)
+			fragment.SelectionSet = normalizeSelectionSet(sel.SelectionSet)
+			normalized = append(normalized, &fragment)
+		}
+	}
+	return normalized
+}
+
+// normalizeDirectives returns a copy of the directives with their arguments
+// sorted. The directives keep their order.
+func normalizeDirectives(directives ast.DirectiveList) ast.DirectiveList {
+	if directives == nil {
+		return nil
+	}
+	normalized := make(ast.DirectiveList, len(directives))
+	for i, d := range directives {
+		directive := *d
+		directive.Arguments = normalizeArguments(d.Arguments)
+		normalized[i] = &directive
+	}
+	return normalized
+}
+
+// normalizeArguments returns a copy of the arguments sorted by name.
+func normalizeArguments(args ast.ArgumentList) ast.ArgumentList {
+	if args == nil {
+		return nil
+	}
+	normalized := make(ast.ArgumentList, len(args))
+	for i, arg := range args {
+		normalized[i] = &ast.Argument{Name: arg.Name, Value: normalizeValue(arg.Value), Position: arg.Position}
+	}
+	slices.SortFunc(normalized, func(a, b *ast.Argument) int { return strings.Compare(a.Name, b.Name) })
+	return normalized
+}
+
+// normalizeValue returns a copy of the value with the fields of input objects
+// sorted by name, at any depth. Lists keep their order.
+func normalizeValue(v *ast.Value) *ast.Value {
+	if v == nil || len(v.Children) == 0 {
+		return v
+	}
+
+	normalized := *v
+	normalized.Children = make(ast.ChildValueList, len(v.Children))
+	for i, child := range v.Children {
+		normalized.Children[i] = &ast.ChildValue{Name: child.Name, Value: normalizeValue(child.Value), Position: child.Position}
+	}
+	if v.Kind == ast.ObjectValue {
+		slices.SortFunc(normalized.Children, func(a, b *ast.ChildValue) int { return strings.Compare(a.Name, b.Name) })
+	}
+	return &normalized
+}
+
+// NormalizeOperations returns the canonical form of every operation of the
+// documents matching the docs glob, see NormalizeOperation. Documents are
+// found and read as by ListOperations, without loading a schema, and
+// documents that cannot be parsed are reported and skipped. Documents
+// spreading unknown fragments, or fragments that spread themselves, are
+// invalid input.
+func NormalizeOperations(ctx context.Context, docs string, opts ...Option) ([]NormalizedOperation, error) {
+	return NormalizeOperationsFS(ctx, os.DirFS("."), docs, opts...)
+}
+
+// NormalizeOperationsFS is like NormalizeOperations but globs and reads the
+// document and ignore files from fsys rather than the working directory.
+func NormalizeOperationsFS(ctx context.Context, fsys fs.FS, docs string, opts ...Option) ([]NormalizedOperation, error) {
+	cfg := newConfig(opts)
+
+	var ignore *Ignore
+	if !cfg.NoIgnore {
+		var err error
+		if ignore, err = loadIgnore(fsys, cfg.IgnorePatterns); err != nil {
+			return nil, err
+		}
+	}
+
+	var operations []NormalizedOperation
+	err := documentSources(fsys, docs, cfg, ignore, func(source document) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		queryDoc, err := parser.ParseQuery(source.Source)
+		if err != nil {
+			cfg.report("Parsing query", source.Name, err)
+			return nil
+		}
+
+		// Without a schema only the fragments are validated, as they are
+		// inlined.
+		if errs := validator.Validate(&ast.Schema{}, queryDoc, rules.NoFragmentCyclesRule, rules.KnownFragmentNamesRule); len(errs) > 0 {
+			return fmt.Errorf("validating fragments: %w: %w", ErrInvalidInput, errs)
+		}
+
+		for i, op := range queryDoc.Operations {
+			operations = append(operations, NormalizedOperation{
+				Path:          source.Name,
+				OperationName: operationName(op, i),
+				OperationType: op.Operation,
+				Normalized:    NormalizeOperation(queryDoc, op),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return operations, nil
+}
@@ -0,0 +1,217 @@
+package complexity_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// normalize parses the query and normalizes its first operation.
+func normalize(t *testing.T, query string) string {
+	t.Helper()
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{Name: "query.graphql", Input: query})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+	return complexity.NormalizeOperation(queryDoc, queryDoc.Operations[0])
+}
+
+func TestNormalizeOperation(t *testing.T) {
+	expected := `query GetUsers ($after: String, $first: Int = 10) {
+	users(filter: {name:"a",role:{id:1,kind:ADMIN}}, first: $first) @include(if: true) {
+		id
+		name
+		friends(after: $after, first: 5) {
+			id
+		}
+	}
+}
+`
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{
+			name: "canonical",
+			query: `query GetUsers($after: String, $first: Int = 10) {
+				users(filter: {name: "a", role: {id: 1, kind: ADMIN}}, first: $first) @include(if: true) {
+					id name friends(after: $after, first: 5) { id }
+				}
+			}`,
+		},
+		{
+			name:  "sorted arguments and variables",
+			query: `query GetUsers($first: Int = 10, $after: String) { users(first: $first, filter: {role: {kind: ADMIN, id: 1}, name: "a"}) @include(if: true) { id name friends(first: 5, after: $after) { id } } }`,
+		},
+		{
+			name: "merged fields and comments",
+			query: `# Users with friends
+			query GetUsers($after: String, $first: Int = 10) {
+				users(first: $first, filter: {name: "a", role: {id: 1, kind: ADMIN}}) @include(if: true) { id name }
+				# Friends are selected apart
+				users(filter: {role: {id: 1, kind: ADMIN}, name: "a"}, first: $first) @include(if: true) { friends(after: $after, first: 5) { id } }
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(expected, normalize(t, tt.query)); diff != "" {
+				t.Errorf("NormalizeOperation() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNormalizeOperationFragments(t *testing.T) {
+	expected := `query GetUser {
+	user(id: 1) {
+		... on User {
+			id
+			name
+		}
+	}
+}
+`
+
+	for _, query := range []string{
+		`query GetUser { user(id: 1) { ... on User { id name } } }`,
+		`query GetUser { user(id: 1) { ...A ...B } } fragment A on User { id } fragment B on User { name }`,
+		`query GetUser { user(id: 1) { ...C } } fragment C on User { id ... on User { name } }`,
+		`query GetUser { user(id: 1) { ... on User { id } ... on User { name } } }`,
+	} {
+		if diff := cmp.Diff(expected, normalize(t, query)); diff != "" {
+			t.Errorf("NormalizeOperation() of %q mismatch (-want +got):\n%s", query, diff)
+		}
+	}
+}
+
+func TestNormalizeOperationTypeConditions(t *testing.T) {
+	// Without a schema the types of node and search are not known, so the
+	// fields of User and Order may not be selected on every object.
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:  "interface",
+			query: `query GetNode { node(id: 1) { id ... on User { name } } }`,
+			expected: `query GetNode {
+	node(id: 1) {
+		id
+		... on User {
+			name
+		}
+	}
+}
+`,
+		},
+		{
+			name:  "union",
+			query: `query Search { search(text: "a") { ... on User { name } ... on Order { total } ... on User { id } } }`,
+			expected: `query Search {
+	search(text: "a") {
+		... on User {
+			name
+			id
+		}
+		... on Order {
+			total
+		}
+	}
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, normalize(t, tt.query)); diff != "" {
+				t.Errorf("NormalizeOperation() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if normalize(t, `query GetNode { node(id: 1) { ... on User { name } } }`) == normalize(t, `query GetNode { node(id: 1) { name } }`) {
+		t.Error("NormalizeOperation() merged a fragment on User into a selection set of unknown type")
+	}
+}
+
+func TestNormalizeOperationIdempotent(t *testing.T) {
+	for _, query := range []string{
+		`query GetUser($id: ID!) { user(id: $id) { ...UserFields } } fragment UserFields on User { name id }`,
+		`mutation { update(input: {tags: ["b", "a"], name: "x"}, id: 1) { id } }`,
+		`query ($b: Int = 1, $a: [String!] = ["z", "y"]) { a: user(id: $b) { id } b: user(id: 2) @skip(if: false) { id } }`,
+		`subscription OnEvent { events(type: {z: 1, a: {y: 2, b: 3}}) { ... on Message { text } } }`,
+	} {
+		once := normalize(t, query)
+		if diff := cmp.Diff(once, normalize(t, once)); diff != "" {
+			t.Errorf("NormalizeOperation() of %q is not idempotent (-want +got):\n%s", query, diff)
+		}
+	}
+}
+
+func TestNormalizeOperationKeepsDocument(t *testing.T) {
+	query := `query GetUser { user(id: 1, active: true) { name id } }`
+	queryDoc, err := parser.ParseQuery(&ast.Source{Name: "query.graphql", Input: query})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+	before := complexity.PrintOperation(queryDoc.Operations[0])
+
+	complexity.NormalizeOperation(queryDoc, queryDoc.Operations[0])
+
+	if diff := cmp.Diff(before, complexity.PrintOperation(queryDoc.Operations[0])); diff != "" {
+		t.Errorf("NormalizeOperation() modified the document (-want +got):\n%s", diff)
+	}
+}
+
+func TestNormalizeOperationsFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.graphql":       {Data: []byte(`query GetUser { user(id: 1, active: true) { id } }`)},
+		"b.graphql":       {Data: []byte(`query GetUser { user(active: true, id: 1) { id id } }`)},
+		"invalid.graphql": {Data: []byte(`query {`)},
+	}
+
+	operations, err := complexity.NormalizeOperationsFS(t.Context(), fsys, "*.graphql")
+	if err != nil {
+		t.Fatalf("NormalizeOperationsFS() error = %v", err)
+	}
+
+	normalized := "query GetUser {\n\tuser(active: true, id: 1) {\n\t\tid\n\t}\n}\n"
+	expected := []complexity.NormalizedOperation{
+		{Path: "a.graphql", OperationName: "GetUser", OperationType: ast.Query, Normalized: normalized},
+		{Path: "b.graphql", OperationName: "GetUser", OperationType: ast.Query, Normalized: normalized},
+	}
+	if diff := cmp.Diff(expected, operations); diff != "" {
+		t.Errorf("NormalizeOperationsFS() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNormalizeOperationsFSInvalidFragments(t *testing.T) {
+	tests := map[string]string{
+		"fragment cycle": `query Q { ...F }
+			fragment F on Query { a ...G }
+			fragment G on Query { b ...F }`,
+		"unknown fragment": `query Q { ...Missing a }`,
+	}
+
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fsys := fstest.MapFS{"query.graphql": {Data: []byte(doc)}}
+
+			_, err := complexity.NormalizeOperationsFS(t.Context(), fsys, "*.graphql")
+			if !errors.Is(err, complexity.ErrInvalidInput) {
+				t.Errorf("NormalizeOperationsFS() error = %v, want %v", err, complexity.ErrInvalidInput)
+			}
+		})
+	}
+}
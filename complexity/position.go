@@ -0,0 +1,74 @@
+package complexity
+
+import (
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/lexer"
+)
+
+// endLines returns the line of the closing brace of each definition in the
+// source, keyed by the start of the definition. The parser only records where
+// definitions start.
+func endLines(src *ast.Source) map[int]int {
+	var (
+		ends   = make(map[int]int)
+		lex    = lexer.New(src)
+		start  = -1
+		braces int
+		parens int
+	)
+
+	for {
+		tok, err := lex.ReadToken()
+		if err != nil || tok.Kind == lexer.EOF {
+			return ends
+		}
+		if tok.Kind == lexer.Comment {
+			continue
+		}
+		if start < 0 {
+			start = tok.Pos.Start
+		}
+
+		// Braces of object values only appear within the parentheses of
+		// arguments and variable definitions.
+		switch tok.Kind {
+		case lexer.ParenL:
+			parens++
+		case lexer.ParenR:
+			parens--
+		case lexer.BraceL:
+			if parens == 0 {
+				braces++
+			}
+		case lexer.BraceR:
+			if parens > 0 {
+				continue
+			}
+			braces--
+			if braces == 0 {
+				ends[start] = tok.Pos.Line
+				start = -1
+			}
+		}
+	}
+}
+
+// documentEndLines returns the end lines of the definitions of the document,
+// see endLines, or nil when the source of the document is unknown.
+func documentEndLines(doc *ast.QueryDocument) map[int]int {
+	for _, op := range doc.Operations {
+		if op.Position != nil && op.Position.Src != nil {
+			return endLines(op.Position.Src)
+		}
+	}
+	return nil
+}
+
+// operationPosition returns the line and column where the operation starts
+// and the line where it ends, or zeros when its position is unknown.
+func operationPosition(op *ast.OperationDefinition, ends map[int]int) (line, column, endLine int) {
+	if op.Position == nil {
+		return 0, 0, 0
+	}
+	return op.Position.Line, op.Position.Column, ends[op.Position.Start]
+}
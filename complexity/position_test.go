@@ -0,0 +1,87 @@
+package complexity_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+
+	"github.com/asger-noer/gql/complexity"
+)
+
+const positionedQuery = `# Operations of the profile page.
+query GetUser($id: ID! = "1") {
+	user(id: $id) {
+		...UserFields
+	}
+}
+
+fragment UserFields on User {
+	id
+	name
+}
+
+  query GetName($id: ID = "{") { user(id: $id) { name } }
+
+query GetID {
+	user(id: 3) { id }
+}
+`
+
+type position struct {
+	Line, Column, EndLine int
+}
+
+func TestAnalyseDocumentPositions(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	// Documents read from stdin are named "<stdin>" rather than by a path.
+	queryDoc, err := parser.ParseQuery(&ast.Source{Name: "<stdin>", Input: positionedQuery})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc)
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	got := make([]position, 0, len(result))
+	for _, r := range result {
+		got = append(got, position{Line: r.Line, Column: r.Column, EndLine: r.EndLine})
+	}
+
+	expected := []position{
+		{Line: 2, Column: 1, EndLine: 6},
+		{Line: 13, Column: 3, EndLine: 13},
+		{Line: 15, Column: 1, EndLine: 17},
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("AnalyseDocument() positions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestJSONFormatterPositions(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "<stdin>", OperationName: "GetUser", Line: 2, Column: 1, EndLine: 6},
+	}
+
+	var buf bytes.Buffer
+	if err := (complexity.JSONFormatter{}).Format(&buf, results); err != nil {
+		t.Fatalf("failed to format results: %v", err)
+	}
+
+	for _, want := range []string{`"line": 2`, `"column": 1`, `"endLine": 6`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("JSONFormatter output does not contain %s:\n%s", want, buf.String())
+		}
+	}
+}
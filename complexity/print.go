@@ -0,0 +1,61 @@
+package complexity
+
+import (
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// PrintOperation formats the operation as a GraphQL document. Given a
+// flattened operation the output contains no fragments.
+func PrintOperation(op *ast.OperationDefinition) string {
+	var sb strings.Builder
+	formatter.NewFormatter(&sb).FormatQueryDocument(&ast.QueryDocument{
+		Operations: ast.OperationList{op},
+	})
+	return sb.String()
+}
+
+// operationSource formats the operation together with the fragments it uses,
+// directly or through other fragments, as a GraphQL document.
+func operationSource(doc *ast.QueryDocument, op *ast.OperationDefinition) string {
+	used := make(map[string]bool)
+	collectFragments(doc, op.SelectionSet, used)
+
+	// Keep the fragments in the order they are defined in the document.
+	var fragments ast.FragmentDefinitionList
+	for _, frag := range doc.Fragments {
+		if used[frag.Name] {
+			fragments = append(fragments, frag)
+		}
+	}
+
+	var sb strings.Builder
+	formatter.NewFormatter(&sb).FormatQueryDocument(&ast.QueryDocument{
+		Operations: ast.OperationList{op},
+		Fragments:  fragments,
+	})
+	return sb.String()
+}
+
+// collectFragments adds the names of the fragments spread in the selection
+// set, and in the fragments they spread, to used.
+func collectFragments(doc *ast.QueryDocument, ss ast.SelectionSet, used map[string]bool) {
+	for _, selection := range ss {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			collectFragments(doc, sel.SelectionSet, used)
+		case *ast.InlineFragment:
+			collectFragments(doc, sel.SelectionSet, used)
+		case *ast.FragmentSpread:
+			if used[sel.Name] {
+				continue
+			}
+			used[sel.Name] = true
+			if frag := findFragmentDefinition(doc, sel.Name); frag != nil {
+				collectFragments(doc, frag.SelectionSet, used)
+			}
+		}
+	}
+}
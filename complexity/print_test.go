@@ -0,0 +1,125 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestPrintOperation(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&fragmentedQuerySource)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc)
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	expected := `query GetOrder ($id: ID!) {
+	user(id: $id) {
+		id
+		name
+	}
+}
+`
+
+	for range 10 {
+		if diff := cmp.Diff(expected, complexity.PrintOperation(result[0].Flattened)); diff != "" {
+			t.Fatalf("PrintOperation() mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestPrintOperationDistinctArguments(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{Name: "users.graphql", Input: `query GetUsers {
+	user(id: 1) { id }
+	user(id: 2) { name }
+	user(id: 1) { name }
+}`})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	// Selecting one field with different arguments fails validation, the
+	// flattened operation keeps the selections apart regardless.
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithoutValidation())
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	expected := `query GetUsers {
+	user(id: 1) {
+		id
+		name
+	}
+	user(id: 2) {
+		name
+	}
+}
+`
+
+	if diff := cmp.Diff(expected, complexity.PrintOperation(result[0].Flattened)); diff != "" {
+		t.Errorf("PrintOperation() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAnalyseDocumentWithSource(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&fragmentedQuerySource)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	result, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc)
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+	if result[0].Source != "" {
+		t.Errorf("AnalyseDocument() Source = %q, want none by default", result[0].Source)
+	}
+
+	result, err = complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithSource())
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	expected := `query GetOrder ($id: ID!) {
+	user(id: $id) {
+		... HeaderFragment
+		... UserFragment
+	}
+}
+fragment HeaderFragment on User {
+	id
+	name
+}
+fragment UserFragment on User {
+	id
+	name
+}
+`
+
+	if diff := cmp.Diff(expected, result[0].Source); diff != "" {
+		t.Errorf("AnalyseDocument() Source mismatch (-want +got):\n%s", diff)
+	}
+}
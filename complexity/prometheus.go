@@ -0,0 +1,50 @@
+package complexity
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// prometheusMetrics are the metrics written for each operation in the
+// Prometheus text exposition format.
+var prometheusMetrics = []struct {
+	name  string
+	help  string
+	value func(ComplexityAnalysis) int
+}{
+	{"gql_operation_complexity", "Complexity of the GraphQL operation.", func(r ComplexityAnalysis) int { return r.Complexity }},
+	{"gql_operation_flattened_complexity", "Complexity of the GraphQL operation after flattening fragments.", func(r ComplexityAnalysis) int { return r.FlattenedComplexity }},
+	{"gql_operation_depth", "Depth of the GraphQL operation.", func(r ComplexityAnalysis) int { return r.Depth }},
+}
+
+// PrometheusFormatter writes the results in the Prometheus text exposition
+// format, see PrometheusMetrics.
+type PrometheusFormatter struct{}
+
+func (PrometheusFormatter) Format(w io.Writer, results []ComplexityAnalysis) error {
+	_, err := io.WriteString(w, PrometheusMetrics(results))
+	return err
+}
+
+// PrometheusMetrics returns the results as gauges in the Prometheus text
+// exposition format, such as
+// gql_operation_complexity{path="user.graphql",operation="GetUser"} 12, for
+// instance for the textfile collector of node_exporter.
+func PrometheusMetrics(results []ComplexityAnalysis) string {
+	var sb strings.Builder
+	for _, m := range prometheusMetrics {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&sb, "# TYPE %s gauge\n", m.name)
+		for _, r := range results {
+			fmt.Fprintf(&sb, "%s{path=\"%s\",operation=\"%s\"} %d\n", m.name, escapePrometheusLabel(r.Path), escapePrometheusLabel(r.OperationName), m.value(r))
+		}
+	}
+	return sb.String()
+}
+
+// escapePrometheusLabel escapes the backslashes, double quotes and line feeds
+// of a label value.
+func escapePrometheusLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,33 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "user.graphql", OperationName: "GetUser", Complexity: 12, FlattenedComplexity: 9, Depth: 3},
+		{Path: `dir\"quoted".graphql`, OperationName: "<anonymous#0>", Complexity: 2, FlattenedComplexity: 2, Depth: 2},
+	}
+
+	expected := `# HELP gql_operation_complexity Complexity of the GraphQL operation.
+# TYPE gql_operation_complexity gauge
+gql_operation_complexity{path="user.graphql",operation="GetUser"} 12
+gql_operation_complexity{path="dir\\\"quoted\".graphql",operation="<anonymous#0>"} 2
+# HELP gql_operation_flattened_complexity Complexity of the GraphQL operation after flattening fragments.
+# TYPE gql_operation_flattened_complexity gauge
+gql_operation_flattened_complexity{path="user.graphql",operation="GetUser"} 9
+gql_operation_flattened_complexity{path="dir\\\"quoted\".graphql",operation="<anonymous#0>"} 2
+# HELP gql_operation_depth Depth of the GraphQL operation.
+# TYPE gql_operation_depth gauge
+gql_operation_depth{path="user.graphql",operation="GetUser"} 3
+gql_operation_depth{path="dir\\\"quoted\".graphql",operation="<anonymous#0>"} 2
+`
+
+	if diff := cmp.Diff(expected, complexity.PrometheusMetrics(results)); diff != "" {
+		t.Errorf("PrometheusMetrics() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,145 @@
+package complexity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// DefaultRegistryURL is the Apollo GraphOS Platform API, used when a Registry
+// has no URL.
+const DefaultRegistryURL = "https://api.apollographql.com/api/graphql"
+
+// Registry identifies a schema published to a schema registry speaking the
+// Apollo GraphOS Platform API.
+type Registry struct {
+	// URL is the GraphQL endpoint of the registry API, DefaultRegistryURL
+	// when empty.
+	URL string
+
+	// GraphRef names the graph variant whose latest schema is fetched, such
+	// as "my-graph@production".
+	GraphRef string
+
+	// APIKey authenticates with the registry, sent as the X-API-KEY header.
+	APIKey string
+}
+
+// registryQuery asks for the API schema of the latest publication of a graph
+// variant.
+const registryQuery = `query SchemaDocument($ref: ID!) {
+  variant(ref: $ref) {
+    __typename
+    ... on GraphVariant { latestPublication { schema { document } } }
+    ... on InvalidRefFormat { message }
+  }
+}`
+
+// fetchRegistrySchema fetches the latest schema of the graph variant from the
+// registry as SDL.
+func fetchRegistrySchema(ctx context.Context, reg Registry, cfg Config) (*ast.Source, error) {
+	if reg.URL == "" {
+		reg.URL = DefaultRegistryURL
+	}
+
+	sdl, err := withRetries(ctx, cfg, func(ctx context.Context) (string, error) {
+		return queryRegistry(ctx, reg, cfg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching schema %s from registry: %w", reg.GraphRef, err)
+	}
+	return &ast.Source{Name: reg.GraphRef, Input: sdl}, nil
+}
+
+// queryRegistry asks the registry for the schema of the graph variant once.
+func queryRegistry(ctx context.Context, reg Registry, cfg Config) (string, error) {
+	if cfg.SchemaTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.SchemaTimeout)
+		defer cancel()
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"query":     registryQuery,
+		"variables": map[string]string{"ref": reg.GraphRef},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reg.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("apollographql-client-name", "gql")
+	if reg.APIKey != "" {
+		req.Header.Set("X-API-KEY", reg.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSchemaUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	// A rejected API key will not be accepted on a retry.
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return "", fmt.Errorf("%w: registry rejected the API key: %s", ErrInvalidInput, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s", ErrSchemaUnreachable, resp.Status)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: reading response: %w", ErrSchemaUnreachable, err)
+	}
+
+	var result struct {
+		Data struct {
+			Variant *struct {
+				Typename          string `json:"__typename"`
+				Message           string `json:"message"`
+				LatestPublication *struct {
+					Schema struct {
+						Document string `json:"document"`
+					} `json:"schema"`
+				} `json:"latestPublication"`
+			} `json:"variant"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("%w: decoding response: %w", ErrInvalidInput, err)
+	}
+
+	if len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+		return "", fmt.Errorf("%w: %s", ErrInvalidInput, strings.Join(messages, "; "))
+	}
+
+	variant := result.Data.Variant
+	switch {
+	case variant == nil:
+		return "", fmt.Errorf("%w: graph ref %q not found", ErrInvalidInput, reg.GraphRef)
+	case variant.Typename == "InvalidRefFormat":
+		return "", fmt.Errorf("%w: graph ref %q: %s", ErrInvalidInput, reg.GraphRef, variant.Message)
+	case variant.LatestPublication == nil:
+		return "", fmt.Errorf("%w: graph ref %q has no published schema", ErrInvalidInput, reg.GraphRef)
+	}
+
+	return variant.LatestPublication.Schema.Document, nil
+}
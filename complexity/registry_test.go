@@ -0,0 +1,93 @@
+package complexity_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestRunAnalysisSchemaRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Ref string `json:"ref"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if got := r.Header.Get("X-API-KEY"); got != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.Variables.Ref != "shop@current" {
+			w.Write([]byte(`{"data": {"variant": null}}`))
+			return
+		}
+
+		resp, _ := json.Marshal(map[string]any{"data": map[string]any{"variant": map[string]any{
+			"__typename":        "GraphVariant",
+			"latestPublication": map[string]any{"schema": map[string]any{"document": schema}},
+		}}})
+		w.Write(resp)
+	}))
+	defer server.Close()
+
+	// The plain schema glob is replaced by the registry and its broken file
+	// never loaded.
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(`type Query {`)},
+		"order.graphql":   {Data: []byte(fragmentedQuery)},
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		reg := complexity.Registry{URL: server.URL, GraphRef: "shop@current", APIKey: "secret"}
+		result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithSchemaRegistry(reg))
+		if err != nil {
+			t.Fatalf("failed to run analysis: %v", err)
+		}
+
+		expected := []complexity.ComplexityAnalysis{
+			{
+				Path:                "order.graphql",
+				OperationName:       "GetOrder",
+				OperationType:       ast.Query,
+				Complexity:          5,
+				FlattenedComplexity: 3,
+				RootFields:          1,
+				Depth:               2,
+				Arguments:           1,
+				UniqueFields:        3,
+				ResponseNodes:       3,
+			},
+		}
+
+		if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+			t.Errorf("RunAnalysisFS() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	errorTests := []struct {
+		name string
+		reg  complexity.Registry
+	}{
+		{name: "unknown graph ref", reg: complexity.Registry{URL: server.URL, GraphRef: "shop@missing", APIKey: "secret"}},
+		{name: "rejected API key", reg: complexity.Registry{URL: server.URL, GraphRef: "shop@current", APIKey: "wrong"}},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithSchemaRegistry(tt.reg))
+			if !errors.Is(err, complexity.ErrInvalidInput) {
+				t.Errorf("RunAnalysisFS() error = %v, want %v", err, complexity.ErrInvalidInput)
+			}
+		})
+	}
+}
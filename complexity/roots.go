@@ -0,0 +1,48 @@
+package complexity
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+// RootField is a top level field selected by an operation and the type it
+// returns.
+type RootField struct {
+	FieldName string `json:"field" yaml:"field" toml:"field"`
+	TypeName  string `json:"type" yaml:"type" toml:"type"`
+}
+
+// CountRootFields counts the top level fields selected by the operation once
+// fragments spread or inlined at the root are expanded. Fields selected more
+// than once under the same response key count once.
+func CountRootFields(doc *ast.QueryDocument, op *ast.OperationDefinition) int {
+	return len(Flatten(doc, op).SelectionSet)
+}
+
+// OperationRootFields returns the top level fields selected by the operation,
+// once fragments spread or inlined at the root are expanded, with the type
+// each returns, such as "[User!]!". Fields selected more than once, under
+// aliases or not, are returned once in the order they are first selected.
+// Fields missing from the schema are returned without a type.
+func OperationRootFields(schema *ast.Schema, doc *ast.QueryDocument, op *ast.OperationDefinition) []RootField {
+	var (
+		roots []RootField
+		seen  = make(map[string]bool)
+	)
+	for _, selection := range Flatten(doc, op).SelectionSet {
+		field := selection.(*ast.Field)
+		if seen[field.Name] {
+			continue
+		}
+		seen[field.Name] = true
+
+		def := field.Definition
+		if def == nil {
+			def = fieldDefinition(schema, rootTypeName(schema, op), field.Name)
+		}
+
+		root := RootField{FieldName: field.Name}
+		if def != nil && def.Type != nil {
+			root.TypeName = def.Type.String()
+		}
+		roots = append(roots, root)
+	}
+	return roots
+}
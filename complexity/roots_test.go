@@ -0,0 +1,123 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestCountRootFields(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, `query GetUsers {
+		a: user(id: 1) { id }
+		...RootUsers
+		... on Query {
+			c: user(id: 3) { id }
+		}
+		a: user(id: 1) { name }
+	}
+
+	fragment RootUsers on Query {
+		b: user(id: 2) { id }
+	}`)
+	if gqlErr != nil {
+		t.Fatalf("failed to load query: %v", gqlErr)
+	}
+
+	if got := complexity.CountRootFields(queryDoc, queryDoc.Operations[0]); got != 3 {
+		t.Errorf("CountRootFields() = %d, want 3", got)
+	}
+}
+
+func TestOperationRootFields(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: `type Query {
+		user(id: ID!): User
+		users: [User!]!
+		version: String
+	}
+
+	type Mutation {
+		rename(name: String!): User!
+	}
+
+	type User {
+		id: ID!
+	}`})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []complexity.RootField
+	}{
+		{
+			name: "fragments and aliases",
+			query: `query GetUsers {
+				a: user(id: 1) { id }
+				...RootUsers
+				b: user(id: 2) { id }
+				... on Query { version }
+			}
+
+			fragment RootUsers on Query {
+				users { id }
+			}`,
+			expected: []complexity.RootField{
+				{FieldName: "user", TypeName: "User"},
+				{FieldName: "users", TypeName: "[User!]!"},
+				{FieldName: "version", TypeName: "String"},
+			},
+		},
+		{
+			name:     "mutation",
+			query:    `mutation Rename { rename(name: "a") { id } }`,
+			expected: []complexity.RootField{{FieldName: "rename", TypeName: "User!"}},
+		},
+		{
+			name:     "unknown field",
+			query:    `query GetUnknown { unknown }`,
+			expected: []complexity.RootField{{FieldName: "unknown"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Parsed rather than validated documents lack field definitions,
+			// which are then looked up in the schema.
+			queryDoc, err := parser.ParseQuery(&ast.Source{Input: tt.query})
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			got := complexity.OperationRootFields(schemaDoc, queryDoc, queryDoc.Operations[0])
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("OperationRootFields() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckRootFields(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "users.graphql", OperationName: "GetUsers", RootFields: 3},
+		{Path: "users.graphql", OperationName: "GetUser", RootFields: 1},
+	}
+
+	expected := []complexity.Violation{
+		{Path: "users.graphql", OperationName: "GetUsers", Rule: complexity.RuleMaxRootFields, Value: 3, Limit: 2},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckRootFields(results, 2)); diff != "" {
+		t.Errorf("CheckRootFields() mismatch (-want +got):\n%s", diff)
+	}
+}
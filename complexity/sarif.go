@@ -0,0 +1,148 @@
+package complexity
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// SARIF rule identifiers of violations, see WriteSARIF.
+const (
+	SARIFRuleComplexity = "gql/complexity"
+	SARIFRuleDepth      = "gql/depth"
+)
+
+// sarifRuleIDs maps violation rules to SARIF rule identifiers. Other rules are
+// identified as "gql/" followed by the rule name.
+var sarifRuleIDs = map[string]string{
+	RuleMaxComplexity: SARIFRuleComplexity,
+	RuleMaxDepth:      SARIFRuleDepth,
+}
+
+// sarifRuleDescriptions describes the rules in the SARIF log.
+var sarifRuleDescriptions = map[string]string{
+	RuleGolden:             "Operation complexity differs from its golden file",
+	RuleMaxAliasesPerField: "Field selected under too many aliases",
+	RuleMaxDirectives:      "Field given too many directives",
+	RuleMaxArguments:       "Operation gives too many arguments",
+	RuleMaxComplexity:      "Operation complexity exceeds the limit",
+	RuleMaxDepth:           "Operation depth exceeds the limit",
+	RuleMaxFileComplexity:  "Combined complexity of a file exceeds the limit",
+	RuleMaxOperations:      "Document declares too many operations",
+	RuleMaxResponseNodes:   "Estimated response of the operation has too many fields",
+	RuleMaxRootFields:      "Operation selects too many root fields",
+	RuleMaxUniqueFields:    "Operation selects too many distinct fields",
+	RuleStrictVariables:    "Operation variable is unused or undefined",
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the violations as a SARIF 2.1.0 log for code scanning
+// tools. Each violation is a result located at its operation, complexity and
+// depth violations use the SARIFRuleComplexity and SARIFRuleDepth rules.
+// Without violations the log has no results.
+func WriteSARIF(w io.Writer, violations []Violation) error {
+	var (
+		rules   []sarifRule
+		results = []sarifResult{}
+		seen    = make(map[string]bool)
+	)
+
+	for _, v := range violations {
+		id := sarifRuleID(v.Rule)
+		if !seen[id] {
+			seen[id] = true
+			rules = append(rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: sarifRuleDescriptions[v.Rule]}})
+		}
+
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: v.Path}}
+		if v.Line > 0 {
+			location.Region = &sarifRegion{StartLine: v.Line, StartColumn: v.Column}
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    id,
+			Level:     "error",
+			Message:   sarifMessage{Text: v.String()},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gql",
+				InformationURI: "https://github.com/asger-noer/gql",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// sarifRuleID returns the SARIF rule identifier of a violation rule.
+func sarifRuleID(rule string) string {
+	if id, ok := sarifRuleIDs[rule]; ok {
+		return id
+	}
+	return "gql/" + strings.TrimPrefix(rule, "max-")
+}
@@ -0,0 +1,108 @@
+package complexity_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	violations := []complexity.Violation{
+		{Path: "user.graphql", OperationName: "GetUser", Rule: complexity.RuleMaxComplexity, Value: 150, Limit: 100, Line: 3, Column: 1},
+		{Path: "user.graphql", OperationName: "GetUser", Rule: complexity.RuleMaxDepth, Value: 8, Limit: 5, Line: 3, Column: 1},
+	}
+
+	var sb strings.Builder
+	if err := complexity.WriteSARIF(&sb, violations); err != nil {
+		t.Fatalf("failed to write SARIF: %v", err)
+	}
+
+	expected := `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "gql",
+          "informationUri": "https://github.com/asger-noer/gql",
+          "rules": [
+            {
+              "id": "gql/complexity",
+              "shortDescription": {
+                "text": "Operation complexity exceeds the limit"
+              }
+            },
+            {
+              "id": "gql/depth",
+              "shortDescription": {
+                "text": "Operation depth exceeds the limit"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "gql/complexity",
+          "level": "error",
+          "message": {
+            "text": "user.graphql: GetUser: max-complexity: 150, limit is 100"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "user.graphql"
+                },
+                "region": {
+                  "startLine": 3,
+                  "startColumn": 1
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "gql/depth",
+          "level": "error",
+          "message": {
+            "text": "user.graphql: GetUser: max-depth: 8, limit is 5"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "user.graphql"
+                },
+                "region": {
+                  "startLine": 3,
+                  "startColumn": 1
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+
+	if diff := cmp.Diff(expected, sb.String()); diff != "" {
+		t.Errorf("WriteSARIF() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteSARIFWithoutViolations(t *testing.T) {
+	var sb strings.Builder
+	if err := complexity.WriteSARIF(&sb, nil); err != nil {
+		t.Fatalf("failed to write SARIF: %v", err)
+	}
+
+	if !strings.Contains(sb.String(), `"results": []`) {
+		t.Errorf("WriteSARIF() = %s, want no results", sb.String())
+	}
+}
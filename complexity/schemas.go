@@ -0,0 +1,284 @@
+package complexity
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// SchemaHint is the comment prefix a document uses to select the named schema
+// it is validated against, e.g. "# gql:schema accounts".
+const SchemaHint = "gql:schema"
+
+// parseSchemaSpec splits a comma separated list of schema globs into globs
+// keyed by schema name. Entries of the form name=glob belong to the named
+// schema, while plain globs belong to the unnamed default schema. Globs may
+// also be URLs of servers to introspect.
+func parseSchemaSpec(spec string) map[string][]string {
+	globs := make(map[string][]string)
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, glob, ok := strings.Cut(entry, "=")
+		if !ok || isURL(entry) {
+			name, glob = "", entry
+		}
+
+		globs[name] = append(globs[name], glob)
+	}
+	return globs
+}
+
+// checkStdin fails when both the schema and the documents are to be read
+// from standard input, which can only be read once.
+func checkStdin(schema, docs string) error {
+	var schemaStdin bool
+	for _, globs := range parseSchemaSpec(schema) {
+		schemaStdin = schemaStdin || slices.Contains(globs, StdinDocument)
+	}
+
+	var docsStdin bool
+	for glob := range strings.SplitSeq(docs, ",") {
+		docsStdin = docsStdin || strings.TrimSpace(glob) == StdinDocument
+	}
+
+	if schemaStdin && docsStdin {
+		return fmt.Errorf("%w: the schema and the documents cannot both be read from standard input", ErrInvalidInput)
+	}
+	return nil
+}
+
+// InlineSchemaName is the file name errors in Config.InlineSchema are reported
+// with.
+const InlineSchemaName = "<inline>"
+
+// loadSchemas loads every schema of the spec keyed by its name. A configured
+// schema registry or inline schema replaces the plain globs as the unnamed
+// schema.
+func loadSchemas(ctx context.Context, fsys fs.FS, spec string, cfg Config, ignore *Ignore) (map[string]*ast.Schema, error) {
+	if cfg.Registry != nil && cfg.InlineSchema != "" {
+		return nil, fmt.Errorf("%w: a schema registry and an inline schema cannot both be used", ErrInvalidInput)
+	}
+
+	specs := parseSchemaSpec(spec)
+	if cfg.Registry != nil || cfg.InlineSchema != "" {
+		specs[""] = nil
+	}
+
+	schemas := make(map[string]*ast.Schema)
+	for name, globs := range specs {
+		var (
+			schemaDoc *ast.Schema
+			err       error
+		)
+		switch {
+		case name == "" && cfg.Registry != nil:
+			schemaDoc, err = loadRegistrySchema(ctx, *cfg.Registry, cfg)
+		case name == "" && cfg.InlineSchema != "":
+			schemaDoc, err = buildSchema([]*ast.Source{{Input: cfg.InlineSchema, Name: InlineSchemaName, BuiltIn: false}}, cfg)
+		default:
+			schemaDoc, err = loadSchema(ctx, fsys, globs, cfg, ignore)
+		}
+		if err != nil {
+			if name != "" {
+				return nil, fmt.Errorf("schema %s: %w", name, err)
+			}
+			return nil, err
+		}
+
+		schemas[name] = schemaDoc
+	}
+	return schemas, nil
+}
+
+// loadSchema loads a single schema from all files matching the globs that are
+// not ignored, and from the servers of globs that are URLs.
+func loadSchema(ctx context.Context, fsys fs.FS, globs []string, cfg Config, ignore *Ignore) (*ast.Schema, error) {
+	var inputs []*ast.Source
+	for _, glob := range globs {
+		if isURL(glob) {
+			source, err := fetchSchema(ctx, glob, cfg)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, source)
+			continue
+		}
+		if glob == StdinDocument {
+			input, err := io.ReadAll(cfg.stdin())
+			if err != nil {
+				return nil, fmt.Errorf("reading schema from standard input: %w", err)
+			}
+			inputs = append(inputs, &ast.Source{Input: string(input), Name: stdinSchemaName, BuiltIn: false})
+			continue
+		}
+
+		schemas, err := fs.Glob(fsys, glob)
+		if err != nil {
+			return nil, fmt.Errorf("globbing schema files: %w: %w", ErrInvalidInput, err)
+		}
+
+		schemas = ignore.Filter(schemas)
+		if len(schemas) == 0 {
+			if err := cfg.noMatches("schema", glob); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, schemaPath := range schemas {
+			fileBytes, err := fs.ReadFile(fsys, schemaPath)
+			if err != nil {
+				return nil, fmt.Errorf("reading schema file %s: %w", schemaPath, err)
+			}
+
+			source := &ast.Source{Input: string(fileBytes), Name: schemaPath, BuiltIn: false}
+			if cfg.Mixed {
+				if source, _, err = splitDefinitions(source); err != nil {
+					return nil, fmt.Errorf("splitting %s: %w", schemaPath, err)
+				}
+			}
+			inputs = append(inputs, source)
+		}
+	}
+
+	return buildSchema(inputs, cfg)
+}
+
+// loadRegistrySchema loads the latest schema of a graph variant from the
+// schema registry.
+func loadRegistrySchema(ctx context.Context, reg Registry, cfg Config) (*ast.Schema, error) {
+	source, err := fetchRegistrySchema(ctx, reg, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return buildSchema([]*ast.Source{source}, cfg)
+}
+
+// buildSchema loads a schema from its sources, declaring the cost, free and
+// incremental delivery directives and, for federation, the federation
+// directives and types they use.
+func buildSchema(inputs []*ast.Source, cfg Config) (*ast.Schema, error) {
+	if errs := schemaErrors(inputs); len(errs) > 0 {
+		return nil, fmt.Errorf("loading schema: %w: %d error(s):\n%w", ErrInvalidInput, len(errs), errs)
+	}
+
+	source, err := directiveSource(inputs, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		inputs = append(inputs, source)
+	}
+
+	if cfg.Federation {
+		source, err := federationSource(inputs)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, source)
+	}
+
+	schemaDoc, err := gqlparser.LoadSchema(inputs...)
+	if err != nil {
+		return nil, fmt.Errorf("loading schema: %w: %w", ErrInvalidInput, err)
+	}
+
+	return schemaDoc, nil
+}
+
+// schemaErrors returns the syntax errors of every schema source and every
+// type or directive declared again by a later source, each with its file and
+// position. gqlparser stops at the first error of a schema, so that problems
+// spread over several files would otherwise be fixed one run at a time.
+// Directives of the gqlparser prelude, such as @specifiedBy, are declared
+// whether or not the schema does, and gqlparser accepts them being declared
+// again, so files may each declare them.
+func schemaErrors(inputs []*ast.Source) gqlerror.List {
+	var (
+		errs       gqlerror.List
+		types      = make(map[string]*ast.Position)
+		directives = make(map[string]*ast.Position)
+	)
+
+	for _, input := range inputs {
+		doc, err := parser.ParseSchema(input)
+		if err != nil {
+			errs = append(errs, gqlerror.WrapIfUnwrapped(err))
+			continue
+		}
+
+		for _, def := range doc.Definitions {
+			if first, ok := types[def.Name]; ok {
+				errs = append(errs, gqlerror.ErrorPosf(def.Position, "Cannot redeclare type %s, first declared at %s.", def.Name, position(first)))
+				continue
+			}
+			types[def.Name] = def.Position
+		}
+		for _, def := range doc.Directives {
+			if builtInDirectives[def.Name] {
+				continue
+			}
+			if first, ok := directives[def.Name]; ok {
+				errs = append(errs, gqlerror.ErrorPosf(def.Position, "Cannot redeclare directive @%s, first declared at %s.", def.Name, position(first)))
+				continue
+			}
+			directives[def.Name] = def.Position
+		}
+	}
+
+	return errs
+}
+
+// position formats a position as file:line:column.
+func position(pos *ast.Position) string {
+	if pos == nil || pos.Src == nil {
+		return "unknown position"
+	}
+	return fmt.Sprintf("%s:%d:%d", pos.Src.Name, pos.Line, pos.Column)
+}
+
+// selectSchema picks the schema a document is validated against. Documents
+// name their schema with a SchemaHint comment and fall back to the unnamed
+// schema when they have none.
+func selectSchema(schemas map[string]*ast.Schema, input string) (*ast.Schema, error) {
+	name := schemaHint(input)
+
+	schemaDoc, ok := schemas[name]
+	switch {
+	case ok:
+		return schemaDoc, nil
+	case name == "":
+		return nil, fmt.Errorf("%w: document has no schema hint, add a '# %s <name>' comment", ErrInvalidInput, SchemaHint)
+	default:
+		return nil, fmt.Errorf("%w: document selects unknown schema %q", ErrInvalidInput, name)
+	}
+}
+
+// schemaHint returns the schema name given by a SchemaHint comment in the
+// document, or the empty string if there is none.
+func schemaHint(input string) string {
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(line, "#")), SchemaHint); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
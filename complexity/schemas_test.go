@@ -0,0 +1,233 @@
+package complexity_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// writeFiles writes the files into a temporary directory and makes it the
+// working directory of the test.
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	t.Chdir(dir)
+}
+
+func TestRunAnalysisNamedSchemas(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"accounts/schema.graphqls": `type Query { user(id: ID!): User } type User { id: ID! name: String! }`,
+		"products/schema.graphqls": `type Query { product(id: ID!): Product } type Product { id: ID! price: Int! }`,
+		"queries/user.graphql": `# gql:schema accounts
+			query GetUser { user(id: 1) { id name } }`,
+		"queries/product.graphql": `# gql:schema products
+			query GetProduct { product(id: 1) { id } }`,
+	})
+
+	result, err := complexity.RunAnalysis(t.Context(), "accounts=accounts/*.graphqls,products=products/*.graphqls", "queries/*.graphql")
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "queries/product.graphql", OperationName: "GetProduct", OperationType: ast.Query, Complexity: 2, FlattenedComplexity: 2, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 2, ResponseNodes: 2},
+		{Path: "queries/user.graphql", OperationName: "GetUser", OperationType: ast.Query, Complexity: 3, FlattenedComplexity: 3, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 3, ResponseNodes: 3},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysis() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunAnalysisMissingSchemaHint(t *testing.T) {
+	writeFiles(t, map[string]string{
+		"accounts.graphqls": `type Query { user(id: ID!): User } type User { id: ID! }`,
+		"user.graphql":      `query GetUser { user(id: 1) { id } }`,
+	})
+
+	_, err := complexity.RunAnalysis(t.Context(), "accounts=accounts.graphqls", "*.graphql")
+	if !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Errorf("RunAnalysis() error = %v, want %v", err, complexity.ErrInvalidInput)
+	}
+}
+
+func TestRunAnalysisSchemaErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.graphqls":    {Data: []byte("type Query { user: User }\ntype User { id: ID! }")},
+		"b.graphqls":    {Data: []byte("type User { name: String! }")},
+		"c.graphqls":    {Data: []byte("type Order {")},
+		"query.graphql": {Data: []byte(`query GetUser { user { id } }`)},
+	}
+
+	_, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql")
+	if !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Fatalf("RunAnalysisFS() error = %v, want %v", err, complexity.ErrInvalidInput)
+	}
+
+	var list gqlerror.List
+	if !errors.As(err, &list) {
+		t.Fatalf("RunAnalysisFS() error = %v, want a list of schema errors", err)
+	}
+
+	var got []string
+	for _, e := range list {
+		got = append(got, e.Error())
+	}
+
+	expected := []string{
+		"b.graphqls:1:6: Cannot redeclare type User, first declared at a.graphqls:2:6.",
+		"c.graphqls:1:13: Expected Name, found <EOF>",
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("RunAnalysisFS() schema errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunAnalysisInlineSchema(t *testing.T) {
+	stdin := strings.NewReader(`query GetUser { user(id: 1) { id name } }`)
+	result, err := complexity.RunAnalysisFS(t.Context(), fstest.MapFS{}, "*.graphqls", complexity.StdinDocument,
+		complexity.WithInlineSchema(`type Query { user(id: ID!): User } type User { id: ID! name: String! }`),
+		complexity.WithStdin(stdin),
+	)
+	if err != nil {
+		t.Fatalf("RunAnalysisFS() error = %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "<stdin>", OperationName: "GetUser", OperationType: ast.Query, Complexity: 3, FlattenedComplexity: 3, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 3, ResponseNodes: 3},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysisFS() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunAnalysisStdinSchema(t *testing.T) {
+	fsys := fstest.MapFS{
+		"query.graphql": {Data: []byte(`query GetUser { user(id: 1) { id name } }`)},
+	}
+	stdin := strings.NewReader(`type Query { user(id: ID!): User } type User { id: ID! name: String! }`)
+
+	result, err := complexity.RunAnalysisFS(t.Context(), fsys, complexity.StdinDocument, "*.graphql", complexity.WithStdin(stdin))
+	if err != nil {
+		t.Fatalf("RunAnalysisFS() error = %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "query.graphql", OperationName: "GetUser", OperationType: ast.Query, Complexity: 3, FlattenedComplexity: 3, RootFields: 1, Depth: 2, Arguments: 1, UniqueFields: 3, ResponseNodes: 3},
+	}
+
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysisFS() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunAnalysisStdinSchemaErrors(t *testing.T) {
+	stdin := strings.NewReader(`type Query { user: Unknown }`)
+
+	_, err := complexity.RunAnalysisFS(t.Context(), fstest.MapFS{}, complexity.StdinDocument, "*.graphql", complexity.WithStdin(stdin))
+	if !errors.Is(err, complexity.ErrInvalidInput) || !strings.Contains(err.Error(), "<stdin-schema>:1:20") {
+		t.Errorf("RunAnalysisFS() error = %v, want an invalid input error in <stdin-schema>", err)
+	}
+}
+
+func TestRunAnalysisStdinSchemaAndDocuments(t *testing.T) {
+	_, err := complexity.RunAnalysisFS(t.Context(), fstest.MapFS{}, "api="+complexity.StdinDocument, "*.graphql, -",
+		complexity.WithStdin(strings.NewReader("")),
+	)
+	if !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Errorf("RunAnalysisFS() error = %v, want %v", err, complexity.ErrInvalidInput)
+	}
+}
+
+func TestRunAnalysisInlineSchemaAndRegistry(t *testing.T) {
+	_, err := complexity.RunAnalysisFS(t.Context(), fstest.MapFS{}, "*.graphqls", "*.graphql",
+		complexity.WithInlineSchema(`type Query { id: ID }`),
+		complexity.WithSchemaRegistry(complexity.Registry{GraphRef: "shop@current"}),
+	)
+	if !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Errorf("RunAnalysisFS() error = %v, want %v", err, complexity.ErrInvalidInput)
+	}
+}
+
+func TestRunAnalysisCustomScalars(t *testing.T) {
+	const query = `query GetEvent { event { id startsAt } }`
+
+	tests := []struct {
+		name     string
+		schemas  map[string]string
+		opts     []complexity.Option
+		expected int
+	}{
+		{
+			name: "specifiedBy without declaration",
+			schemas: map[string]string{
+				"schema.graphqls": `scalar DateTime @specifiedBy(url: "https://scalars.graphql.org/andimarek/date-time")
+					type Query { event: Event }
+					type Event { id: ID! startsAt: DateTime! }`,
+			},
+			expected: 3,
+		},
+		{
+			name: "specifiedBy declared by several files",
+			schemas: map[string]string{
+				"query.graphqls": `directive @specifiedBy(url: String!) on SCALAR
+					type Query { event: Event }`,
+				"event.graphqls": `directive @specifiedBy(url: String!) on SCALAR
+					scalar DateTime @specifiedBy(url: "https://scalars.graphql.org/andimarek/date-time")
+					type Event { id: ID! startsAt: DateTime! }`,
+			},
+			expected: 3,
+		},
+		{
+			name: "custom scalars are leaves",
+			schemas: map[string]string{
+				"schema.graphqls": `scalar DateTime
+					type Query { event: Event }
+					type Event { id: ID! startsAt: DateTime! }`,
+			},
+			opts:     []complexity.Option{complexity.WithScalarComplexity(0)},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"event.graphql": {Data: []byte(query)}}
+			for name, input := range tt.schemas {
+				fsys[name] = &fstest.MapFile{Data: []byte(input)}
+			}
+
+			result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", tt.opts...)
+			if err != nil {
+				t.Fatalf("RunAnalysisFS() error = %v", err)
+			}
+			if len(result) != 1 {
+				t.Fatalf("RunAnalysisFS() returned %d results, want 1", len(result))
+			}
+			if result[0].Complexity != tt.expected {
+				t.Errorf("RunAnalysisFS() complexity = %d, want %d", result[0].Complexity, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package complexity
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Keys results are sorted by with SortResults.
+const (
+	SortByPath       = "path"
+	SortByName       = "name"
+	SortByComplexity = "complexity"
+	SortByFlattened  = "flattened"
+	SortByDepth      = "depth"
+)
+
+// sortKeys compares two results by each sort key.
+var sortKeys = map[string]func(a, b ComplexityAnalysis) int{
+	SortByPath: func(a, b ComplexityAnalysis) int {
+		return cmp.Compare(a.Path, b.Path)
+	},
+	SortByName: func(a, b ComplexityAnalysis) int {
+		return cmp.Compare(a.OperationName, b.OperationName)
+	},
+	SortByComplexity: func(a, b ComplexityAnalysis) int {
+		return cmp.Compare(a.Complexity, b.Complexity)
+	},
+	SortByFlattened: func(a, b ComplexityAnalysis) int {
+		return cmp.Compare(a.FlattenedComplexity, b.FlattenedComplexity)
+	},
+	SortByDepth: func(a, b ComplexityAnalysis) int {
+		return cmp.Compare(a.Depth, b.Depth)
+	},
+}
+
+// SortKeys returns the sorted names of the keys accepted by SortResults.
+func SortKeys() []string {
+	return slices.Sorted(maps.Keys(sortKeys))
+}
+
+// SortResults returns the results in ascending order of the key, or
+// descending order when reverse is set, keeping the order of results with
+// equal keys. An empty key keeps the order of the results, reversed when
+// reverse is set. The results are not modified.
+func SortResults(results []ComplexityAnalysis, key string, reverse bool) ([]ComplexityAnalysis, error) {
+	rows := slices.Clone(results)
+	if key == "" {
+		if reverse {
+			slices.Reverse(rows)
+		}
+		return rows, nil
+	}
+
+	compare, ok := sortKeys[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown sort key %q, valid keys are %s", ErrInvalidInput, key, strings.Join(SortKeys(), ", "))
+	}
+
+	slices.SortStableFunc(rows, func(a, b ComplexityAnalysis) int {
+		if reverse {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return rows, nil
+}
@@ -0,0 +1,66 @@
+package complexity_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSortResults(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "b.graphql", OperationName: "GetUser", Complexity: 5, FlattenedComplexity: 4, Depth: 2},
+		{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10, FlattenedComplexity: 3, Depth: 3},
+		{Path: "c.graphql", OperationName: "GetOrder", Complexity: 7, FlattenedComplexity: 7, Depth: 2},
+		{Path: "a.graphql", OperationName: "GetTask", Complexity: 7, FlattenedComplexity: 6, Depth: 1},
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		reverse  bool
+		expected []string
+	}{
+		{name: "unsorted", expected: []string{"GetUser", "ListUsers", "GetOrder", "GetTask"}},
+		{name: "unsorted reversed", reverse: true, expected: []string{"GetTask", "GetOrder", "ListUsers", "GetUser"}},
+		{name: "path", key: complexity.SortByPath, expected: []string{"ListUsers", "GetTask", "GetUser", "GetOrder"}},
+		{name: "path reversed", key: complexity.SortByPath, reverse: true, expected: []string{"GetOrder", "GetUser", "ListUsers", "GetTask"}},
+		{name: "name", key: complexity.SortByName, expected: []string{"GetOrder", "GetTask", "GetUser", "ListUsers"}},
+		{name: "name reversed", key: complexity.SortByName, reverse: true, expected: []string{"ListUsers", "GetUser", "GetTask", "GetOrder"}},
+		{name: "complexity", key: complexity.SortByComplexity, expected: []string{"GetUser", "GetOrder", "GetTask", "ListUsers"}},
+		{name: "complexity reversed", key: complexity.SortByComplexity, reverse: true, expected: []string{"ListUsers", "GetOrder", "GetTask", "GetUser"}},
+		{name: "flattened", key: complexity.SortByFlattened, expected: []string{"ListUsers", "GetUser", "GetTask", "GetOrder"}},
+		{name: "flattened reversed", key: complexity.SortByFlattened, reverse: true, expected: []string{"GetOrder", "GetTask", "GetUser", "ListUsers"}},
+		{name: "depth", key: complexity.SortByDepth, expected: []string{"GetTask", "GetUser", "GetOrder", "ListUsers"}},
+		{name: "depth reversed", key: complexity.SortByDepth, reverse: true, expected: []string{"ListUsers", "GetUser", "GetOrder", "GetTask"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := complexity.SortResults(results, tt.key, tt.reverse)
+			if err != nil {
+				t.Fatalf("SortResults() error = %v", err)
+			}
+
+			var got []string
+			for _, r := range sorted {
+				got = append(got, r.OperationName)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("SortResults() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if results[0].OperationName != "GetUser" {
+		t.Errorf("SortResults() modified the results")
+	}
+}
+
+func TestSortResultsUnknownKey(t *testing.T) {
+	_, err := complexity.SortResults(nil, "size", false)
+	if !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Errorf("SortResults() error = %v, want %v", err, complexity.ErrInvalidInput)
+	}
+}
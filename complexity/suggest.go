@@ -0,0 +1,118 @@
+package complexity
+
+import (
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Extension keys holding details of unknown field errors, see Diagnostic.
+const (
+	extensionField      = "field"
+	extensionType       = "type"
+	extensionSuggestion = "suggestion"
+)
+
+// explainUnknownFields rewrites the errors of fields unknown to their parent
+// type to name the field and type and to suggest the closest field name of
+// that type. The details are also stored in the error extensions.
+func explainUnknownFields(queryDoc *ast.QueryDocument, errs gqlerror.List) {
+	unknown := make(map[ast.Position]*ast.Field)
+	for _, op := range queryDoc.Operations {
+		collectUnknownFields(op.SelectionSet, unknown)
+	}
+	for _, frag := range queryDoc.Fragments {
+		collectUnknownFields(frag.SelectionSet, unknown)
+	}
+
+	for _, err := range errs {
+		if err.Rule != "FieldsOnCorrectType" || len(err.Locations) == 0 {
+			continue
+		}
+
+		loc := err.Locations[0]
+		field, ok := unknown[ast.Position{Line: loc.Line, Column: loc.Column}]
+		if !ok {
+			continue
+		}
+
+		var candidates []string
+		for _, f := range field.ObjectDefinition.Fields {
+			candidates = append(candidates, f.Name)
+		}
+
+		err.Message = fmt.Sprintf("Cannot query field %q on type %q.", field.Name, field.ObjectDefinition.Name)
+		if err.Extensions == nil {
+			err.Extensions = make(map[string]any)
+		}
+		err.Extensions[extensionField] = field.Name
+		err.Extensions[extensionType] = field.ObjectDefinition.Name
+
+		if suggestion := closestName(field.Name, candidates); suggestion != "" {
+			err.Message += fmt.Sprintf(" Did you mean %q?", suggestion)
+			err.Extensions[extensionSuggestion] = suggestion
+		}
+	}
+}
+
+// collectUnknownFields adds the fields of the selection set, and of the
+// selection sets below it, that the validator found no definition for keyed by
+// their position.
+func collectUnknownFields(selectionSet ast.SelectionSet, unknown map[ast.Position]*ast.Field) {
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if sel.Definition == nil && sel.ObjectDefinition != nil && sel.Position != nil {
+				unknown[ast.Position{Line: sel.Position.Line, Column: sel.Position.Column}] = sel
+			}
+			collectUnknownFields(sel.SelectionSet, unknown)
+		case *ast.InlineFragment:
+			collectUnknownFields(sel.SelectionSet, unknown)
+		}
+	}
+}
+
+// closestName returns the candidate with the smallest edit distance to name,
+// or nothing when no candidate is close enough to be a likely typo.
+func closestName(name string, candidates []string) string {
+	var (
+		closest string
+		// Allow about two edits for every five characters, like gqlparser,
+		// so that swapped letters are still suggested.
+		best = len(name)*2/5 + 2
+	)
+
+	for _, candidate := range candidates {
+		if d := levenshtein(name, candidate); d < best {
+			closest, best = candidate, d
+		}
+	}
+	return closest
+}
+
+// levenshtein returns the number of single character insertions, deletions
+// and substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
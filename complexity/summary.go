@@ -0,0 +1,37 @@
+package complexity
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// RunSummary sums up an analysis for scripts wrapping the command: the number
+// of operations analysed, the number of threshold violations and the highest
+// complexity of any operation.
+type RunSummary struct {
+	Analyzed   int `json:"analyzed"`
+	Violations int `json:"violations"`
+	Max        int `json:"max"`
+}
+
+// Summarize sums up the results and the violations found in them.
+func Summarize(results []ComplexityAnalysis, violations []Violation) RunSummary {
+	var s RunSummary
+	for _, r := range results {
+		s.Add(r)
+	}
+	s.Violations = len(violations)
+	return s
+}
+
+// Add counts the result, for results that are not kept until the end of the
+// analysis.
+func (s *RunSummary) Add(r ComplexityAnalysis) {
+	s.Analyzed++
+	s.Max = max(s.Max, r.Complexity)
+}
+
+// WriteJSON writes the summary as JSON on a single line.
+func (s RunSummary) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
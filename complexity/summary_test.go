@@ -0,0 +1,34 @@
+package complexity_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSummarize(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "users.graphql", OperationName: "GetUser", Complexity: 3},
+		{Path: "users.graphql", OperationName: "ListUsers", Complexity: 40},
+		{Path: "orders.graphql", OperationName: "GetOrder", Complexity: 12},
+	}
+	violations := []complexity.Violation{
+		{Path: "users.graphql", OperationName: "ListUsers", Rule: complexity.RuleMaxComplexity, Value: 40, Limit: 20},
+	}
+
+	expected := complexity.RunSummary{Analyzed: 3, Violations: 1, Max: 40}
+	got := complexity.Summarize(results, violations)
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Summarize() mismatch (-want +got):\n%s", diff)
+	}
+
+	var buf bytes.Buffer
+	if err := got.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if want := "{\"analyzed\":3,\"violations\":1,\"max\":40}\n"; buf.String() != want {
+		t.Errorf("WriteJSON() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,134 @@
+package complexity
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// ANSI escape codes used to color table rows.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiDefault = "\x1b[39m"
+)
+
+// TableFormatter writes the results as a plain text table. With a Budget a
+// column with each operation's complexity as a percentage of it is added,
+// and with Color rows are colored green, yellow from BudgetWarn percent and
+// red from BudgetCritical percent. Without a Budget, Color colors rows by
+// the share of their complexity limit instead, red once they exceed it, and
+// leaves rows of operations without a limit in the default color. The
+// summary includes the Hidden results. Streamed tables keep the text of
+// every row until they are closed, see Stream.
+type TableFormatter struct {
+	Summary        bool
+	Hidden         *HiddenResults
+	Budget         int
+	BudgetWarn     int
+	BudgetCritical int
+	Limits         ComplexityLimits
+	Color          bool
+}
+
+func (f TableFormatter) Format(w io.Writer, results []ComplexityAnalysis) error {
+	return formatAll(f, w, results)
+}
+
+// Stream writes the table as results are written. Rows are kept as text
+// rather than results and written on Close, once the width of every column
+// is known.
+func (f TableFormatter) Stream(out io.Writer) ResultWriter {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, f.plain())
+	if f.Budget > 0 {
+		fmt.Fprintf(w, "File:\tOperation:\tComplexity:\tFlattened Complexity:\tBudget%%:\n")
+	} else {
+		fmt.Fprintf(w, "File:\tOperation:\tComplexity:\tFlattened Complexity:\n")
+	}
+	return &tableWriter{w: w, f: f}
+}
+
+type tableWriter struct {
+	w     *tabwriter.Writer
+	f     TableFormatter
+	count int
+	total int
+}
+
+func (t *tableWriter) Write(r ComplexityAnalysis) error {
+	color := t.f.rowColor(r)
+	fmt.Fprint(t.w, color)
+	if t.f.Budget > 0 {
+		fmt.Fprintf(t.w, "%s\t%s\t%d\t%d\t%d%%", r.Path, r.OperationName, r.Complexity, r.FlattenedComplexity, BudgetPercent(r.Complexity, t.f.Budget))
+	} else {
+		fmt.Fprintf(t.w, "%s\t%s\t%d\t%d", r.Path, r.OperationName, r.Complexity, r.FlattenedComplexity)
+	}
+	if color != "" {
+		fmt.Fprint(t.w, ansiReset)
+	}
+	fmt.Fprintln(t.w)
+
+	t.count++
+	t.total = safeAdd(t.total, r.Complexity)
+	return nil
+}
+
+func (t *tableWriter) Close() error {
+	if t.f.Summary {
+		fmt.Fprint(t.w, t.f.plain())
+		if h := t.f.Hidden; h != nil && h.Count > 0 {
+			fmt.Fprintf(t.w, "Total:\t%d operations, %d hidden\t%d\t\n", t.count+h.Count, h.Count, safeAdd(t.total, h.Complexity))
+		} else {
+			fmt.Fprintf(t.w, "Total:\t%d operations\t%d\t\n", t.count, t.total)
+		}
+	}
+	return t.w.Flush()
+}
+
+// rowColor returns the color of the row of r, or "" when rows are not
+// colored. Every colored row starts with a code of the same length so the
+// columns stay aligned.
+func (f TableFormatter) rowColor(r ComplexityAnalysis) string {
+	if !f.Color {
+		return ""
+	}
+	if f.Budget > 0 {
+		return budgetColor(BudgetPercent(r.Complexity, f.Budget), f.BudgetWarn, f.BudgetCritical)
+	}
+
+	limit, _ := f.Limits.limit(r.OperationType)
+	if r.MaxComplexity > 0 {
+		limit = r.MaxComplexity
+	}
+	switch {
+	case limit <= 0:
+		return f.plain()
+	case r.Complexity > limit:
+		return ansiRed
+	}
+	return budgetColor(BudgetPercent(r.Complexity, limit), f.BudgetWarn, maxInt)
+}
+
+// plain returns the code starting the uncolored lines of a colored table,
+// whose first column would otherwise be narrower than that of the rows.
+func (f TableFormatter) plain() string {
+	if f.Color {
+		return ansiDefault
+	}
+	return ""
+}
+
+// budgetColor returns the color of a row using the given share of the budget.
+func budgetColor(percent, warn, critical int) string {
+	switch {
+	case percent >= critical:
+		return ansiRed
+	case percent >= warn:
+		return ansiYellow
+	default:
+		return ansiGreen
+	}
+}
@@ -0,0 +1,30 @@
+package complexity
+
+import "time"
+
+// Timing records the wall-clock time spent analysing an operation, see
+// Config.Profile. Parse and Validate are the time spent on the whole document
+// holding the operation, and are shared by its other operations.
+type Timing struct {
+	Parse     time.Duration `json:"parse" yaml:"parse" toml:"parse"`
+	Validate  time.Duration `json:"validate" yaml:"validate" toml:"validate"`
+	Calculate time.Duration `json:"calculate" yaml:"calculate" toml:"calculate"`
+}
+
+// now returns the current time when profiling, and the zero time otherwise so
+// that analyses without Config.Profile do not read the clock.
+func (c Config) now() time.Time {
+	if !c.Profile {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// since returns the time elapsed since start when profiling, and zero
+// otherwise.
+func (c Config) since(start time.Time) time.Duration {
+	if !c.Profile {
+		return 0
+	}
+	return time.Since(start)
+}
@@ -0,0 +1,46 @@
+package complexity_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+)
+
+func TestRunAnalysisProfile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(schema)},
+		"query.graphql":   {Data: []byte(`query GetUser { user(id: 1) { id } } query GetName { user(id: 2) { name } }`)},
+	}
+
+	result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql")
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+	for _, r := range result {
+		if r.Timing != nil {
+			t.Errorf("RunAnalysisFS() %s Timing = %+v without profiling, want nil", r.OperationName, r.Timing)
+		}
+	}
+
+	result, err = complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithProfile())
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("RunAnalysisFS() returned %d results, want 2", len(result))
+	}
+	for _, r := range result {
+		if r.Timing == nil {
+			t.Fatalf("RunAnalysisFS() %s Timing = nil, want the time spent on it", r.OperationName)
+		}
+		if r.Timing.Parse < 0 || r.Timing.Validate < 0 || r.Timing.Calculate < 0 {
+			t.Errorf("RunAnalysisFS() %s Timing = %+v, want no negative durations", r.OperationName, *r.Timing)
+		}
+	}
+
+	// Both operations share the parsing and validation of their document.
+	if result[0].Timing.Parse != result[1].Timing.Parse || result[0].Timing.Validate != result[1].Timing.Validate {
+		t.Errorf("RunAnalysisFS() Timing = %+v and %+v, want the same parse and validate times", *result[0].Timing, *result[1].Timing)
+	}
+}
@@ -0,0 +1,21 @@
+package complexity
+
+import (
+	"cmp"
+	"slices"
+)
+
+// TopN returns the n results with the highest complexity in descending order
+// of complexity, keeping the order of results with equal complexity. An n of
+// zero or less returns every result sorted. The results are not modified.
+func TopN(results []ComplexityAnalysis, n int) []ComplexityAnalysis {
+	rows := slices.Clone(results)
+	slices.SortStableFunc(rows, func(a, b ComplexityAnalysis) int {
+		return cmp.Compare(b.Complexity, a.Complexity)
+	})
+
+	if n > 0 && n < len(rows) {
+		rows = rows[:n]
+	}
+	return rows
+}
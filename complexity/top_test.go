@@ -0,0 +1,43 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTopN(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "a.graphql", OperationName: "GetUser", Complexity: 5},
+		{Path: "b.graphql", OperationName: "GetOrder", Complexity: 7},
+		{Path: "a.graphql", OperationName: "ListUsers", Complexity: 10},
+		{Path: "c.graphql", OperationName: "GetTask", Complexity: 7},
+	}
+
+	tests := []struct {
+		name     string
+		n        int
+		expected []string
+	}{
+		{name: "all", n: 0, expected: []string{"ListUsers", "GetOrder", "GetTask", "GetUser"}},
+		{name: "top", n: 2, expected: []string{"ListUsers", "GetOrder"}},
+		{name: "more than results", n: 10, expected: []string{"ListUsers", "GetOrder", "GetTask", "GetUser"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range complexity.TopN(results, tt.n) {
+				got = append(got, r.OperationName)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("TopN() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if results[0].OperationName != "GetUser" {
+		t.Errorf("TopN() modified its input")
+	}
+}
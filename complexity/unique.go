@@ -0,0 +1,33 @@
+package complexity
+
+import "github.com/vektah/gqlparser/v2/ast"
+
+// CountUniqueFields counts the distinct "Type.field" pairs selected by the
+// operation and the fragments it uses, where the type is the one the field is
+// selected on. Unlike the complexity, selecting a field again does not add to
+// the count. Fields of documents not validated against the schema are not
+// counted.
+func CountUniqueFields(doc *ast.QueryDocument, op *ast.OperationDefinition) int {
+	fields := make(map[string]struct{})
+	collectUniqueFields(op.SelectionSet, fields)
+	for _, frag := range usedFragments(doc, op.SelectionSet) {
+		collectUniqueFields(frag.SelectionSet, fields)
+	}
+	return len(fields)
+}
+
+// collectUniqueFields adds the "Type.field" pairs selected in the selection
+// set to fields, leaving out the fragments it spreads.
+func collectUniqueFields(selectionSet ast.SelectionSet, fields map[string]struct{}) {
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if sel.ObjectDefinition != nil {
+				fields[sel.ObjectDefinition.Name+"."+sel.Name] = struct{}{}
+			}
+			collectUniqueFields(sel.SelectionSet, fields)
+		case *ast.InlineFragment:
+			collectUniqueFields(sel.SelectionSet, fields)
+		}
+	}
+}
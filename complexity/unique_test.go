@@ -0,0 +1,101 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const uniqueFieldsSchema = `type Query {
+	user(id: ID!): User
+	node(id: ID!): Node
+}
+
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	name: String!
+	friends: [User!]!
+}
+`
+
+func TestCountUniqueFields(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphqls", Input: uniqueFieldsSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{
+			name:     "distinct fields",
+			query:    `query GetUser { user(id: 1) { id name } }`,
+			expected: 3,
+		},
+		{
+			name: "repeated selections collapse",
+			query: `query GetUsers {
+				a: user(id: 1) { id name friends { id name friends { id name } } }
+				b: user(id: 2) { id }
+			}`,
+			expected: 4,
+		},
+		{
+			name: "fragments",
+			query: `query GetUser {
+				user(id: 1) { ...UserFields }
+			}
+
+			fragment UserFields on User {
+				friends { ...UserFields2 }
+			}
+
+			fragment UserFields2 on User {
+				name
+			}`,
+			expected: 3,
+		},
+		{
+			name:     "types the field is selected on",
+			query:    `query GetNode { node(id: 1) { id ... on User { id } } }`,
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryDoc, gqlErr := gqlparser.LoadQuery(schemaDoc, tt.query)
+			if gqlErr != nil {
+				t.Fatalf("failed to load query: %v", gqlErr)
+			}
+
+			if got := complexity.CountUniqueFields(queryDoc, queryDoc.Operations[0]); got != tt.expected {
+				t.Errorf("CountUniqueFields() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckUniqueFields(t *testing.T) {
+	results := []complexity.ComplexityAnalysis{
+		{Path: "schema.graphql", OperationName: "IntrospectionQuery", UniqueFields: 40},
+		{Path: "users.graphql", OperationName: "GetUser", UniqueFields: 3},
+	}
+
+	expected := []complexity.Violation{
+		{Path: "schema.graphql", OperationName: "IntrospectionQuery", Rule: complexity.RuleMaxUniqueFields, Value: 40, Limit: 20},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckUniqueFields(results, 20)); diff != "" {
+		t.Errorf("CheckUniqueFields() mismatch (-want +got):\n%s", diff)
+	}
+}
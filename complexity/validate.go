@@ -0,0 +1,101 @@
+package complexity
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// ValidationResult holds the outcome of validating a single document. A
+// document without diagnostics is valid.
+type ValidationResult struct {
+	Path        string
+	Diagnostics []Diagnostic
+}
+
+// Valid reports whether the document passed validation.
+func (r ValidationResult) Valid() bool {
+	return len(r.Diagnostics) == 0
+}
+
+// RunValidation validates every document matching the docs glob against the
+// schema loaded from the schema globs. Schemas and documents are loaded as by
+// RunAnalysis.
+func RunValidation(ctx context.Context, schema, docs string, opts ...Option) ([]ValidationResult, error) {
+	return RunValidationFS(ctx, os.DirFS("."), schema, docs, opts...)
+}
+
+// RunValidationFS is like RunValidation but globs and reads the schema,
+// document and ignore files from fsys rather than the working directory.
+func RunValidationFS(ctx context.Context, fsys fs.FS, schema, docs string, opts ...Option) ([]ValidationResult, error) {
+	cfg := newConfig(opts)
+
+	var ignore *Ignore
+	if !cfg.NoIgnore {
+		var err error
+		if ignore, err = loadIgnore(fsys, cfg.IgnorePatterns); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkStdin(schema, docs); err != nil {
+		return nil, err
+	}
+
+	validationRules, err := cfg.validationRules()
+	if err != nil {
+		return nil, err
+	}
+
+	schemas, err := loadSchemas(ctx, fsys, schema, cfg, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ChangedFiles, err = changedDocuments(fsys, schema, cfg.ChangedFiles); err != nil {
+		return nil, err
+	}
+
+	var results []ValidationResult
+	err = documentSources(fsys, docs, cfg, ignore, func(source document) error {
+		schemaDoc, err := selectSchema(schemas, source.Input)
+		if err != nil {
+			return fmt.Errorf("selecting schema for %s: %w", source.Name, err)
+		}
+
+		result := ValidationResult{Path: source.Name}
+		if queryDoc, err := parser.ParseQuery(source.Source); err != nil {
+			result.Diagnostics = Diagnostics(source.Name, err)
+		} else if err := ValidateDocument(schemaDoc, queryDoc, WithRules(validationRules)); err != nil {
+			result.Diagnostics = Diagnostics(source.Name, err)
+		}
+
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ValidateDocument validates the query document against the schema with the
+// default rules, or the rules given by the options.
+func ValidateDocument(schemaDoc *ast.Schema, queryDoc *ast.QueryDocument, opts ...Option) error {
+	validationRules, err := newConfig(opts).validationRules()
+	if err != nil {
+		return err
+	}
+
+	if errs := validator.ValidateWithRules(schemaDoc, queryDoc, validationRules); len(errs) > 0 {
+		explainUnknownFields(queryDoc, errs)
+		return fmt.Errorf("validating query document: %w", errs)
+	}
+	return nil
+}
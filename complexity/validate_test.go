@@ -0,0 +1,139 @@
+package complexity_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator/rules"
+)
+
+func TestRunValidationFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls":   {Data: []byte(schema)},
+		"valid.graphql":     {Data: []byte(fragmentedQuery)},
+		"invalid.graphql":   {Data: []byte(`query GetEmail { user(id: 1) { email } }`)},
+		"malformed.graphql": {Data: []byte(`query Broken { user(id: 1) {`)},
+	}
+
+	result, err := complexity.RunValidationFS(t.Context(), fsys, "*.graphqls", "*.graphql")
+	if err != nil {
+		t.Fatalf("failed to run validation: %v", err)
+	}
+
+	expected := []complexity.ValidationResult{
+		{
+			Path: "invalid.graphql",
+			Diagnostics: []complexity.Diagnostic{
+				{Path: "invalid.graphql", Line: 1, Column: 32, Message: `Cannot query field "email" on type "User".`, Field: "email", Type: "User"},
+			},
+		},
+		{
+			Path: "malformed.graphql",
+			Diagnostics: []complexity.Diagnostic{
+				{Path: "malformed.graphql", Line: 1, Column: 29, Message: "Expected Name, found <EOF>"},
+			},
+		},
+		{Path: "valid.graphql"},
+	}
+
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("RunValidationFS() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateDocumentRules(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{
+		Name: "unused.graphql",
+		Input: `query GetUser { user(id: 1) { id } }
+		fragment Unused on User { name }`,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	if err := complexity.ValidateDocument(schemaDoc, queryDoc); err == nil {
+		t.Errorf("ValidateDocument() error = nil, want unused fragment error")
+	}
+
+	if err := complexity.ValidateDocument(schemaDoc, queryDoc, complexity.WithoutRules("NoUnusedFragments")); err != nil {
+		t.Errorf("ValidateDocument() without NoUnusedFragments error = %v, want nil", err)
+	}
+
+	onlyFields := rules.NewRules(rules.FieldsOnCorrectTypeRule)
+	if err := complexity.ValidateDocument(schemaDoc, queryDoc, complexity.WithRules(onlyFields)); err != nil {
+		t.Errorf("ValidateDocument() with custom rules error = %v, want nil", err)
+	}
+
+	err = complexity.ValidateDocument(schemaDoc, queryDoc, complexity.WithoutRules("NoSuchRule"))
+	if !errors.Is(err, complexity.ErrInvalidInput) {
+		t.Errorf("ValidateDocument() with unknown rule error = %v, want %v", err, complexity.ErrInvalidInput)
+	}
+
+	for _, name := range []string{"NoFragmentCycles", "KnownFragmentNames"} {
+		err = complexity.ValidateDocument(schemaDoc, queryDoc, complexity.WithoutRules(name))
+		if !errors.Is(err, complexity.ErrInvalidInput) {
+			t.Errorf("ValidateDocument() without %s error = %v, want %v", name, err, complexity.ErrInvalidInput)
+		}
+	}
+}
+
+func TestRunAnalysisFSOneOf(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schema.graphqls": {Data: []byte(`
+			type Query {
+				user(by: UserBy!): User
+			}
+
+			input UserBy @oneOf {
+				id: ID
+				email: String
+			}
+
+			type User {
+				id: ID!
+				name: String!
+			}
+		`)},
+		"user.graphql":  {Data: []byte(`query GetUser { user(by: { email: "a@example.com" }) { id name } }`)},
+		"both.graphql":  {Data: []byte(`query GetBoth { user(by: { id: 1, email: "a@example.com" }) { id } }`)},
+		"empty.graphql": {Data: []byte(`query GetNone { user(by: {}) { id } }`)},
+	}
+
+	var diagnostics []complexity.Diagnostic
+	result, err := complexity.RunAnalysisFS(t.Context(), fsys, "*.graphqls", "*.graphql", complexity.WithDiagnosticHandler(func(d complexity.Diagnostic) {
+		diagnostics = append(diagnostics, d)
+	}))
+	if err != nil {
+		t.Fatalf("failed to run analysis: %v", err)
+	}
+
+	expected := []complexity.ComplexityAnalysis{
+		{Path: "user.graphql", OperationName: "GetUser", OperationType: ast.Query, Complexity: 3, FlattenedComplexity: 3, RootFields: 1, Depth: 2, Arguments: 2, UniqueFields: 3, ResponseNodes: 3},
+	}
+	if diff := cmp.Diff(expected, result, ignoreAST); diff != "" {
+		t.Errorf("RunAnalysisFS() mismatch (-want +got):\n%s", diff)
+	}
+
+	var failed []string
+	for _, d := range diagnostics {
+		failed = append(failed, d.Path+": "+d.Message)
+	}
+	expectedFailed := []string{
+		`both.graphql: OneOf Input Object "UserBy" must specify exactly one key.`,
+		`empty.graphql: OneOf Input Object "UserBy" must specify exactly one key.`,
+	}
+	if diff := cmp.Diff(expectedFailed, failed); diff != "" {
+		t.Errorf("RunAnalysisFS() diagnostics mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,177 @@
+package complexity
+
+import (
+	"slices"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Variables holds the values of the variables an operation is analysed with.
+// Every feature depending on variables, such as @skip and @include
+// conditions, list multipliers and @stream's initialCount, resolves them
+// through it.
+type Variables map[string]any
+
+// NewVariables returns the variable values the operation is analysed with.
+// Provided values take precedence over the defaults declared by the
+// operation, variables with neither are left out and stay unresolved.
+func NewVariables(op *ast.OperationDefinition, provided map[string]any) Variables {
+	vars := make(Variables, len(op.VariableDefinitions))
+	for _, def := range op.VariableDefinitions {
+		if value, ok := provided[def.Variable]; ok {
+			vars[def.Variable] = value
+			continue
+		}
+
+		if def.DefaultValue != nil {
+			if value, err := def.DefaultValue.Value(nil); err == nil {
+				vars[def.Variable] = value
+			}
+		}
+	}
+	return vars
+}
+
+// Value returns the value of a literal or a variable, and whether it is
+// resolved. A variable without a value is unresolved.
+func (v Variables) Value(value *ast.Value) (any, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if value.Kind == ast.Variable {
+		x, ok := v[value.Raw]
+		return x, ok
+	}
+	x, err := value.Value(v)
+	return x, err == nil
+}
+
+// Int returns the integer value of a literal or a variable, and whether it is
+// resolved to an integer.
+func (v Variables) Int(value *ast.Value) (int, bool) {
+	x, ok := v.Value(value)
+	if !ok {
+		return 0, false
+	}
+	return intValue(x)
+}
+
+// Bool returns the boolean value of a literal or a variable, and whether it is
+// resolved to a boolean.
+func (v Variables) Bool(value *ast.Value) (bool, bool) {
+	x, ok := v.Value(value)
+	if !ok {
+		return false, false
+	}
+	b, ok := x.(bool)
+	return b, ok
+}
+
+// Arguments returns the values of the field's arguments. Arguments given
+// unresolved variables, or not given at all, take the default of their
+// definition and are left out without one.
+func (v Variables) Arguments(field *ast.Field) map[string]any {
+	values := make(map[string]any, len(field.Arguments))
+	for _, arg := range field.Arguments {
+		if x, ok := v.Value(arg.Value); ok {
+			values[arg.Name] = x
+		}
+	}
+
+	if field.Definition == nil {
+		return values
+	}
+	for _, def := range field.Definition.Arguments {
+		if _, ok := values[def.Name]; ok || def.DefaultValue == nil {
+			continue
+		}
+		if x, err := def.DefaultValue.Value(nil); err == nil {
+			values[def.Name] = x
+		}
+	}
+	return values
+}
+
+// operationVariableIssues returns the variables the operation declares but
+// never uses, including in the fragments it spreads, and the variables it
+// uses without declaring them. Both are sorted.
+func operationVariableIssues(doc *ast.QueryDocument, op *ast.OperationDefinition) (unused, undefined []string) {
+	used := make(map[string]bool)
+	collectVariables(doc, op.SelectionSet, used, make(map[string]bool))
+	collectDirectiveVariables(op.Directives, used)
+
+	declared := make(map[string]bool, len(op.VariableDefinitions))
+	for _, def := range op.VariableDefinitions {
+		declared[def.Variable] = true
+		if !used[def.Variable] {
+			unused = append(unused, def.Variable)
+		}
+	}
+
+	for name := range used {
+		if !declared[name] {
+			undefined = append(undefined, name)
+		}
+	}
+
+	slices.Sort(unused)
+	slices.Sort(undefined)
+	return unused, undefined
+}
+
+// collectVariables adds the variables used by the arguments of the fields and
+// directives of the selection set to used. Fragments are visited once.
+func collectVariables(doc *ast.QueryDocument, selectionSet ast.SelectionSet, used, visited map[string]bool) {
+	for _, selection := range selectionSet {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			collectArgumentVariables(sel.Arguments, used)
+			collectDirectiveVariables(sel.Directives, used)
+			collectVariables(doc, sel.SelectionSet, used, visited)
+		case *ast.InlineFragment:
+			collectDirectiveVariables(sel.Directives, used)
+			collectVariables(doc, sel.SelectionSet, used, visited)
+		case *ast.FragmentSpread:
+			collectDirectiveVariables(sel.Directives, used)
+			if visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			if frag := findFragmentDefinition(doc, sel.Name); frag != nil {
+				collectDirectiveVariables(frag.Directives, used)
+				collectVariables(doc, frag.SelectionSet, used, visited)
+			}
+		}
+	}
+}
+
+// collectDirectiveVariables adds the variables used by the directives to used.
+func collectDirectiveVariables(directives ast.DirectiveList, used map[string]bool) {
+	for _, d := range directives {
+		collectArgumentVariables(d.Arguments, used)
+	}
+}
+
+// collectArgumentVariables adds the variables used by the arguments, also
+// within list and object values, to used.
+func collectArgumentVariables(args ast.ArgumentList, used map[string]bool) {
+	for _, arg := range args {
+		collectValueVariables(arg.Value, used)
+	}
+}
+
+// collectValueVariables adds the variables used by the value to used.
+func collectValueVariables(value *ast.Value, used map[string]bool) {
+	if value == nil {
+		return
+	}
+
+	if value.Kind == ast.Variable {
+		used[value.Raw] = true
+		return
+	}
+
+	for _, child := range value.Children {
+		collectValueVariables(child.Value, used)
+	}
+}
@@ -0,0 +1,189 @@
+package complexity_test
+
+import (
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestCheckVariables(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&schemaSource)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	queryDoc, err := parser.ParseQuery(&ast.Source{
+		Name: "variables.graphql",
+		Input: `query Unused($id: ID!, $foo: String) {
+			user(id: $id) { ...UserFields }
+		}
+
+		query Undefined {
+			user(id: $id) { id }
+		}
+
+		fragment UserFields on User {
+			id
+			name @include(if: $withName)
+		}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	if _, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc); err == nil {
+		t.Fatalf("AnalyseDocument() error = nil, want variable validation errors")
+	}
+
+	analysis, err := complexity.AnalyseDocument(t.Context(), schemaDoc, queryDoc, complexity.WithStrictVariables())
+	if err != nil {
+		t.Fatalf("failed to analyse document: %v", err)
+	}
+
+	var results []complexity.ComplexityAnalysis
+	for _, a := range analysis {
+		results = append(results, complexity.ComplexityAnalysis{
+			Path:               "variables.graphql",
+			OperationName:      a.OperationName,
+			UnusedVariables:    a.UnusedVariables,
+			UndefinedVariables: a.UndefinedVariables,
+		})
+	}
+
+	expected := []complexity.Violation{
+		{Path: "variables.graphql", OperationName: "Unused", Rule: complexity.RuleStrictVariables, Subject: "$foo", Message: "is declared but never used"},
+		{Path: "variables.graphql", OperationName: "Unused", Rule: complexity.RuleStrictVariables, Subject: "$withName", Message: "is used but not declared"},
+		{Path: "variables.graphql", OperationName: "Undefined", Rule: complexity.RuleStrictVariables, Subject: "$id", Message: "is used but not declared"},
+	}
+
+	if diff := cmp.Diff(expected, complexity.CheckVariables(results)); diff != "" {
+		t.Errorf("CheckVariables() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewVariables(t *testing.T) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: `query ($provided: Int = 1, $defaulted: Int = 2, $missing: Int, $null: Int = 3) { __typename }`})
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	got := complexity.NewVariables(doc.Operations[0], map[string]any{"provided": float64(10), "null": nil, "undeclared": true})
+	expected := complexity.Variables{"provided": float64(10), "defaulted": int64(2), "null": nil}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("NewVariables() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestVariablesLookup(t *testing.T) {
+	vars := complexity.Variables{"count": float64(5), "fraction": 1.5, "flag": true, "null": nil}
+
+	tests := []struct {
+		name     string
+		value    *ast.Value
+		wantInt  int
+		intOK    bool
+		wantBool bool
+		boolOK   bool
+	}{
+		{name: "nil", value: nil},
+		{name: "int literal", value: &ast.Value{Kind: ast.IntValue, Raw: "3"}, wantInt: 3, intOK: true},
+		{name: "boolean literal", value: &ast.Value{Kind: ast.BooleanValue, Raw: "false"}, boolOK: true},
+		{name: "int variable", value: &ast.Value{Kind: ast.Variable, Raw: "count"}, wantInt: 5, intOK: true},
+		{name: "fractional variable", value: &ast.Value{Kind: ast.Variable, Raw: "fraction"}},
+		{name: "boolean variable", value: &ast.Value{Kind: ast.Variable, Raw: "flag"}, wantBool: true, boolOK: true},
+		{name: "null variable", value: &ast.Value{Kind: ast.Variable, Raw: "null"}},
+		{name: "unresolved variable", value: &ast.Value{Kind: ast.Variable, Raw: "missing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n, ok := vars.Int(tt.value); n != tt.wantInt || ok != tt.intOK {
+				t.Errorf("Int() = %d, %t, want %d, %t", n, ok, tt.wantInt, tt.intOK)
+			}
+			if b, ok := vars.Bool(tt.value); b != tt.wantBool || ok != tt.boolOK {
+				t.Errorf("Bool() = %t, %t, want %t, %t", b, ok, tt.wantBool, tt.boolOK)
+			}
+		})
+	}
+}
+
+func TestVariablesArguments(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Input: `type Query {
+		users(first: Int, last: Int = 20, after: String): [String!]!
+	}`})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	doc, errs := gqlparser.LoadQuery(schemaDoc, `query ($first: Int, $last: Int) { users(first: $first, last: $last, after: "x") }`)
+	if errs != nil {
+		t.Fatalf("failed to load query: %v", errs)
+	}
+	field := doc.Operations[0].SelectionSet[0].(*ast.Field)
+
+	got := complexity.Variables{"first": float64(10)}.Arguments(field)
+	expected := map[string]any{"first": float64(10), "last": int64(20), "after": "x"}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Arguments() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAnalyseDocumentVariables(t *testing.T) {
+	schemaDoc, err := gqlparser.LoadSchema(&ast.Source{Name: "list.graphqls", Input: listSchema})
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	const query = `query Users($count: Int = 4, $withName: Boolean = true) {
+		users(first: $count) {
+			id
+			name @include(if: $withName)
+		}
+	}`
+
+	tests := []struct {
+		name     string
+		vars     map[string]any
+		expected int
+	}{
+		{
+			name:     "defaults",
+			expected: 9,
+		},
+		{
+			name:     "provided",
+			vars:     map[string]any{"count": float64(10), "withName": false},
+			expected: 11,
+		},
+		{
+			name:     "null",
+			vars:     map[string]any{"count": nil, "withName": nil},
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := complexity.AnalyseString(t.Context(), schemaDoc, query,
+				complexity.WithListMultiplierArgs("first"),
+				complexity.WithVariables(tt.vars),
+			)
+			if err != nil {
+				t.Fatalf("failed to analyse document: %v", err)
+			}
+
+			if got := result[0].Complexity; got != tt.expected {
+				t.Errorf("Complexity = %d, want %d", got, tt.expected)
+			}
+			if got := result[0].ResponseNodes; got != tt.expected {
+				t.Errorf("ResponseNodes = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+package complexity
+
+import (
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Rule names used when reporting violations.
+const (
+	RuleMaxAliasesPerField = "max-aliases-per-field"
+	RuleMaxArguments       = "max-arguments"
+	RuleMaxComplexity      = "max-complexity"
+	RuleMaxDepth           = "max-depth"
+	RuleMaxDirectives      = "max-directives-per-field"
+	RuleMaxFileComplexity  = "max-file-complexity"
+	RuleMaxOperations      = "max-operations-per-document"
+	RuleMaxResponseNodes   = "max-response-nodes"
+	RuleMaxRootFields      = "max-root-fields"
+	RuleMaxUniqueFields    = "max-unique-fields"
+	RuleStrictVariables    = "strict-variables"
+
+	RuleMaxQueryComplexity        = "max-query-complexity"
+	RuleMaxMutationComplexity     = "max-mutation-complexity"
+	RuleMaxSubscriptionComplexity = "max-subscription-complexity"
+)
+
+// Rule names used when reporting lint warnings, see LintOperations.
+const (
+	RuleEmptySelection = "empty-selection"
+	RuleRootFieldsOnly = "root-fields-only"
+)
+
+// RuleMaxFieldCoverage is the rule name used when warning about broad
+// selections, see CheckFieldCoverage.
+const RuleMaxFieldCoverage = "max-field-coverage"
+
+// Violation describes an operation exceeding a configured threshold. Rules
+// without a numeric threshold describe the violation with a Message instead of
+// a Value and Limit. Line and Column locate the operation and are zero when
+// its position is unknown.
+type Violation struct {
+	Path          string
+	OperationName string
+	Rule          string
+	Subject       string
+	Value         int
+	Limit         int
+	Message       string
+	Line          int
+	Column        int
+}
+
+func (v Violation) String() string {
+	if v.Message != "" {
+		return fmt.Sprintf("%s: %s: %s: %s %s", v.Path, v.OperationName, v.Rule, v.Subject, v.Message)
+	}
+	if v.Subject != "" {
+		return fmt.Sprintf("%s: %s: %s: %s is %d, limit is %d", v.Path, v.OperationName, v.Rule, v.Subject, v.Value, v.Limit)
+	}
+	return fmt.Sprintf("%s: %s: %s: %d, limit is %d", v.Path, v.OperationName, v.Rule, v.Value, v.Limit)
+}
+
+// CheckAliases reports every field that is selected under more than limit
+// distinct aliases within a single selection set.
+func CheckAliases(results []ComplexityAnalysis, limit int) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		for _, a := range r.Aliases {
+			if a.Count > limit {
+				violations = append(violations, Violation{
+					Path:          r.Path,
+					Line:          r.Line,
+					Column:        r.Column,
+					OperationName: r.OperationName,
+					Rule:          RuleMaxAliasesPerField,
+					Subject:       a.Field,
+					Value:         a.Count,
+					Limit:         limit,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// CheckDirectives reports every field given more than limit directives in a
+// single selection, counted by CountDirectives.
+func CheckDirectives(results []ComplexityAnalysis, limit int) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		for _, d := range r.Directives {
+			if d.Count > limit {
+				violations = append(violations, Violation{
+					Path:          r.Path,
+					Line:          r.Line,
+					Column:        r.Column,
+					OperationName: r.OperationName,
+					Rule:          RuleMaxDirectives,
+					Subject:       d.Field,
+					Value:         d.Count,
+					Limit:         limit,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// CheckArguments reports every operation giving more than limit arguments,
+// counted by CountArguments.
+func CheckArguments(results []ComplexityAnalysis, limit int) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		if r.Arguments > limit {
+			violations = append(violations, Violation{
+				Path:          r.Path,
+				Line:          r.Line,
+				Column:        r.Column,
+				OperationName: r.OperationName,
+				Rule:          RuleMaxArguments,
+				Value:         r.Arguments,
+				Limit:         limit,
+			})
+		}
+	}
+	return violations
+}
+
+// ComplexityLimits holds the complexity limit of each operation type. Default
+// applies to operation types without a limit of their own. A zero limit
+// disables the check.
+type ComplexityLimits struct {
+	Default      int
+	Query        int
+	Mutation     int
+	Subscription int
+}
+
+// limit returns the limit for operations of type op and the rule reporting
+// it.
+func (l ComplexityLimits) limit(op ast.Operation) (int, string) {
+	switch {
+	case op == ast.Query && l.Query > 0:
+		return l.Query, RuleMaxQueryComplexity
+	case op == ast.Mutation && l.Mutation > 0:
+		return l.Mutation, RuleMaxMutationComplexity
+	case op == ast.Subscription && l.Subscription > 0:
+		return l.Subscription, RuleMaxSubscriptionComplexity
+	}
+	return l.Default, RuleMaxComplexity
+}
+
+// CheckComplexity reports every operation with a complexity above limit.
+// Operations annotated with the MaxComplexityHint are checked against their
+// own limit instead.
+func CheckComplexity(results []ComplexityAnalysis, limit int) []Violation {
+	return CheckComplexityLimits(results, ComplexityLimits{Default: limit})
+}
+
+// CheckComplexityLimits reports every operation with a complexity above the
+// limit of its operation type. Operations annotated with the
+// MaxComplexityHint are checked against their own limit instead.
+func CheckComplexityLimits(results []ComplexityAnalysis, limits ComplexityLimits) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		opLimit, rule := limits.limit(r.OperationType)
+		if r.MaxComplexity > 0 {
+			opLimit, rule = r.MaxComplexity, RuleMaxComplexity
+		}
+
+		if opLimit > 0 && r.Complexity > opLimit {
+			violations = append(violations, Violation{
+				Path:          r.Path,
+				Line:          r.Line,
+				Column:        r.Column,
+				OperationName: r.OperationName,
+				Rule:          rule,
+				Value:         r.Complexity,
+				Limit:         opLimit,
+			})
+		}
+	}
+	return violations
+}
+
+// CheckDepth reports every operation nesting fields deeper than limit.
+func CheckDepth(results []ComplexityAnalysis, limit int) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		if r.Depth > limit {
+			violations = append(violations, Violation{
+				Path:          r.Path,
+				Line:          r.Line,
+				Column:        r.Column,
+				OperationName: r.OperationName,
+				Rule:          RuleMaxDepth,
+				Value:         r.Depth,
+				Limit:         limit,
+			})
+		}
+	}
+	return violations
+}
+
+// CheckFileComplexity reports every file whose operations have a combined
+// complexity above limit.
+func CheckFileComplexity(results []ComplexityAnalysis, limit int) []Violation {
+	var violations []Violation
+	for _, total := range AggregateByFile(results) {
+		if total.Complexity > limit {
+			violations = append(violations, Violation{
+				Path:          total.Path,
+				OperationName: total.OperationName,
+				Rule:          RuleMaxFileComplexity,
+				Value:         total.Complexity,
+				Limit:         limit,
+			})
+		}
+	}
+	return violations
+}
+
+// CheckOperationsPerDocument reports every document declaring more than limit
+// operations, counting the results of each path. Files keep the order in which
+// they first appear in results.
+func CheckOperationsPerDocument(results []ComplexityAnalysis, limit int) []Violation {
+	var (
+		paths  []string
+		counts = make(map[string]int)
+	)
+	for _, r := range results {
+		if _, ok := counts[r.Path]; !ok {
+			paths = append(paths, r.Path)
+		}
+		counts[r.Path]++
+	}
+
+	var violations []Violation
+	for _, path := range paths {
+		if counts[path] > limit {
+			violations = append(violations, Violation{
+				Path:          path,
+				OperationName: FileTotalOperationName,
+				Rule:          RuleMaxOperations,
+				Value:         counts[path],
+				Limit:         limit,
+			})
+		}
+	}
+	return violations
+}
+
+// CheckResponseNodes reports every operation whose estimated number of
+// response fields, see EstimateResponseNodes, is above limit.
+func CheckResponseNodes(results []ComplexityAnalysis, limit int) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		if r.ResponseNodes > limit {
+			violations = append(violations, Violation{
+				Path:          r.Path,
+				Line:          r.Line,
+				Column:        r.Column,
+				OperationName: r.OperationName,
+				Rule:          RuleMaxResponseNodes,
+				Value:         r.ResponseNodes,
+				Limit:         limit,
+			})
+		}
+	}
+	return violations
+}
+
+// CheckRootFields reports every operation selecting more than limit root
+// fields.
+func CheckRootFields(results []ComplexityAnalysis, limit int) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		if r.RootFields > limit {
+			violations = append(violations, Violation{
+				Path:          r.Path,
+				Line:          r.Line,
+				Column:        r.Column,
+				OperationName: r.OperationName,
+				Rule:          RuleMaxRootFields,
+				Value:         r.RootFields,
+				Limit:         limit,
+			})
+		}
+	}
+	return violations
+}
+
+// CheckUniqueFields reports every operation selecting more than limit
+// distinct fields, counted by CountUniqueFields.
+func CheckUniqueFields(results []ComplexityAnalysis, limit int) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		if r.UniqueFields > limit {
+			violations = append(violations, Violation{
+				Path:          r.Path,
+				Line:          r.Line,
+				Column:        r.Column,
+				OperationName: r.OperationName,
+				Rule:          RuleMaxUniqueFields,
+				Value:         r.UniqueFields,
+				Limit:         limit,
+			})
+		}
+	}
+	return violations
+}
+
+// CheckVariables reports every variable an operation declares without using
+// it, or uses without declaring it. The results must come from an analysis
+// with strict variables, see Config.StrictVariables.
+func CheckVariables(results []ComplexityAnalysis) []Violation {
+	var violations []Violation
+	for _, r := range results {
+		for _, name := range r.UnusedVariables {
+			violations = append(violations, Violation{
+				Path:          r.Path,
+				Line:          r.Line,
+				Column:        r.Column,
+				OperationName: r.OperationName,
+				Rule:          RuleStrictVariables,
+				Subject:       "$" + name,
+				Message:       "is declared but never used",
+			})
+		}
+		for _, name := range r.UndefinedVariables {
+			violations = append(violations, Violation{
+				Path:          r.Path,
+				Line:          r.Line,
+				Column:        r.Column,
+				OperationName: r.OperationName,
+				Rule:          RuleStrictVariables,
+				Subject:       "$" + name,
+				Message:       "is used but not declared",
+			})
+		}
+	}
+	return violations
+}
+
+// LintOperations warns about operations that likely select less than intended,
+// such as those generated from a broken template: operations whose flattened
+// complexity is 0, and operations whose flattened complexity equals their
+// number of root fields, so that nothing below the root fields adds to it.
+// Unlike the checks, it describes suspicious rather than invalid operations.
+func LintOperations(results []ComplexityAnalysis) []Violation {
+	var warnings []Violation
+	for _, r := range results {
+		v := Violation{
+			Path:          r.Path,
+			Line:          r.Line,
+			Column:        r.Column,
+			OperationName: r.OperationName,
+			Subject:       "flattened complexity",
+		}
+
+		switch {
+		case r.FlattenedComplexity == 0:
+			v.Rule = RuleEmptySelection
+			v.Message = "is 0, the operation selects nothing with a cost"
+		case r.FlattenedComplexity == r.RootFields:
+			v.Rule = RuleRootFieldsOnly
+			v.Message = fmt.Sprintf("equals its %d root field(s), nothing below the root fields is selected", r.RootFields)
+		default:
+			continue
+		}
+
+		warnings = append(warnings, v)
+	}
+	return warnings
+}
+
+// CheckFieldCoverage warns about every type of which an operation selects
+// more than the limit, a fraction such as 0.8, of the fields, as generated
+// queries selecting every field of a type do. The results must come from an
+// analysis with field coverage, see Config.Coverage. Like LintOperations, it
+// describes suspicious rather than invalid operations.
+func CheckFieldCoverage(results []ComplexityAnalysis, limit float64) []Violation {
+	var warnings []Violation
+	for _, r := range results {
+		for _, c := range r.Coverage {
+			if c.Ratio() > limit {
+				warnings = append(warnings, Violation{
+					Path:          r.Path,
+					Line:          r.Line,
+					Column:        r.Column,
+					OperationName: r.OperationName,
+					Rule:          RuleMaxFieldCoverage,
+					Subject:       c.TypeName,
+					Message:       fmt.Sprintf("selects %d of %d fields (%.0f%%), limit is %.0f%%", c.Selected, c.Fields, c.Ratio()*100, limit*100),
+				})
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,33 @@
+package complexity
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadFieldWeights reads field weights for Config.FieldWeights from a YAML
+// mapping of "Type.field" to a weight, such as a costs file maintained apart
+// from the schema:
+//
+//	Query.search: 10
+//	User.friends: 5
+func ReadFieldWeights(r io.Reader) (map[string]int, error) {
+	var weights map[string]int
+	if err := yaml.NewDecoder(r).Decode(&weights); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing field weights: %w: %w", ErrInvalidInput, err)
+	}
+
+	for field, weight := range weights {
+		if !strings.Contains(field, ".") {
+			return nil, fmt.Errorf("%w: field weight key must be Type.field, got %q", ErrInvalidInput, field)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("%w: weight of %s must not be negative, got %d", ErrInvalidInput, field, weight)
+		}
+	}
+	return weights, nil
+}
@@ -0,0 +1,56 @@
+package complexity_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadFieldWeights(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]int
+		err      error
+	}{
+		{
+			name:     "weights",
+			input:    "Query.search: 10\nUser.friends: 0\n",
+			expected: map[string]int{"Query.search": 10, "User.friends": 0},
+		},
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:  "missing type",
+			input: "search: 10\n",
+			err:   complexity.ErrInvalidInput,
+		},
+		{
+			name:  "negative weight",
+			input: "Query.search: -1\n",
+			err:   complexity.ErrInvalidInput,
+		},
+		{
+			name:  "not a number",
+			input: "Query.search: high\n",
+			err:   complexity.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := complexity.ReadFieldWeights(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ReadFieldWeights() error = %v, want %v", err, tt.err)
+			}
+			if diff := cmp.Diff(tt.expected, weights); diff != "" {
+				t.Errorf("ReadFieldWeights() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package complexity
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// mostExpensiveCondition returns the complexity of the most expensive type
+// condition of a selection set on the parent type, given the summed
+// complexity of the fragments of each type condition, together with the type
+// conditions it is the complexity of.
+//
+// In worst case mode a response object is assumed to be of the most expensive
+// concrete type of the parent, paying for every fragment that applies to it:
+// those on the type itself and those on interfaces or unions including it.
+func mostExpensiveCondition[T int | float64](schema *ast.Schema, parent *ast.Definition, conditions map[string]T, worstCase bool, add func(a, b T) T) (T, []string) {
+	var (
+		mostExpensive T
+		chosen        []string
+	)
+	if !worstCase || parent == nil {
+		for _, typeCondition := range slices.Sorted(maps.Keys(conditions)) {
+			if c := conditions[typeCondition]; chosen == nil || c > mostExpensive {
+				mostExpensive, chosen = c, []string{typeCondition}
+			}
+		}
+		return mostExpensive, chosen
+	}
+
+	for _, possible := range schema.GetPossibleTypes(parent) {
+		var (
+			total   T
+			applied []string
+		)
+		for _, typeCondition := range slices.Sorted(maps.Keys(conditions)) {
+			if appliesTo(schema, typeCondition, possible) {
+				total = add(total, conditions[typeCondition])
+				applied = append(applied, typeCondition)
+			}
+		}
+		if chosen == nil || total > mostExpensive {
+			mostExpensive, chosen = total, applied
+		}
+	}
+	return mostExpensive, chosen
+}
+
+// appliesTo reports whether fragments with the type condition apply to
+// objects of the concrete type.
+func appliesTo(schema *ast.Schema, typeCondition string, concrete *ast.Definition) bool {
+	if typeCondition == concrete.Name {
+		return true
+	}
+
+	def := schema.Types[typeCondition]
+	if def == nil || !isAbstract(def) {
+		return false
+	}
+	for _, t := range schema.GetPossibleTypes(def) {
+		if t.Name == concrete.Name {
+			return true
+		}
+	}
+	return false
+}
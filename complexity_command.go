@@ -0,0 +1,834 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	ComplexityCommandName        = "complexity"
+	ComplexityCommandUsage       = "Analyze GraphQL query complexity"
+	ComplexityCommandDescription = `Analyze the complexity of GraphQL operations based on the provided schema.
+
+The complexity is calculated using the folling rules from gqlgen:
+- Each field has a base complexity of 1, see --base-complexity and --scalar-complexity.
+- __typename is free, see --count-typename.
+- Interfaces have the complexity of their most complex implementing type.
+- Selections on interfaces and unions cost their most expensive type condition.
+
+Exit codes:
+  0  success
+  1  internal or IO error
+  2  threshold violation
+  3  invalid input (bad glob, unparseable schema)`
+)
+
+func complexityCommand() *cli.Command {
+	return &cli.Command{
+		Name:        ComplexityCommandName,
+		Usage:       ComplexityCommandUsage,
+		Description: ComplexityCommandDescription,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "docs",
+				Usage: "Comma separated glob patterns to search for graphql files, or .zip, .tar or .tar.gz archives of them",
+				Value: complexity.DefaultDocuments,
+			},
+			&cli.StringSliceFlag{
+				Name:  "operation",
+				Usage: "Name of an operation to report, all operations are reported when none is given",
+			},
+			&cli.BoolFlag{
+				Name:  "federation",
+				Usage: "Declare the Apollo Federation directives and types used by subgraph schemas",
+			},
+			&cli.BoolFlag{
+				Name:  "manifest",
+				Usage: "Read documents as persisted query manifests, files ending in .json always are",
+			},
+			&cli.BoolFlag{
+				Name:  "mixed",
+				Usage: "Analyze operations found in schema files, such as examples, instead of failing to load the schema",
+			},
+			&cli.BoolFlag{
+				Name:  "batch",
+				Usage: "Read documents as batched requests, JSON arrays of queries with their variables, and report the total of each batch",
+			},
+			&cli.BoolFlag{
+				Name:  "no-ignore",
+				Usage: "Analyze files excluded by the .gqlignore file",
+			},
+			&cli.BoolFlag{
+				Name:  "require-matches",
+				Usage: "Fail when the document or a schema glob matches no files instead of warning",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Only analyze documents changed between this git ref and HEAD, or every document when a schema file changed",
+			},
+			&cli.StringSliceFlag{
+				Name:  "ignore",
+				Usage: "Gitignore style pattern of files to leave out in addition to the .gqlignore file",
+			},
+			&cli.StringSliceFlag{
+				Name:  "disable-rule",
+				Usage: "Name of a validation rule, such as NoUnusedFragments, to not validate documents with",
+			},
+			&cli.FloatFlag{
+				Name:  "max-field-coverage",
+				Usage: "Warn when an operation selects more than this fraction, such as 0.8, of the fields of a type (0 disables the warning)",
+			},
+			&cli.BoolFlag{
+				Name:  "lint",
+				Usage: "Warn about operations whose flattened complexity is 0 or equals their number of root fields, which suggests they select less than intended",
+			},
+			&cli.BoolFlag{
+				Name:  "strict-variables",
+				Usage: "Fail when an operation declares a variable it does not use or uses one it does not declare",
+			},
+			&cli.StringSliceFlag{
+				Name:  "list-multiplier-args",
+				Usage: "Arguments, such as first or last, whose value multiplies the complexity of a field's selection set",
+			},
+			&cli.StringSliceFlag{
+				Name:  "list-size-arg",
+				Usage: "Argument giving the list size of a field as Type.field=argument, such as Query.users=pageSize, consulted before --list-multiplier-args",
+			},
+			&cli.BoolFlag{
+				Name:  "worst-case",
+				Usage: "Assume interface and union objects are of their most expensive type, including fragments on the interfaces and unions that type belongs to",
+			},
+			&cli.BoolFlag{
+				Name:  "type-conditions",
+				Usage: "Only flatten fragments into the selections of the types they apply to, keeping fragments on different types of an interface or union apart",
+			},
+			&cli.StringSliceFlag{
+				Name:  "field-weight",
+				Usage: "Cost of a field as Type.field=N instead of 1",
+			},
+			&cli.StringSliceFlag{
+				Name:  "argument-cost",
+				Usage: "Cost added to a field when an argument has a value, as 'Type.field: when argument=value add N', such as 'Query.export: when format=CSV add 50'",
+			},
+			&cli.StringSliceFlag{
+				Name:  "ignore-field",
+				Usage: "Field as Type.field that costs nothing beyond its selection set, such as one batched by a DataLoader",
+			},
+			&cli.StringFlag{
+				Name:  "weights",
+				Usage: "YAML file mapping Type.field to the cost of the field, which takes precedence over @cost directives but not over --field-weight",
+			},
+			&cli.IntFlag{
+				Name:  "base-complexity",
+				Usage: "Cost of fields without a weight of their own",
+				Value: 1,
+			},
+			&cli.IntFlag{
+				Name:  "scalar-complexity",
+				Usage: "Cost of scalar and enum fields without a weight of their own, such as 0 to only count object fields (defaults to --base-complexity)",
+			},
+			&cli.BoolFlag{
+				Name:  "count-typename",
+				Usage: "Count __typename like other scalar fields rather than as free",
+			},
+			&cli.StringFlag{
+				Name:  "free-directive",
+				Usage: "Name of the directive marking field definitions that cost nothing beyond their selection set",
+				Value: complexity.DefaultFreeDirective,
+			},
+			&cli.FloatFlag{
+				Name:  "depth-decay",
+				Usage: "Make each field cost 1/decay^depth instead of 1 so that deeper fields are cheaper, for a decay of at least 1 (0 uses gqlgen's model)",
+			},
+			&cli.StringFlag{
+				Name:  "variables",
+				Usage: "JSON object with values for operation variables, variables without a value use their default. @skip and @include conditions on variables with neither count the selection",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format of the results, one of table, compact, markdown, html, json, yaml, toml, prometheus or sarif, which reports threshold violations",
+				Value: "table",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write the results to this file instead of stdout, creating its directory when missing",
+			},
+			&cli.StringFlag{
+				Name:  "schema-before",
+				Usage: "Glob pattern of the schema files before a change, such as new field costs, to compare the complexity of every operation with --schema-after instead of analyzing it, which cannot be combined with the output and threshold flags",
+			},
+			&cli.StringFlag{
+				Name:  "schema-after",
+				Usage: "Glob pattern of the schema files after a change, see --schema-before",
+			},
+			&cli.StringFlag{
+				Name:  "golden",
+				Usage: "Directory of golden files to compare the complexity of every operation with, failing when an operation changed, was added or was removed",
+			},
+			&cli.BoolFlag{
+				Name:  "update-golden",
+				Usage: "Rewrite the golden files in the --golden directory with the current complexity instead of comparing with them",
+			},
+			&cli.StringFlag{
+				Name:  "summary-json",
+				Usage: "Write the number of analyzed operations, threshold violations and the highest complexity to this file as JSON",
+			},
+			&cli.StringFlag{
+				Name:  "group-by",
+				Usage: "Group json, yaml and toml results, set to file to nest operations under their file with its total",
+			},
+			&cli.BoolFlag{
+				Name:  "include-source",
+				Usage: "Include the text of each operation and its fragments in json, yaml and toml results",
+			},
+			&cli.BoolFlag{
+				Name:  "by-fragment",
+				Usage: "Report the standalone complexity of every fragment each operation uses, including fragments used by other fragments",
+			},
+			&cli.BoolFlag{
+				Name:  "profile",
+				Usage: "Print the time spent parsing, validating and analyzing each operation to stderr",
+			},
+			&cli.BoolFlag{
+				Name:  "optimize-hints",
+				Usage: "Rank the fragments each operation uses by how much removing them would lower its flattened complexity",
+			},
+			&cli.BoolFlag{
+				Name:  "by-type",
+				Usage: "Report the complexity each operation spends on the fields of each type, attributing interface fields to the interface",
+			},
+			&cli.BoolFlag{
+				Name:  "roots",
+				Usage: "Report the top level fields of each operation and the types they return",
+			},
+			&cli.BoolFlag{
+				Name:  "explain",
+				Usage: "Report the fields of each operation as a tree with the complexity and schema description of each field",
+			},
+			&cli.BoolFlag{
+				Name:  "summary",
+				Usage: "Append the total complexity of all operations to the results",
+			},
+			&cli.IntFlag{
+				Name:  "top",
+				Usage: "Only report the N operations with the highest complexity, sorted by descending complexity (0 reports all)",
+			},
+			&cli.StringFlag{
+				Name:  "sort",
+				Usage: "Sort the results by path, name, complexity, flattened or depth in ascending order, rather than in the order documents are analysed",
+			},
+			&cli.BoolFlag{
+				Name:  "reverse",
+				Usage: "Reverse the order of the results, such as to sort them in descending order",
+			},
+			&cli.IntFlag{
+				Name:  "min-complexity",
+				Usage: "Hide results with a complexity below this value from the output, while --summary still counts them",
+			},
+			&cli.BoolFlag{
+				Name:  "per-file",
+				Usage: "Report one result per file with the summed complexity of its operations",
+			},
+			&cli.IntFlag{
+				Name:  "budget",
+				Usage: "Complexity budget to report each operation's complexity as a percentage of",
+			},
+			&cli.IntFlag{
+				Name:  "budget-warn",
+				Usage: "Percentage of the budget, or of the complexity limit without --budget, from which rows are colored yellow",
+				Value: 75,
+			},
+			&cli.IntFlag{
+				Name:  "budget-critical",
+				Usage: "Budget percentage from which rows are colored red",
+				Value: 100,
+			},
+			&cli.StringFlag{
+				Name:  "color",
+				Usage: "Color the rows of the table: auto, on a terminal unless NO_COLOR is set, always or never",
+				Value: "auto",
+			},
+			&cli.BoolFlag{
+				Name:  "quiet",
+				Usage: "Do not show the number of analyzed files on stderr while analyzing",
+			},
+			&cli.BoolFlag{
+				Name:  "print-flattened",
+				Usage: "Print each operation with all fragments inlined after the results",
+			},
+			&cli.BoolFlag{
+				Name:  "fail-fast",
+				Usage: "Stop analyzing at the first operation exceeding a threshold, --max-file-complexity and --max-operations-per-document aside, and report it",
+			},
+			&cli.IntFlag{
+				Name:  "max-complexity",
+				Usage: "Fail when an operation's complexity exceeds this value, operations annotated with # gql:max-complexity N use N instead (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-query-complexity",
+				Usage: "Fail when a query's complexity exceeds this value, overriding --max-complexity for queries (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-mutation-complexity",
+				Usage: "Fail when a mutation's complexity exceeds this value, overriding --max-complexity for mutations (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-subscription-complexity",
+				Usage: "Fail when a subscription's complexity exceeds this value, overriding --max-complexity for subscriptions (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-depth",
+				Usage: "Fail when an operation nests fields deeper than this value (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-aliases-per-field",
+				Usage: "Fail when a field is selected under more than this many aliases in one selection set (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-directives-per-field",
+				Usage: "Fail when a field is given more than this many directives in one selection (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-arguments",
+				Usage: "Fail when an operation gives more than this many arguments, counting every entry of object and list literals (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-root-fields",
+				Usage: "Fail when an operation selects more than this many root fields (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-unique-fields",
+				Usage: "Fail when an operation selects more than this many distinct Type.field pairs (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-response-nodes",
+				Usage: "Fail when the estimated number of fields in an operation's response, multiplied by the sizes of the lists they are in, exceeds this value (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-file-complexity",
+				Usage: "Fail when the combined complexity of the operations in a file exceeds this value (0 disables the check)",
+			},
+			&cli.IntFlag{
+				Name:  "max-operations-per-document",
+				Usage: "Fail when a document declares more than this many operations (0 disables the check)",
+			},
+		},
+		Commands: []*cli.Command{
+			diffCommand(),
+		},
+		Before: applyConfigFile,
+		Action: runComplexity,
+	}
+}
+
+func runComplexity(ctx context.Context, c *cli.Command) (err error) {
+	var (
+		docFind       = c.String("docs")
+		maxAliases    = c.Int("max-aliases-per-field")
+		maxDirectives = c.Int("max-directives-per-field")
+		maxArgs       = c.Int("max-arguments")
+		maxCost       = complexity.ComplexityLimits{
+			Default:      c.Int("max-complexity"),
+			Query:        c.Int("max-query-complexity"),
+			Mutation:     c.Int("max-mutation-complexity"),
+			Subscription: c.Int("max-subscription-complexity"),
+		}
+		maxDepth  = c.Int("max-depth")
+		maxFile   = c.Int("max-file-complexity")
+		maxOps    = c.Int("max-operations-per-document")
+		maxNodes  = c.Int("max-response-nodes")
+		maxRoots  = c.Int("max-root-fields")
+		maxUnique = c.Int("max-unique-fields")
+		names     = c.StringSlice("operation")
+	)
+
+	cfg, err := analysisConfig(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	fsys, err := rootFS(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	if c.IsSet("schema-before") || c.IsSet("schema-after") {
+		return runSchemaDiff(ctx, c, fsys, cfg)
+	}
+
+	schemaFind, err := schemaGlobs(c, fsys)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	// Golden files record every operation, and analyses leaving some out
+	// would report them as removed.
+	golden := c.String("golden")
+	switch {
+	case c.Bool("update-golden") && golden == "":
+		return cli.Exit("Invalid input: --update-golden needs --golden", ExitInvalidInput)
+	case golden != "" && (len(names) > 0 || c.String("since") != "" || c.Bool("fail-fast")):
+		return cli.Exit("Invalid input: --golden cannot be combined with --operation, --since or --fail-fast", ExitInvalidInput)
+	}
+
+	if ref := c.String("since"); ref != "" {
+		if cfg.ChangedFiles, err = changedFiles(ctx, c.String("root"), ref); err != nil {
+			return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+		}
+	}
+
+	var stdout io.Writer = os.Stdout
+	if path := c.String("output"); path != "" {
+		f, err := createFile(path)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("Unable to write results: %v", err), ExitError)
+		}
+		// Errors closing the file lose results written to it.
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = cli.Exit(fmt.Sprintf("Unable to write results: %v", closeErr), ExitError)
+			}
+		}()
+		stdout = f
+	}
+
+	out, err := complexityWriter(c, stdout, maxCost)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	progress := newProgressLine(c)
+	cfg.ProgressHandler = progress.handler()
+
+	// Results are written as they are analysed. Only the checks and outputs
+	// that need every result keep them.
+	var (
+		violations []complexity.Violation
+		warnings   []complexity.Violation
+		flattened  []complexity.ComplexityAnalysis
+		fragments  []complexity.ComplexityAnalysis
+		deltas     []complexity.ComplexityAnalysis
+		profiled   []complexity.ComplexityAnalysis
+		types      []complexity.ComplexityAnalysis
+		roots      []complexity.ComplexityAnalysis
+		explained  []complexity.ComplexityAnalysis
+		files      []complexity.ComplexityAnalysis
+		documents  []complexity.ComplexityAnalysis
+		analysed   []complexity.ComplexityAnalysis
+		operations = complexity.NewOperationFilter(names)
+		stopped    bool
+		summarized []complexity.ComplexityAnalysis
+	)
+
+	// The operation exceeding a threshold is still written before stopping.
+	if c.Bool("fail-fast") {
+		cfg.StopWhen = func(complexity.ComplexityAnalysis) bool {
+			stopped = len(violations) > 0
+			return stopped
+		}
+	}
+
+	emit := func(r complexity.ComplexityAnalysis) error {
+		// Every operation of a document counts, whether it is reported or not.
+		if maxOps > 0 {
+			documents = append(documents, complexity.ComplexityAnalysis{Path: r.Path})
+		}
+		if len(names) > 0 && !operations.Match(r) {
+			return nil
+		}
+		if c.String("summary-json") != "" {
+			summarized = append(summarized, complexity.ComplexityAnalysis{Complexity: r.Complexity})
+		}
+
+		single := []complexity.ComplexityAnalysis{r}
+		if c.Bool("lint") {
+			warnings = append(warnings, complexity.LintOperations(single)...)
+		}
+		if cfg.Coverage {
+			warnings = append(warnings, complexity.CheckFieldCoverage(single, c.Float("max-field-coverage"))...)
+		}
+		if cfg.StrictVariables {
+			violations = append(violations, complexity.CheckVariables(single)...)
+		}
+		if maxCost != (complexity.ComplexityLimits{}) {
+			violations = append(violations, complexity.CheckComplexityLimits(single, maxCost)...)
+		}
+		if maxDepth > 0 {
+			violations = append(violations, complexity.CheckDepth(single, maxDepth)...)
+		}
+		if maxAliases > 0 {
+			violations = append(violations, complexity.CheckAliases(single, maxAliases)...)
+		}
+		if maxDirectives > 0 {
+			violations = append(violations, complexity.CheckDirectives(single, maxDirectives)...)
+		}
+		if maxArgs > 0 {
+			violations = append(violations, complexity.CheckArguments(single, maxArgs)...)
+		}
+		if maxRoots > 0 {
+			violations = append(violations, complexity.CheckRootFields(single, maxRoots)...)
+		}
+		if maxUnique > 0 {
+			violations = append(violations, complexity.CheckUniqueFields(single, maxUnique)...)
+		}
+		if maxNodes > 0 {
+			violations = append(violations, complexity.CheckResponseNodes(single, maxNodes)...)
+		}
+		if maxFile > 0 {
+			files = append(files, complexity.ComplexityAnalysis{Path: r.Path, Complexity: r.Complexity})
+		}
+		if golden != "" {
+			analysed = append(analysed, complexity.ComplexityAnalysis{
+				Path:          r.Path,
+				OperationName: r.OperationName,
+				Complexity:    r.Complexity,
+				Line:          r.Line,
+				Column:        r.Column,
+			})
+		}
+		if c.Bool("print-flattened") {
+			flattened = append(flattened, r)
+		}
+		if cfg.ByFragment && !structuredFormat(c.String("format")) && len(r.Fragments) > 0 {
+			fragments = append(fragments, r)
+		}
+		if r.Timing != nil {
+			profiled = append(profiled, complexity.ComplexityAnalysis{Path: r.Path, OperationName: r.OperationName, Timing: r.Timing})
+		}
+		if cfg.FragmentDeltas && !structuredFormat(c.String("format")) && len(r.FragmentDeltas) > 0 {
+			deltas = append(deltas, r)
+		}
+		if cfg.ByType && !structuredFormat(c.String("format")) && len(r.Types) > 0 {
+			types = append(types, r)
+		}
+		if cfg.Roots && !structuredFormat(c.String("format")) && len(r.Roots) > 0 {
+			roots = append(roots, r)
+		}
+		if cfg.Explain && !structuredFormat(c.String("format")) && len(r.Explain) > 0 {
+			explained = append(explained, r)
+		}
+
+		progress.Clear()
+		return out.Write(r)
+	}
+
+	if err := complexity.StreamAnalysisFS(ctx, fsys, schemaFind, docFind, emit, complexity.WithConfig(cfg)); err != nil {
+		return analysisExit(err)
+	}
+	if err := out.Close(); err != nil {
+		return cli.Exit("Unable to write results", ExitError)
+	}
+
+	// Operations after the first violation were not looked for.
+	if missing := operations.Missing(); !stopped && len(missing) > 0 {
+		return cli.Exit(fmt.Sprintf("Invalid input: operation(s) not found: %s", strings.Join(missing, ", ")), ExitInvalidInput)
+	}
+
+	if maxFile > 0 {
+		violations = append(violations, complexity.CheckFileComplexity(files, maxFile)...)
+	}
+	if maxOps > 0 {
+		violations = append(violations, complexity.CheckOperationsPerDocument(documents, maxOps)...)
+	}
+
+	if golden != "" && c.Bool("update-golden") {
+		if err := complexity.WriteGolden(golden, analysed); err != nil {
+			return cli.Exit(fmt.Sprintf("Unable to write golden files: %v", err), ExitError)
+		}
+	} else if golden != "" {
+		want, err := complexity.ReadGolden(golden)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return cli.Exit(fmt.Sprintf("Invalid input: no golden files in %s, create them with --update-golden", golden), ExitInvalidInput)
+		case errors.Is(err, complexity.ErrInvalidInput):
+			return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+		case err != nil:
+			return cli.Exit(fmt.Sprintf("Unable to read golden files: %v", err), ExitError)
+		}
+		violations = append(violations, complexity.CheckGolden(want, analysed)...)
+	}
+
+	// The SARIF log reports the violations rather than the results.
+	if c.String("format") == "sarif" {
+		if err := complexity.WriteSARIF(stdout, violations); err != nil {
+			return cli.Exit("Unable to write results", ExitError)
+		}
+	}
+
+	// Structured formats hold the fragments, types, roots and fields of each
+	// result, others get a table of their own.
+	if len(fragments) > 0 {
+		writeFragments(stdout, fragments)
+	}
+	if len(deltas) > 0 {
+		writeFragmentDeltas(stdout, deltas)
+	}
+	if len(types) > 0 {
+		writeTypes(stdout, types)
+	}
+	if len(roots) > 0 {
+		writeRoots(stdout, roots)
+	}
+	if len(explained) > 0 {
+		writeExplain(stdout, explained)
+	}
+
+	for _, r := range flattened {
+		fmt.Fprintf(stdout, "\n# %s: %s\n%s", r.Path, r.OperationName, complexity.PrintOperation(r.Flattened))
+	}
+
+	if path := c.String("summary-json"); path != "" {
+		if err := writeSummary(path, complexity.Summarize(summarized, violations)); err != nil {
+			return cli.Exit(fmt.Sprintf("Unable to write summary: %v", err), ExitError)
+		}
+	}
+
+	if len(profiled) > 0 {
+		writeProfile(os.Stderr, profiled)
+	}
+
+	// Lint and field coverage warnings do not fail the command.
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+
+	if len(violations) > 0 {
+		for _, v := range violations {
+			fmt.Fprintln(os.Stderr, v)
+		}
+		return cli.Exit(fmt.Sprintf("%d threshold violation(s)", len(violations)), ExitThresholdViolation)
+	}
+
+	return nil
+}
+
+// complexityWriter returns the writer of the results to w in the output format
+// selected by the flags of the complexity command. Formats that can highlight
+// the operations exceeding the limits do.
+func complexityWriter(c *cli.Command, w io.Writer, limits complexity.ComplexityLimits) (complexity.ResultWriter, error) {
+	var (
+		out     complexity.ResultWriter
+		format  = c.String("format")
+		groupBy = c.String("group-by")
+	)
+
+	if groupBy != "" && groupBy != "file" {
+		return nil, fmt.Errorf("unknown grouping %q", groupBy)
+	}
+	if c.Int("top") < 0 {
+		return nil, fmt.Errorf("top must not be negative, got %d", c.Int("top"))
+	}
+	if c.Int("min-complexity") < 0 {
+		return nil, fmt.Errorf("min complexity must not be negative, got %d", c.Int("min-complexity"))
+	}
+	if key := c.String("sort"); key != "" && !slices.Contains(complexity.SortKeys(), key) {
+		return nil, fmt.Errorf("unknown sort key %q, valid keys are %s", key, strings.Join(complexity.SortKeys(), ", "))
+	}
+	color, err := colorOutput(c.String("color"), w)
+	if err != nil {
+		return nil, err
+	}
+
+	// The SARIF log reports the violations rather than the results, and is
+	// written once every threshold is checked.
+	if format == "sarif" {
+		out = complexity.NewBufferedWriter(func([]complexity.ComplexityAnalysis) error { return nil })
+	} else {
+		if !slices.Contains(complexity.FormatterNames(), format) {
+			formats := append(complexity.FormatterNames(), "sarif")
+			slices.Sort(formats)
+			return nil, fmt.Errorf("unknown format %q, valid formats are %s", format, strings.Join(formats, ", "))
+		}
+
+		var hidden *complexity.HiddenResults
+		if c.Int("min-complexity") > 0 {
+			hidden = &complexity.HiddenResults{}
+		}
+
+		f, err := complexity.NewFormatter(format, complexity.FormatOptions{
+			Summary:        c.Bool("summary"),
+			Hidden:         hidden,
+			GroupByFile:    groupBy == "file",
+			Budget:         c.Int("budget"),
+			BudgetWarn:     c.Int("budget-warn"),
+			BudgetCritical: c.Int("budget-critical"),
+			Color:          color,
+			Limits:         limits,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = complexity.NewResultWriter(f, w)
+		if hidden != nil {
+			out = minComplexityWriter(out, c.Int("min-complexity"), hidden)
+		}
+	}
+
+	// Files are ranked by their total when results are reported per file,
+	// and the reported results are sorted after ranking them.
+	if key, reverse := c.String("sort"), c.Bool("reverse"); key != "" || reverse {
+		out = sortWriter(out, key, reverse)
+	}
+	if top := c.Int("top"); top > 0 {
+		out = topWriter(out, top)
+	}
+	if c.Bool("per-file") {
+		out = perFileWriter(out)
+	}
+	return out, nil
+}
+
+// analysisConfig builds the analysis configuration from the flags of the
+// complexity command.
+func analysisConfig(c *cli.Command) (complexity.Config, error) {
+	cfg := complexity.Config{
+		Federation:         c.Bool("federation"),
+		SchemaTimeout:      c.Duration("schema-timeout"),
+		SchemaRetries:      c.Int("schema-retries"),
+		Registry:           schemaRegistry(c),
+		RequireMatches:     c.Bool("require-matches"),
+		NoIgnore:           c.Bool("no-ignore"),
+		IgnorePatterns:     c.StringSlice("ignore"),
+		Manifest:           c.Bool("manifest"),
+		Batch:              c.Bool("batch"),
+		Mixed:              c.Bool("mixed"),
+		Profile:            c.Bool("profile"),
+		ListMultiplierArgs: c.StringSlice("list-multiplier-args"),
+		DisabledRules:      c.StringSlice("disable-rule"),
+		DepthDecay:         c.Float("depth-decay"),
+		WorstCase:          c.Bool("worst-case"),
+		TypeConditions:     c.Bool("type-conditions"),
+		StrictVariables:    c.Bool("strict-variables"),
+		FreeDirective:      c.String("free-directive"),
+		CountTypename:      c.Bool("count-typename"),
+	}
+
+	var err error
+	if cfg.InlineSchema, err = inlineSchema(c); err != nil {
+		return cfg, err
+	}
+	if cfg.Logger, err = newLogger(c); err != nil {
+		return cfg, err
+	}
+
+	if base := c.Int("base-complexity"); base != 1 {
+		if base < 0 {
+			return cfg, fmt.Errorf("base complexity must not be negative, got %d", base)
+		}
+		cfg.BaseComplexity = &base
+	}
+	if c.IsSet("scalar-complexity") {
+		scalar := c.Int("scalar-complexity")
+		if scalar < 0 {
+			return cfg, fmt.Errorf("scalar complexity must not be negative, got %d", scalar)
+		}
+		cfg.ScalarComplexity = &scalar
+	}
+
+	// A decay below 1 would make deeper fields more expensive.
+	if cfg.DepthDecay != 0 && cfg.DepthDecay < 1 {
+		return cfg, fmt.Errorf("depth decay must be 0 or at least 1, got %v", cfg.DepthDecay)
+	}
+	coverage := c.Float("max-field-coverage")
+	if coverage < 0 || coverage > 1 {
+		return cfg, fmt.Errorf("max field coverage must be a fraction between 0 and 1, got %v", coverage)
+	}
+	cfg.Coverage = coverage > 0
+
+	// Only the json, yaml and toml formats have room for the operation text.
+	if structuredFormat(c.String("format")) {
+		cfg.IncludeSource = c.Bool("include-source")
+	}
+	if c.String("format") != "sarif" {
+		cfg.ByFragment = c.Bool("by-fragment")
+		cfg.FragmentDeltas = c.Bool("optimize-hints")
+		cfg.ByType = c.Bool("by-type")
+		cfg.Roots = c.Bool("roots")
+		cfg.Explain = c.Bool("explain")
+	}
+
+	if path := c.String("weights"); path != "" {
+		if cfg.FieldWeights, err = readWeightsFile(path); err != nil {
+			return cfg, err
+		}
+	}
+	for _, fw := range c.StringSlice("field-weight") {
+		field, value, ok := strings.Cut(fw, "=")
+		weight, err := strconv.Atoi(value)
+		if !ok || err != nil || weight < 0 || !strings.Contains(field, ".") {
+			return cfg, fmt.Errorf("field weight must be Type.field=N with N not negative, got %q", fw)
+		}
+		if cfg.FieldWeights == nil {
+			cfg.FieldWeights = make(map[string]int)
+		}
+		cfg.FieldWeights[field] = weight
+	}
+
+	for _, rule := range c.StringSlice("argument-cost") {
+		cost, err := complexity.ParseArgumentCost(rule)
+		if err != nil {
+			return cfg, fmt.Errorf("parsing --argument-cost: %w", err)
+		}
+		cfg.ArgumentCosts = append(cfg.ArgumentCosts, cost)
+	}
+
+	for _, field := range c.StringSlice("ignore-field") {
+		if typeName, name, ok := strings.Cut(field, "."); !ok || typeName == "" || name == "" {
+			return cfg, fmt.Errorf("ignored field must be Type.field, got %q", field)
+		}
+		cfg.IgnoreFields = append(cfg.IgnoreFields, field)
+	}
+
+	for _, lsa := range c.StringSlice("list-size-arg") {
+		field, arg, ok := strings.Cut(lsa, "=")
+		if !ok || arg == "" || !strings.Contains(field, ".") {
+			return cfg, fmt.Errorf("list size argument must be Type.field=argument, got %q", lsa)
+		}
+		if cfg.ListSizeArgs == nil {
+			cfg.ListSizeArgs = make(map[string]string)
+		}
+		cfg.ListSizeArgs[field] = arg
+	}
+
+	if vars := c.String("variables"); vars != "" {
+		if err := json.Unmarshal([]byte(vars), &cfg.Variables); err != nil {
+			return cfg, fmt.Errorf("parsing variables: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// structuredFormat reports whether results in the format hold every field of
+// the analysis, rather than a fixed set of columns.
+func structuredFormat(format string) bool {
+	return format == "json" || format == "yaml" || format == "toml"
+}
+
+// readWeightsFile reads the field weights of the YAML file at path, see
+// complexity.ReadFieldWeights.
+func readWeightsFile(path string) (map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening weights file: %w", err)
+	}
+	defer f.Close()
+
+	weights, err := complexity.ReadFieldWeights(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading weights file %s: %w", path, err)
+	}
+	return weights, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the name of the config file read from the root directory when
+// --config is not given.
+const ConfigFile = "gql.yaml"
+
+// fileConfig is the content of a config file. Every value is a default for
+// the flag of the same name and is overridden by the flag.
+type fileConfig struct {
+	Schema           []string          `yaml:"schema"`
+	Docs             string            `yaml:"docs"`
+	Format           string            `yaml:"format"`
+	Thresholds       fileThresholds    `yaml:"thresholds"`
+	FieldWeights     map[string]int    `yaml:"field-weights"`
+	ListSizeArgs     map[string]string `yaml:"list-size-args"`
+	IgnoreFields     []string          `yaml:"ignore-fields"`
+	ArgumentCosts    []string          `yaml:"argument-costs"`
+	Weights          string            `yaml:"weights"`
+	BaseComplexity   *int              `yaml:"base-complexity"`
+	ScalarComplexity *int              `yaml:"scalar-complexity"`
+	Ignore           []string          `yaml:"ignore"`
+}
+
+type fileThresholds struct {
+	MaxComplexity             int `yaml:"max-complexity"`
+	MaxQueryComplexity        int `yaml:"max-query-complexity"`
+	MaxMutationComplexity     int `yaml:"max-mutation-complexity"`
+	MaxSubscriptionComplexity int `yaml:"max-subscription-complexity"`
+	MaxDepth                  int `yaml:"max-depth"`
+	MaxAliasesPerField        int `yaml:"max-aliases-per-field"`
+	MaxDirectivesPerField     int `yaml:"max-directives-per-field"`
+	MaxArguments              int `yaml:"max-arguments"`
+	MaxRootFields             int `yaml:"max-root-fields"`
+	MaxUniqueFields           int `yaml:"max-unique-fields"`
+	MaxResponseNodes          int `yaml:"max-response-nodes"`
+	MaxFileComplexity         int `yaml:"max-file-complexity"`
+	MaxOperationsPerDocument  int `yaml:"max-operations-per-document"`
+}
+
+// readConfigFile reads the config file at path. A missing file is only an
+// error when required is set.
+func readConfigFile(path string, required bool) (*fileConfig, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening config file: %w", err)
+	}
+	defer f.Close()
+
+	var cfg fileConfig
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// applyConfigFile sets the flags of c that were not given on the command line
+// to the values of the config file named by --config, or of the ConfigFile in
+// the --root directory when it exists.
+func applyConfigFile(ctx context.Context, c *cli.Command) (context.Context, error) {
+	path, required := filepath.Join(c.String("root"), ConfigFile), false
+	if c.IsSet("config") {
+		path, required = c.String("config"), true
+	}
+
+	cfg, err := readConfigFile(path, required)
+	if err != nil {
+		return ctx, cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+	if cfg == nil {
+		return ctx, nil
+	}
+
+	values := map[string][]string{
+		"schema":                      cfg.Schema,
+		"docs":                        nonZero(cfg.Docs),
+		"format":                      nonZero(cfg.Format),
+		"max-complexity":              nonZeroInt(cfg.Thresholds.MaxComplexity),
+		"max-query-complexity":        nonZeroInt(cfg.Thresholds.MaxQueryComplexity),
+		"max-mutation-complexity":     nonZeroInt(cfg.Thresholds.MaxMutationComplexity),
+		"max-subscription-complexity": nonZeroInt(cfg.Thresholds.MaxSubscriptionComplexity),
+		"max-depth":                   nonZeroInt(cfg.Thresholds.MaxDepth),
+		"max-aliases-per-field":       nonZeroInt(cfg.Thresholds.MaxAliasesPerField),
+		"max-directives-per-field":    nonZeroInt(cfg.Thresholds.MaxDirectivesPerField),
+		"max-arguments":               nonZeroInt(cfg.Thresholds.MaxArguments),
+		"max-root-fields":             nonZeroInt(cfg.Thresholds.MaxRootFields),
+		"max-unique-fields":           nonZeroInt(cfg.Thresholds.MaxUniqueFields),
+		"max-response-nodes":          nonZeroInt(cfg.Thresholds.MaxResponseNodes),
+		"max-file-complexity":         nonZeroInt(cfg.Thresholds.MaxFileComplexity),
+		"max-operations-per-document": nonZeroInt(cfg.Thresholds.MaxOperationsPerDocument),
+		"base-complexity":             optionalInt(cfg.BaseComplexity),
+		"scalar-complexity":           optionalInt(cfg.ScalarComplexity),
+		"ignore":                      cfg.Ignore,
+		"weights":                     nonZero(cfg.Weights),
+		"ignore-field":                cfg.IgnoreFields,
+		"argument-cost":               cfg.ArgumentCosts,
+	}
+	for field, weight := range cfg.FieldWeights {
+		values["field-weight"] = append(values["field-weight"], fmt.Sprintf("%s=%d", field, weight))
+	}
+	slices.Sort(values["field-weight"])
+	for field, arg := range cfg.ListSizeArgs {
+		values["list-size-arg"] = append(values["list-size-arg"], field+"="+arg)
+	}
+	slices.Sort(values["list-size-arg"])
+
+	for name, vals := range values {
+		if len(vals) == 0 || !hasFlag(c, name) || c.IsSet(name) {
+			continue
+		}
+		for _, v := range vals {
+			if err := c.Set(name, v); err != nil {
+				return ctx, cli.Exit(fmt.Sprintf("Invalid input: config file %s: %s: %v", path, name, err), ExitInvalidInput)
+			}
+		}
+	}
+
+	return ctx, nil
+}
+
+// hasFlag reports whether c or one of its parents defines the flag name.
+// Config values for flags of other commands are skipped.
+func hasFlag(c *cli.Command, name string) bool {
+	for _, cmd := range c.Lineage() {
+		for _, f := range cmd.Flags {
+			if slices.Contains(f.Names(), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func nonZero(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+func nonZeroInt(n int) []string {
+	if n == 0 {
+		return nil
+	}
+	return []string{strconv.Itoa(n)}
+}
+
+func optionalInt(n *int) []string {
+	if n == nil {
+		return nil
+	}
+	return []string{strconv.Itoa(*n)}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestApplyConfigFileThreshold(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gql.yaml")
+	if err := os.WriteFile(path, []byte("thresholds:\n  max-complexity: 50\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{
+			name: "config value when flag is absent",
+			args: []string{"gql", "--config", path},
+			want: 50,
+		},
+		{
+			name: "flag overrides config value",
+			args: []string{"gql", "--config", path, "--max-complexity", "10"},
+			want: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			cmd := &cli.Command{
+				Name: "gql",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "config"},
+					&cli.IntFlag{Name: "max-complexity"},
+				},
+				Before: applyConfigFile,
+				Action: func(_ context.Context, c *cli.Command) error {
+					got = c.Int("max-complexity")
+					return nil
+				},
+			}
+
+			if err := cmd.Run(context.Background(), tt.args); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("max-complexity = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyConfigFileMissing(t *testing.T) {
+	cmd := &cli.Command{
+		Name:   "gql",
+		Flags:  []cli.Flag{&cli.StringFlag{Name: "config"}},
+		Before: applyConfigFile,
+		Action: func(context.Context, *cli.Command) error { return nil },
+		// The exit error must be returned rather than exit the test binary.
+		ExitErrHandler: func(context.Context, *cli.Command, error) {},
+	}
+
+	err := cmd.Run(context.Background(), []string{"gql", "--config", filepath.Join(t.TempDir(), "missing.yaml")})
+	if err == nil {
+		t.Fatal("Run() error = nil, want an error for a missing config file")
+	}
+}
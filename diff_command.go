@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	DiffCommandName        = "diff"
+	DiffCommandUsage       = "Compare the complexity of two sets of GraphQL documents"
+	DiffCommandDescription = `Analyze two sets of documents against the same schema and report how the
+complexity of each operation changed. Operations are matched by file path and
+operation name, operations only present in one set are marked added or removed.`
+)
+
+func diffCommand() *cli.Command {
+	return &cli.Command{
+		Name:        DiffCommandName,
+		Usage:       DiffCommandUsage,
+		Description: DiffCommandDescription,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "before",
+				Usage:    "Glob pattern to search for graphql files before the change",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "after",
+				Usage:    "Glob pattern to search for graphql files after the change",
+				Required: true,
+			},
+		},
+		Action: runDiff,
+	}
+}
+
+func runDiff(ctx context.Context, c *cli.Command) error {
+	cfg, err := analysisConfig(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	fsys, err := rootFS(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	schemaFind, err := schemaGlobs(c, fsys)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+	cfg.ProgressHandler = newProgressLine(c).handler()
+
+	before, err := complexity.RunAnalysisFS(ctx, fsys, schemaFind, c.String("before"), complexity.WithConfig(cfg))
+	if err != nil {
+		return analysisExit(err)
+	}
+
+	after, err := complexity.RunAnalysisFS(ctx, fsys, schemaFind, c.String("after"), complexity.WithConfig(cfg))
+	if err != nil {
+		return analysisExit(err)
+	}
+
+	if err := writeChanges(os.Stdout, complexity.Compare(before, after)); err != nil {
+		return cli.Exit("Unable to flush writer", ExitError)
+	}
+
+	return nil
+}
+
+// schemaDiffConflicts are the flags of the complexity command selecting,
+// writing or checking results, which the table of changes written by
+// runSchemaDiff has no room for.
+var schemaDiffConflicts = []string{
+	"operation", "since", "format", "output", "golden", "update-golden", "summary-json",
+	"group-by", "include-source", "by-fragment", "optimize-hints", "by-type", "roots",
+	"explain", "summary", "top", "sort", "reverse", "min-complexity", "per-file",
+	"budget", "budget-warn", "budget-critical", "print-flattened", "fail-fast", "lint",
+	"max-field-coverage", "max-complexity", "max-query-complexity", "max-mutation-complexity",
+	"max-subscription-complexity", "max-depth", "max-aliases-per-field",
+	"max-directives-per-field", "max-arguments", "max-root-fields", "max-unique-fields",
+	"max-response-nodes", "max-file-complexity", "max-operations-per-document",
+}
+
+// runSchemaDiff analyzes the documents against the schemas of --schema-before
+// and --schema-after and reports how the complexity of each operation
+// changed.
+func runSchemaDiff(ctx context.Context, c *cli.Command, fsys fs.FS, cfg complexity.Config) error {
+	before, after := c.String("schema-before"), c.String("schema-after")
+	if before == "" || after == "" {
+		return cli.Exit("Invalid input: --schema-before and --schema-after must both be given", ExitInvalidInput)
+	}
+
+	var conflicts []string
+	for _, name := range schemaDiffConflicts {
+		if c.IsSet(name) {
+			conflicts = append(conflicts, "--"+name)
+		}
+	}
+	if len(conflicts) > 0 {
+		return cli.Exit(fmt.Sprintf("Invalid input: --schema-before and --schema-after cannot be combined with %s", strings.Join(conflicts, ", ")), ExitInvalidInput)
+	}
+	cfg.ProgressHandler = newProgressLine(c).handler()
+
+	changes, err := complexity.CompareSchemasFS(ctx, fsys, before, after, c.String("docs"), complexity.WithConfig(cfg))
+	if err != nil {
+		return analysisExit(err)
+	}
+
+	if err := writeChanges(os.Stdout, changes); err != nil {
+		return cli.Exit("Unable to flush writer", ExitError)
+	}
+
+	return nil
+}
+
+// writeChanges writes a table of the complexity of each operation before and
+// after a change. Operations missing on one side have their status in place
+// of the delta.
+func writeChanges(out io.Writer, changes []complexity.Change) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "File:\tOperation:\tBefore:\tAfter:\tDelta:\n")
+
+	for _, change := range changes {
+		switch change.Status {
+		case complexity.ChangeAdded, complexity.ChangeInvalidBefore:
+			fmt.Fprintf(w, "%s\t%s\t-\t%d\t%s\n", change.Path, change.OperationName, change.After, change.Status)
+		case complexity.ChangeRemoved, complexity.ChangeInvalidAfter:
+			fmt.Fprintf(w, "%s\t%s\t%d\t-\t%s\n", change.Path, change.OperationName, change.Before, change.Status)
+		default:
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%+d\n", change.Path, change.OperationName, change.Before, change.After, change.Delta)
+		}
+	}
+
+	return w.Flush()
+}
+
+// analysisExit converts an error from running the analysis into an exit error
+// with the matching exit code.
+func analysisExit(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return cli.Exit("Interrupted", ExitError)
+	case errors.Is(err, complexity.ErrInvalidInput):
+		// Lists of GraphQL errors end every error with a newline.
+		return cli.Exit(fmt.Sprintf("Invalid input: %s", strings.TrimSuffix(err.Error(), "\n")), ExitInvalidInput)
+	case errors.Is(err, complexity.ErrIntrospectionDisabled):
+		return cli.Exit(fmt.Sprintf("Schema server refused introspection: %v", err), ExitInvalidInput)
+	case errors.Is(err, complexity.ErrSchemaUnreachable):
+		return cli.Exit(fmt.Sprintf("Schema server could not be reached: %v", err), ExitError)
+	}
+	return cli.Exit("Unable to calculate complexity", ExitError)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// changedFiles returns the files changed between the merge base of ref and
+// HEAD and HEAD, relative to the root directory. Files outside of the root
+// are left out. Refs starting with a dash are rejected, as git would read
+// them as options.
+func changedFiles(ctx context.Context, root, ref string) ([]string, error) {
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("--since must be a git ref, got %q", ref)
+	}
+	if _, err := git(ctx, "-C", root, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("--since needs a git repository: %w", err)
+	}
+
+	out, err := git(ctx, "-C", root, "diff", "--name-only", "--relative", ref+"...HEAD", "--")
+	if err != nil {
+		return nil, fmt.Errorf("listing files changed since %s: %w", ref, err)
+	}
+
+	// A non-nil list selects no documents when no files changed.
+	files := []string{}
+	for line := range strings.Lines(out) {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// git runs git with the arguments and returns its output. Errors include what
+// git wrote to stderr.
+func git(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
@@ -4,8 +4,10 @@ go 1.25.0
 
 require (
 	github.com/99designs/gqlgen v0.17.81
+	github.com/BurntSushi/toml v1.6.0
 	github.com/urfave/cli/v3 v3.5.0
 	github.com/vektah/gqlparser/v2 v2.5.31
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// gqlgenConfig holds the fields of a gqlgen config file, such as gqlgen.yml,
+// that gql honors. Other fields are ignored.
+type gqlgenConfig struct {
+	Schema stringList `yaml:"schema"`
+}
+
+// stringList is a YAML list of strings that may also be written as a single
+// string, as gqlgen allows.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = stringList{value.Value}
+		return nil
+	}
+	return value.Decode((*[]string)(l))
+}
+
+// gqlgenDefaultSchema is the schema gqlgen reads when its config file names
+// none.
+const gqlgenDefaultSchema = "schema.graphql"
+
+// readGqlgenSchema returns the schema globs of the gqlgen config file named
+// name in fsys. gqlgen resolves them relative to the directory of the config
+// file, which must therefore be in fsys.
+func readGqlgenSchema(fsys fs.FS, name string) ([]string, error) {
+	name = path.Clean(filepath.ToSlash(name))
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("opening gqlgen config: %w", err)
+	}
+	defer f.Close()
+
+	var cfg gqlgenConfig
+	if err := yaml.NewDecoder(f).Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing gqlgen config %s: %w", name, err)
+	}
+	if len(cfg.Schema) == 0 {
+		cfg.Schema = stringList{gqlgenDefaultSchema}
+	}
+
+	globs := make([]string, len(cfg.Schema))
+	for i, glob := range cfg.Schema {
+		globs[i] = path.Join(path.Dir(name), glob)
+	}
+	return globs, nil
+}
+
+// schemaGlobs returns the comma separated schema globs given with --schema,
+// or those of the gqlgen config file in fsys given with --gqlgen-config,
+// which replaces every other way of giving the schema.
+func schemaGlobs(c *cli.Command, fsys fs.FS) (string, error) {
+	name := c.String("gqlgen-config")
+	if name == "" {
+		return strings.Join(c.StringSlice("schema"), ","), nil
+	}
+
+	for _, name := range []string{"schema", "schema-inline", "schema-registry"} {
+		if c.IsSet(name) {
+			return "", fmt.Errorf("--%s and --gqlgen-config cannot both be used", name)
+		}
+	}
+
+	globs, err := readGqlgenSchema(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(globs, ","), nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/urfave/cli/v3"
+)
+
+func TestReadGqlgenSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected []string
+	}{
+		{
+			name:     "list",
+			config:   "schema:\n  - graph/*.graphqls\n  - extra.graphql\nexec:\n  filename: graph/generated.go\n",
+			expected: []string{"api/graph/*.graphqls", "api/extra.graphql"},
+		},
+		{
+			name:     "single string",
+			config:   "schema: graph/**/*.graphqls\n",
+			expected: []string{"api/graph/**/*.graphqls"},
+		},
+		{
+			name:     "default",
+			config:   "model:\n  filename: graph/model/models_gen.go\n",
+			expected: []string{"api/schema.graphql"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"api/gqlgen.yml": {Data: []byte(tt.config)}}
+
+			got, err := readGqlgenSchema(fsys, filepath.Join("api", "gqlgen.yml"))
+			if err != nil {
+				t.Fatalf("readGqlgenSchema() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("readGqlgenSchema() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGqlgenConfigAnalysis(t *testing.T) {
+	fsys := fstest.MapFS{
+		"gqlgen.yml":             {Data: []byte("schema:\n  - graph/*.graphqls\n")},
+		"graph/schema.graphqls":  {Data: []byte("type Query {\n  user(id: ID!): User\n}\n")},
+		"graph/user.graphqls":    {Data: []byte("type User {\n  id: ID!\n  name: String!\n}\n")},
+		"documents/user.graphql": {Data: []byte("query GetUser { user(id: 1) { id name } }\n")},
+	}
+
+	var schema string
+	cmd := &cli.Command{
+		Name: "gql",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{Name: "schema", Value: []string{"*.graphqls"}},
+			&cli.StringFlag{Name: "schema-inline"},
+			&cli.StringFlag{Name: "schema-registry"},
+			&cli.StringFlag{Name: "gqlgen-config"},
+		},
+		Action: func(_ context.Context, c *cli.Command) (err error) {
+			schema, err = schemaGlobs(c, fsys)
+			return err
+		},
+	}
+	if err := cmd.Run(context.Background(), []string{"gql", "--gqlgen-config", "gqlgen.yml"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	results, err := complexity.RunAnalysisFS(t.Context(), fsys, schema, "documents/*.graphql")
+	if err != nil {
+		t.Fatalf("RunAnalysisFS() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Complexity != 3 {
+		t.Errorf("RunAnalysisFS() = %+v, want GetUser with complexity 3", results)
+	}
+
+	if err := cmd.Run(context.Background(), []string{"gql", "--gqlgen-config", "gqlgen.yml", "--schema", "graph/*.graphqls"}); err == nil {
+		t.Error("Run() with --schema and --gqlgen-config succeeded, want an error")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	ListCommandName        = "list"
+	ListCommandUsage       = "List the operations of GraphQL documents"
+	ListCommandDescription = `List every operation of the documents with its file and type, without loading
+a schema or analyzing complexity, such as to build an allowlist of persisted
+queries. Operations of the same name in several files are all listed.
+
+Exit codes:
+  0  success
+  1  internal or IO error
+  3  invalid input`
+)
+
+func listCommand() *cli.Command {
+	return &cli.Command{
+		Name:        ListCommandName,
+		Usage:       ListCommandUsage,
+		Description: ListCommandDescription,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "docs",
+				Usage: "Comma separated glob patterns to search for graphql files, or .zip, .tar or .tar.gz archives of them",
+				Value: complexity.DefaultDocuments,
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format, table or json",
+				Value: "table",
+			},
+			&cli.BoolFlag{
+				Name:  "manifest",
+				Usage: "Read documents as persisted query manifests, files ending in .json always are",
+			},
+			&cli.BoolFlag{
+				Name:  "no-ignore",
+				Usage: "List files excluded by the .gqlignore file",
+			},
+			&cli.BoolFlag{
+				Name:  "require-matches",
+				Usage: "Fail when the document glob matches no files instead of warning",
+			},
+			&cli.StringSliceFlag{
+				Name:  "ignore",
+				Usage: "Gitignore style pattern of files to leave out in addition to the .gqlignore file",
+			},
+		},
+		Before: applyConfigFile,
+		Action: runList,
+	}
+}
+
+func runList(ctx context.Context, c *cli.Command) error {
+	format := c.String("format")
+	if format != "table" && format != "json" {
+		return cli.Exit(fmt.Sprintf("Invalid input: unknown format %q, valid formats are json, table", format), ExitInvalidInput)
+	}
+
+	cfg := complexity.Config{
+		RequireMatches: c.Bool("require-matches"),
+		NoIgnore:       c.Bool("no-ignore"),
+		IgnorePatterns: c.StringSlice("ignore"),
+		Manifest:       c.Bool("manifest"),
+	}
+
+	var err error
+	if cfg.Logger, err = newLogger(c); err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	fsys, err := rootFS(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	operations, err := complexity.ListOperationsFS(ctx, fsys, c.String("docs"), complexity.WithConfig(cfg))
+	if err != nil {
+		return analysisExit(err)
+	}
+
+	if format == "json" {
+		// An empty list is written as [] rather than null.
+		if operations == nil {
+			operations = []complexity.Operation{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(operations); err != nil {
+			return cli.Exit("Unable to write results", ExitError)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "File:\tOperation:\tType:\n")
+	for _, op := range operations {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", op.Path, op.OperationName, op.OperationType)
+	}
+	if err := w.Flush(); err != nil {
+		return cli.Exit("Unable to write results", ExitError)
+	}
+	return nil
+}
@@ -2,80 +2,167 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
+	"log/slog"
 	"os"
-	"text/tabwriter"
+	"os/signal"
+	"time"
 
 	"github.com/asger-noer/gql/complexity"
 	"github.com/urfave/cli/v3"
 )
 
+// Exit codes returned by the gql command.
 const (
-	ComplexityCommandName        = "complexity"
-	ComplexityCommandUsage       = "Analyze GraphQL query complexity"
-	ComplexityCommandDescription = `Analyze the complexity of GraphQL operations based on the provided schema.
-
-The complexity is calculated using the folling rules from gqlgen:
-- Each field has a base complexity of 1.
-- Interfaces have the complexity of their most complex implementing type.`
+	ExitSuccess            = 0 // Analysis completed without violations.
+	ExitError              = 1 // Internal or IO error.
+	ExitThresholdViolation = 2 // One or more operations exceeded a configured threshold.
+	ExitInvalidInput       = 3 // Invalid input such as a bad glob or an unparseable schema.
 )
 
 func main() {
-	ctx := context.Background()
+	// Interrupting the command aborts in-flight schema fetches.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	cmd := &cli.Command{
+	if err := newCommand().Run(ctx, os.Args); err != nil {
+		// Errors reaching this point come from parsing the command line.
+		fmt.Fprintln(os.Stderr, err)
+		stop()
+		os.Exit(ExitInvalidInput)
+	}
+}
+
+// newCommand returns the gql command and its subcommands.
+func newCommand() *cli.Command {
+	return &cli.Command{
 		Name:  "gql",
 		Usage: "GraphQL utilities",
 		Flags: []cli.Flag{
-			&cli.StringFlag{
+			&cli.StringSliceFlag{
 				Name:    "schema",
 				Aliases: []string{"s"},
-				Usage:   "Glob pattern to search for graphql schema files",
-				Value:   "*.graphqls",
+				Usage:   "Glob pattern to search for graphql schema files or URL to introspect, use name=glob to load a named schema",
+				Value:   []string{"*.graphqls"},
+			},
+			&cli.StringFlag{
+				Name:  "schema-inline",
+				Usage: "SDL of the schema, such as 'type Query { ... }', in place of plain --schema globs",
+			},
+			&cli.StringFlag{
+				Name:  "gqlgen-config",
+				Usage: "Path of a gqlgen config file, such as gqlgen.yml, whose schema globs are loaded in place of --schema",
+			},
+			&cli.StringFlag{
+				Name:  "schema-registry",
+				Usage: "Graph ref, such as my-graph@production, whose latest schema is fetched from the schema registry in place of plain --schema globs",
+			},
+			&cli.StringFlag{
+				Name:  "schema-registry-url",
+				Usage: "URL of the schema registry API",
+				Value: complexity.DefaultRegistryURL,
+			},
+			&cli.StringFlag{
+				Name:    "schema-registry-key",
+				Usage:   "API key of the schema registry",
+				Sources: cli.EnvVars("APOLLO_KEY"),
+			},
+			&cli.StringFlag{
+				Name:    "root",
+				Aliases: []string{"C"},
+				Usage:   "Directory to match schema and document globs in, and to report their paths relative to",
+				Value:   ".",
+			},
+			&cli.StringFlag{
+				Name:  "config",
+				Usage: "Path of a YAML file with defaults for the flags, gql.yaml is read when it exists",
+			},
+			&cli.DurationFlag{
+				Name:  "schema-timeout",
+				Usage: "Time limit for each attempt to introspect a schema URL",
+				Value: 30 * time.Second,
+			},
+			&cli.IntFlag{
+				Name:  "schema-retries",
+				Usage: "Number of times to retry introspecting a schema URL that could not be reached, with exponential backoff",
+				Value: 2,
+			},
+			&cli.StringFlag{
+				Name:  "log-level",
+				Usage: "Lowest level of the messages logged to stderr, one of debug, info, warn or error",
+				Value: "info",
+			},
+			&cli.BoolFlag{
+				Name:  "verbose",
+				Usage: "Log debug messages, such as @skip and @include conditions that could not be decided, like --log-level debug",
 			},
 		},
 		Commands: []*cli.Command{
-			{
-				Name:        ComplexityCommandName,
-				Usage:       ComplexityCommandUsage,
-				Description: ComplexityCommandDescription,
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:  "docs",
-						Usage: "Glob pattern to search for graphql files",
-						Value: "*.graphql",
-					},
-				},
-				Action: func(ctx context.Context, c *cli.Command) error {
-					var (
-						schemaFind = c.String("schema")
-						docFind    = c.String("docs")
-					)
-
-					result, err := complexity.RunAnalysis(ctx, schemaFind, docFind)
-					if err != nil {
-						return cli.Exit("Unable to calculate complexity", 1)
-					}
+			complexityCommand(),
+			validateCommand(),
+			listCommand(),
+			normalizeCommand(),
+		},
+	}
+}
 
-					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-					fmt.Fprintf(w, "File:\tOperation:\tComplexity:\tFlattened Complexity:\n")
-					defer w.Flush()
+// rootFS returns the directory given with --root, in which schema and
+// document globs are matched.
+func rootFS(c *cli.Command) (fs.FS, error) {
+	root := c.String("root")
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("opening root: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("root %s is not a directory", root)
+	}
+	return os.DirFS(root), nil
+}
 
-					for _, r := range result {
-						fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", r.Path, r.OperationName, r.Complexity, r.FlattenedComplexity)
-						if err := w.Flush(); err != nil {
-							return cli.Exit("Unable to flush writer", 1)
-						}
-					}
+// inlineSchema returns the SDL given with --schema-inline, which cannot be
+// combined with --schema.
+func inlineSchema(c *cli.Command) (string, error) {
+	if c.IsSet("schema") && c.IsSet("schema-inline") {
+		return "", errors.New("--schema and --schema-inline cannot both be used")
+	}
+	return c.String("schema-inline"), nil
+}
 
-					return nil
-				},
-			},
-		},
+// schemaRegistry returns the schema registry selected by the flags, or nil
+// when schemas are only loaded from --schema.
+func schemaRegistry(c *cli.Command) *complexity.Registry {
+	if c.String("schema-registry") == "" {
+		return nil
 	}
+	return &complexity.Registry{
+		URL:      c.String("schema-registry-url"),
+		GraphRef: c.String("schema-registry"),
+		APIKey:   c.String("schema-registry-key"),
+	}
+}
 
-	if err := cmd.Run(ctx, os.Args); err != nil {
-		log.Fatal(err)
+// newLogger returns the logger writing the messages of the analysis at or
+// above the level of --log-level, or --verbose, to stderr.
+func newLogger(c *cli.Command) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.String("log-level"))); err != nil {
+		return nil, fmt.Errorf("log level must be one of debug, info, warn or error, got %q", c.String("log-level"))
+	}
+	if c.Bool("verbose") {
+		level = min(level, slog.LevelDebug)
 	}
+
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		// The time of each message only adds noise to the output of a command.
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})), nil
 }
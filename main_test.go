@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/urfave/cli/v3"
+)
+
+// TestMain runs the gql command rather than the tests when GQL_RUN_MAIN is
+// set, so that tests can run it as a process and check its exit code.
+func TestMain(m *testing.M) {
+	if os.Getenv("GQL_RUN_MAIN") != "" {
+		main()
+		os.Exit(ExitSuccess)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRootFlag(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "services", "api")
+	files := map[string]string{
+		"schema.graphqls":         "type Query {\n  user(id: ID!): User\n}\n\ntype User {\n  id: ID!\n  name: String!\n}\n",
+		"queries/user.graphql":    "query GetUser { user(id: 1) { id name } }\n",
+		"queries/user_id.graphql": "query GetUserID { user(id: 1) { id } }\n",
+		"queries/ignored.graphql": "query Ignored { user(id: 1) { id } }\n",
+		".gqlignore":              "queries/ignored.graphql\n",
+		"gql.yaml":                "docs: queries/*.graphql\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Globs, the ignore file and the config file are found below the root
+	// rather than the working directory.
+	t.Chdir(dir)
+	output := filepath.Join(dir, "results.json")
+	args := []string{"gql", "-C", filepath.Join("services", "api"), "complexity", "--format", "json", "--output", output}
+	if err := newCommand().Run(t.Context(), args); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []struct {
+		Path       string `json:"path"`
+		Operation  string `json:"operation"`
+		Complexity int    `json:"complexity"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatalf("decoding results: %v", err)
+	}
+
+	expected := []struct {
+		Path       string `json:"path"`
+		Operation  string `json:"operation"`
+		Complexity int    `json:"complexity"`
+	}{
+		{Path: "queries/user.graphql", Operation: "GetUser", Complexity: 3},
+		{Path: "queries/user_id.graphql", Operation: "GetUserID", Complexity: 2},
+	}
+	if diff := cmp.Diff(expected, results); diff != "" {
+		t.Errorf("results mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRootFlagMissing(t *testing.T) {
+	cmd := newCommand()
+	// Keep the exit code error from exiting the test.
+	cmd.ExitErrHandler = func(context.Context, *cli.Command, error) {}
+
+	args := []string{"gql", "--root", filepath.Join(t.TempDir(), "missing"), "complexity"}
+	err := cmd.Run(t.Context(), args)
+	var exitErr cli.ExitCoder
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != ExitInvalidInput {
+		t.Errorf("Run() with a missing root error = %v, want exit code %d", err, ExitInvalidInput)
+	}
+}
+
+func TestOutputFlag(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"schema.graphqls": "type Query {\n  user(id: ID!): User\n}\n\ntype User {\n  id: ID!\n}\n",
+		"user.graphql":    "query GetUser { user(id: 1) { id } }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, format := range []string{"table", "compact", "markdown", "html", "json", "yaml", "toml", "sarif"} {
+		t.Run(format, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "reports", format, "results")
+			args := []string{"gql", "-C", dir, "complexity", "--format", format, "-o", output}
+			if err := newCommand().Run(t.Context(), args); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) == 0 {
+				t.Error("output file is empty")
+			}
+		})
+	}
+
+	t.Run("unwritable", func(t *testing.T) {
+		cmd := newCommand()
+		cmd.ExitErrHandler = func(context.Context, *cli.Command, error) {}
+
+		// The parent of the output is a file rather than a directory.
+		output := filepath.Join(dir, "user.graphql", "results.json")
+		err := cmd.Run(t.Context(), []string{"gql", "-C", dir, "complexity", "-o", output})
+		var exitErr cli.ExitCoder
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != ExitError {
+			t.Errorf("Run() error = %v, want exit code %d", err, ExitError)
+		}
+	})
+}
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"schema.graphqls":  "type Query {\n  user(id: ID!): User\n}\n\ntype User {\n  id: ID!\n}\n",
+		"invalid.graphqls": "type Query {\n",
+		"user.graphql":     "query GetUser { user(id: 1) { id } }\n",
+		"aliases.graphql":  "query GetUsers { a: user(id: 1) { id } b: user(id: 2) { id } }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Writing the results to a file opened for reading fails.
+	readOnly, err := os.Open(filepath.Join(dir, "user.graphql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readOnly.Close()
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdout *os.File
+		want   int
+	}{
+		{
+			name: "success",
+			args: []string{"--schema", "schema.graphqls", "complexity"},
+			want: ExitSuccess,
+		},
+		{
+			name:   "error",
+			args:   []string{"--schema", "schema.graphqls", "complexity"},
+			stdout: readOnly,
+			want:   ExitError,
+		},
+		{
+			name: "threshold violation",
+			args: []string{"--schema", "schema.graphqls", "complexity", "--max-aliases-per-field", "1"},
+			want: ExitThresholdViolation,
+		},
+		{
+			name: "invalid input",
+			args: []string{"--schema", "invalid.graphqls", "complexity"},
+			want: ExitInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runMain(t, dir, tt.stdout, tt.args...); got != tt.want {
+				t.Errorf("gql %v exit code = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+// runMain runs the gql command with the arguments in dir as a process and
+// returns its exit code. Its output is discarded unless stdout is given.
+func runMain(t *testing.T, dir string, stdout *os.File, args ...string) int {
+	t.Helper()
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GQL_RUN_MAIN=1")
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ExitSuccess
+}
+
+func TestSchemaDiffConflicts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"schema.graphqls": "type Query {\n  user(id: ID!): User\n}\n\ntype User {\n  id: ID!\n}\n",
+		"user.graphql":    "query GetUser { user(id: 1) { id } }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	complexityCmd := newCommand()
+	for _, name := range schemaDiffConflicts {
+		if !hasFlag(complexityCmd.Command(ComplexityCommandName), name) {
+			t.Errorf("schemaDiffConflicts names %q, which is not a flag of the complexity command", name)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{
+			name: "threshold",
+			args: []string{"--max-complexity", "1"},
+			want: ExitInvalidInput,
+		},
+		{
+			name: "output",
+			args: []string{"--format", "json", "-o", filepath.Join(t.TempDir(), "results")},
+			want: ExitInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCommand()
+			// Keep the exit code error from exiting the test.
+			cmd.ExitErrHandler = func(context.Context, *cli.Command, error) {}
+
+			args := []string{"gql", "-C", dir, "complexity", "--quiet", "--schema-before", "schema.graphqls", "--schema-after", "schema.graphqls"}
+			err := cmd.Run(t.Context(), append(args, tt.args...))
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("Run() error = %v, exit code %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
+
+// exitCode returns the exit code the command exits with after returning err.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	var exitErr cli.ExitCoder
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	// Errors without an exit code come from parsing the command line.
+	return ExitInvalidInput
+}
+
+func TestChangedFilesOptionRef(t *testing.T) {
+	// The ref would make git diff write its output to a file.
+	output := filepath.Join(t.TempDir(), "diff")
+	if _, err := changedFiles(t.Context(), ".", "--output="+output); err == nil {
+		t.Error("changedFiles() with an option as ref succeeded, want an error")
+	}
+	if _, err := os.Stat(output); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("git wrote %s, stat error = %v", output, err)
+	}
+}
+
+func TestDepthDecayFlagBelowOne(t *testing.T) {
+	for _, decay := range []string{"-1", "0.5"} {
+		t.Run(decay, func(t *testing.T) {
+			cmd := newCommand()
+			// Keep the exit code error from exiting the test.
+			cmd.ExitErrHandler = func(context.Context, *cli.Command, error) {}
+
+			args := []string{"gql", "-C", t.TempDir(), "complexity", "--depth-decay", decay}
+			if err := cmd.Run(t.Context(), args); exitCode(err) != ExitInvalidInput {
+				t.Errorf("Run() error = %v, want exit code %d", err, ExitInvalidInput)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	NormalizeCommandName        = "normalize"
+	NormalizeCommandUsage       = "Print the canonical form of the operations of GraphQL documents"
+	NormalizeCommandDescription = `Print every operation of the documents in a canonical form, for stable diffs
+and deduplicating persisted queries, without loading a schema. Fragments are
+inlined, those with a type condition into one inline fragment per type, and
+repeated fields merged, variables, arguments and input object fields are
+sorted by name, and the operation is formatted with consistent whitespace and
+without comments. Operations differing only in these respects
+are printed the same.
+
+Exit codes:
+  0  success
+  1  internal or IO error
+  3  invalid input`
+)
+
+func normalizeCommand() *cli.Command {
+	return &cli.Command{
+		Name:        NormalizeCommandName,
+		Usage:       NormalizeCommandUsage,
+		Description: NormalizeCommandDescription,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "docs",
+				Usage: "Comma separated glob patterns to search for graphql files, or .zip, .tar or .tar.gz archives of them",
+				Value: complexity.DefaultDocuments,
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Write a JSON list of the operations with their path, name, type and canonical form",
+			},
+			&cli.BoolFlag{
+				Name:  "manifest",
+				Usage: "Read documents as persisted query manifests, files ending in .json always are",
+			},
+			&cli.BoolFlag{
+				Name:  "no-ignore",
+				Usage: "Normalize files excluded by the .gqlignore file",
+			},
+			&cli.BoolFlag{
+				Name:  "require-matches",
+				Usage: "Fail when the document glob matches no files instead of warning",
+			},
+			&cli.StringSliceFlag{
+				Name:  "ignore",
+				Usage: "Gitignore style pattern of files to leave out in addition to the .gqlignore file",
+			},
+		},
+		Before: applyConfigFile,
+		Action: runNormalize,
+	}
+}
+
+func runNormalize(ctx context.Context, c *cli.Command) error {
+	cfg := complexity.Config{
+		RequireMatches: c.Bool("require-matches"),
+		NoIgnore:       c.Bool("no-ignore"),
+		IgnorePatterns: c.StringSlice("ignore"),
+		Manifest:       c.Bool("manifest"),
+	}
+
+	var err error
+	if cfg.Logger, err = newLogger(c); err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	fsys, err := rootFS(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	operations, err := complexity.NormalizeOperationsFS(ctx, fsys, c.String("docs"), complexity.WithConfig(cfg))
+	if err != nil {
+		return analysisExit(err)
+	}
+
+	if c.Bool("json") {
+		// An empty list is written as [] rather than null.
+		if operations == nil {
+			operations = []complexity.NormalizedOperation{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(operations); err != nil {
+			return cli.Exit("Unable to write results", ExitError)
+		}
+		return nil
+	}
+
+	// Each operation is preceded by a comment naming it, so the output stays
+	// a GraphQL document.
+	for i, op := range operations {
+		if i > 0 {
+			fmt.Fprintln(os.Stdout)
+		}
+		if _, err := fmt.Fprintf(os.Stdout, "# %s: %s\n%s", op.Path, op.OperationName, op.Normalized); err != nil {
+			return cli.Exit("Unable to write results", ExitError)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/asger-noer/gql/complexity"
+)
+
+// perFileWriter writes a single result per file with the summed complexity of
+// its operations to next.
+func perFileWriter(next complexity.ResultWriter) complexity.ResultWriter {
+	return complexity.NewBufferedWriter(func(rows []complexity.ComplexityAnalysis) error {
+		for _, r := range complexity.AggregateByFile(rows) {
+			if err := next.Write(r); err != nil {
+				return err
+			}
+		}
+		return next.Close()
+	})
+}
+
+// topWriter writes the n most complex results in descending order of
+// complexity to next, or every result sorted when n is zero.
+func topWriter(next complexity.ResultWriter, n int) complexity.ResultWriter {
+	return complexity.NewBufferedWriter(func(rows []complexity.ComplexityAnalysis) error {
+		for _, r := range complexity.TopN(rows, n) {
+			if err := next.Write(r); err != nil {
+				return err
+			}
+		}
+		return next.Close()
+	})
+}
+
+// minComplexityWriter writes the results with a complexity of at least min to
+// next, counting the others in hidden.
+func minComplexityWriter(next complexity.ResultWriter, min int, hidden *complexity.HiddenResults) complexity.ResultWriter {
+	return &filterWriter{next: next, filter: func(r complexity.ComplexityAnalysis) bool {
+		return !hidden.Hides(r, min)
+	}}
+}
+
+// filterWriter writes the results passing the filter to next as they are
+// written.
+type filterWriter struct {
+	next   complexity.ResultWriter
+	filter func(complexity.ComplexityAnalysis) bool
+}
+
+func (f *filterWriter) Write(r complexity.ComplexityAnalysis) error {
+	if !f.filter(r) {
+		return nil
+	}
+	return f.next.Write(r)
+}
+
+func (f *filterWriter) Close() error {
+	return f.next.Close()
+}
+
+// sortWriter writes the results to next sorted by key, see
+// complexity.SortResults.
+func sortWriter(next complexity.ResultWriter, key string, reverse bool) complexity.ResultWriter {
+	return complexity.NewBufferedWriter(func(rows []complexity.ComplexityAnalysis) error {
+		sorted, err := complexity.SortResults(rows, key, reverse)
+		if err != nil {
+			return err
+		}
+		for _, r := range sorted {
+			if err := next.Write(r); err != nil {
+				return err
+			}
+		}
+		return next.Close()
+	})
+}
+
+// writeFragments writes a table of the complexity of the fragments used by
+// each operation.
+func writeFragments(out io.Writer, results []complexity.ComplexityAnalysis) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\nFile:\tOperation:\tFragment:\tComplexity:\n")
+	for _, r := range results {
+		for _, f := range r.Fragments {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", r.Path, r.OperationName, f.FragmentName, f.Complexity)
+		}
+	}
+	w.Flush()
+}
+
+// writeFragmentDeltas writes a table of how much the fragments used by each
+// operation add to its flattened complexity, the largest first.
+func writeFragmentDeltas(out io.Writer, results []complexity.ComplexityAnalysis) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\nFile:\tOperation:\tFragment:\tDelta:\n")
+	for _, r := range results {
+		for _, d := range r.FragmentDeltas {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", r.Path, r.OperationName, d.FragmentName, d.Delta)
+		}
+	}
+	w.Flush()
+}
+
+// writeProfile writes the time spent on each operation, the slowest first,
+// followed by the total time spent on each step. Documents are parsed and
+// validated once for all of their operations.
+func writeProfile(out io.Writer, results []complexity.ComplexityAnalysis) {
+	total := func(t *complexity.Timing) time.Duration {
+		return t.Parse + t.Validate + t.Calculate
+	}
+	rows := slices.Clone(results)
+	slices.SortStableFunc(rows, func(a, b complexity.ComplexityAnalysis) int {
+		return cmp.Compare(total(b.Timing), total(a.Timing))
+	})
+
+	var (
+		parse, validate, calculate time.Duration
+		documents                  = make(map[string]bool)
+	)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\nFile:\tOperation:\tParse:\tValidate:\tCalculate:\n")
+	for _, r := range rows {
+		t := r.Timing
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Path, r.OperationName, t.Parse.Round(time.Microsecond), t.Validate.Round(time.Microsecond), t.Calculate.Round(time.Microsecond))
+
+		if !documents[r.Path] {
+			documents[r.Path] = true
+			parse += t.Parse
+			validate += t.Validate
+		}
+		calculate += t.Calculate
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "Total: parse %s, validate %s, calculate %s\n", parse.Round(time.Microsecond), validate.Round(time.Microsecond), calculate.Round(time.Microsecond))
+}
+
+// writeTypes writes a table of the complexity each operation spends on the
+// fields of each type.
+func writeTypes(out io.Writer, results []complexity.ComplexityAnalysis) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\nFile:\tOperation:\tType:\tComplexity:\n")
+	for _, r := range results {
+		for _, t := range r.Types {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", r.Path, r.OperationName, t.TypeName, t.Complexity)
+		}
+	}
+	w.Flush()
+}
+
+// writeRoots writes a table of the top level fields of each operation and the
+// types they return.
+func writeRoots(out io.Writer, results []complexity.ComplexityAnalysis) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\nFile:\tOperation:\tField:\tType:\n")
+	for _, r := range results {
+		for _, root := range r.Roots {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Path, r.OperationName, root.FieldName, root.TypeName)
+		}
+	}
+	w.Flush()
+}
+
+// writeExplain writes a table of the fields of each operation, indented by
+// their depth, with their complexity and description.
+func writeExplain(out io.Writer, results []complexity.ComplexityAnalysis) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\nFile:\tOperation:\tField:\tType:\tComplexity:\tDescription:\n")
+	for _, r := range results {
+		writeExplainNodes(w, r, r.Explain, 0)
+	}
+	w.Flush()
+}
+
+func writeExplainNodes(w io.Writer, r complexity.ComplexityAnalysis, nodes []complexity.ComplexityNode, depth int) {
+	for _, n := range nodes {
+		fmt.Fprintf(w, "%s\t%s\t%s%s\t%s\t%d\t%s\n", r.Path, r.OperationName, strings.Repeat("  ", depth), n.Field, n.Type, n.Complexity, truncateDescription(n.Description))
+		writeExplainNodes(w, r, n.Children, depth+1)
+	}
+}
+
+// maxDescriptionLength is the number of characters of a field description
+// shown by writeExplain.
+const maxDescriptionLength = 60
+
+// truncateDescription returns the description on a single line, cut to
+// maxDescriptionLength characters.
+func truncateDescription(description string) string {
+	description = strings.Join(strings.Fields(description), " ")
+	if runes := []rune(description); len(runes) > maxDescriptionLength {
+		return string(runes[:maxDescriptionLength-1]) + "…"
+	}
+	return description
+}
+
+// writeSummary writes the summary as JSON to the file at path.
+func writeSummary(path string, summary complexity.RunSummary) error {
+	f, err := createFile(path)
+	if err != nil {
+		return err
+	}
+	if err := summary.WriteJSON(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// createFile creates the file at path for writing, together with the
+// directories leading to it.
+func createFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+)
+
+// isTerminal reports whether the file is a character device such as a
+// terminal rather than a pipe or regular file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorOutput reports whether output written to w is colored in the given
+// mode. In auto mode only a terminal on stdout is colored, and nothing is
+// when the NO_COLOR environment variable is set.
+func colorOutput(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return w == os.Stdout && isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "", nil
+	}
+	return false, fmt.Errorf("unknown color mode %q, valid modes are auto, always, never", mode)
+}
+
+// progressLine shows the number of analyzed files on a single line of a
+// terminal. A nil progressLine shows nothing.
+type progressLine struct {
+	w     io.Writer
+	shown bool
+}
+
+// newProgressLine returns the progress line for the command, or nil when it
+// is quiet or stderr is not a terminal so that progress never ends up in logs.
+func newProgressLine(c *cli.Command) *progressLine {
+	if c.Bool("quiet") || !isTerminal(os.Stderr) {
+		return nil
+	}
+	return &progressLine{w: os.Stderr}
+}
+
+// Update replaces the line with the number of files done. The line is cleared
+// once every file is done.
+func (p *progressLine) Update(done, total int) {
+	if done == total {
+		p.Clear()
+		return
+	}
+	fmt.Fprintf(p.w, "\r\x1b[Kanalyzed %d/%d files", done, total)
+	p.shown = true
+}
+
+// Clear erases the line so other output can be written to the terminal.
+func (p *progressLine) Clear() {
+	if p == nil || !p.shown {
+		return
+	}
+	fmt.Fprint(p.w, "\r\x1b[K")
+	p.shown = false
+}
+
+// handler returns the progress handler updating the line, or nil when there
+// is no line.
+func (p *progressLine) handler() func(done, total int) {
+	if p == nil {
+		return nil
+	}
+	return p.Update
+}
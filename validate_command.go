@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/asger-noer/gql/complexity"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	ValidateCommandName        = "validate"
+	ValidateCommandUsage       = "Validate GraphQL documents against the schema"
+	ValidateCommandDescription = `Validate GraphQL documents against the provided schema without analyzing their
+complexity. Each document is reported as passing or failing, failures are
+followed by their errors.
+
+Exit codes:
+  0  all documents are valid
+  1  internal or IO error
+  3  invalid input, including one or more invalid documents`
+)
+
+func validateCommand() *cli.Command {
+	return &cli.Command{
+		Name:        ValidateCommandName,
+		Usage:       ValidateCommandUsage,
+		Description: ValidateCommandDescription,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "docs",
+				Usage: "Comma separated glob patterns to search for graphql files, or .zip, .tar or .tar.gz archives of them",
+				Value: complexity.DefaultDocuments,
+			},
+			&cli.BoolFlag{
+				Name:  "federation",
+				Usage: "Declare the Apollo Federation directives and types used by subgraph schemas",
+			},
+			&cli.BoolFlag{
+				Name:  "manifest",
+				Usage: "Read documents as persisted query manifests, files ending in .json always are",
+			},
+			&cli.BoolFlag{
+				Name:  "no-ignore",
+				Usage: "Validate files excluded by the .gqlignore file",
+			},
+			&cli.BoolFlag{
+				Name:  "require-matches",
+				Usage: "Fail when the document or a schema glob matches no files instead of warning",
+			},
+			&cli.StringSliceFlag{
+				Name:  "ignore",
+				Usage: "Gitignore style pattern of files to leave out in addition to the .gqlignore file",
+			},
+			&cli.StringSliceFlag{
+				Name:  "disable-rule",
+				Usage: "Name of a validation rule, such as NoUnusedFragments, to not validate documents with",
+			},
+		},
+		Before: applyConfigFile,
+		Action: runValidate,
+	}
+}
+
+func runValidate(ctx context.Context, c *cli.Command) error {
+	cfg := complexity.Config{
+		Federation:     c.Bool("federation"),
+		SchemaTimeout:  c.Duration("schema-timeout"),
+		SchemaRetries:  c.Int("schema-retries"),
+		Registry:       schemaRegistry(c),
+		RequireMatches: c.Bool("require-matches"),
+		NoIgnore:       c.Bool("no-ignore"),
+		IgnorePatterns: c.StringSlice("ignore"),
+		Manifest:       c.Bool("manifest"),
+		DisabledRules:  c.StringSlice("disable-rule"),
+	}
+
+	var err error
+	if cfg.InlineSchema, err = inlineSchema(c); err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+	if cfg.Logger, err = newLogger(c); err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	fsys, err := rootFS(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	schemaFind, err := schemaGlobs(c, fsys)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid input: %v", err), ExitInvalidInput)
+	}
+
+	results, err := complexity.RunValidationFS(ctx, fsys, schemaFind, c.String("docs"), complexity.WithConfig(cfg))
+	if err != nil {
+		return analysisExit(err)
+	}
+
+	var failed int
+	for _, r := range results {
+		if r.Valid() {
+			fmt.Fprintf(os.Stdout, "PASS %s\n", r.Path)
+			continue
+		}
+
+		failed++
+		fmt.Fprintf(os.Stdout, "FAIL %s\n", r.Path)
+		for _, d := range r.Diagnostics {
+			fmt.Fprintf(os.Stdout, "  %d:%d: %s\n", d.Line, d.Column, d.Message)
+		}
+	}
+
+	if failed > 0 {
+		return cli.Exit(fmt.Sprintf("%d of %d document(s) failed validation", failed, len(results)), ExitInvalidInput)
+	}
+
+	return nil
+}